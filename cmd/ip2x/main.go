@@ -2,28 +2,53 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/pg9182/ip2x"
 )
 
 var opts struct {
-	JSON    bool
-	Compact bool
-	Strict  bool
+	JSON     bool
+	Format   string
+	Fields   ip2x.DBFields
+	Compact  bool
+	Strict   bool
+	MaxAge   time.Duration
+	WarnAge  time.Duration
+	Raw      bool
+	Coalesce bool
+	MMDBKeys string
 }
 
 func init() {
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "%s db_path [ip_addr...]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "%s info db_path\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "%s dump db_path\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "%s export -format mmdb db_path out_path\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "%s convert db_path out_path (native, or BIN if out_path ends with .bin; -fields selects a subset)\n", os.Args[0])
 		flag.PrintDefaults()
 	}
-	flag.BoolVar(&opts.JSON, "json", false, "use json output")
+	flag.BoolVar(&opts.JSON, "json", false, "use json output (alias for -format json)")
+	flag.StringVar(&opts.Format, "format", "text", "output format (text, json, csv, tsv, logfmt, cbor, msgpack; mmdb for export)")
+	flag.Var(&opts.Fields, "fields", "comma-separated fields to output (default all)")
 	flag.BoolVar(&opts.Compact, "compact", false, "compact output")
 	flag.BoolVar(&opts.Strict, "strict", false, "fail immediately if a record is not found")
+	flag.DurationVar(&opts.MaxAge, "max-age", 0, "fail if the database is older than this")
+	flag.DurationVar(&opts.WarnAge, "warn-age", 0, "warn if the database is older than this")
+	flag.BoolVar(&opts.Raw, "raw", false, "open unsupported databases (and output raw column values for text)")
+	flag.StringVar(&opts.MMDBKeys, "mmdb-keys", "", "comma-separated field=key mappings to override the default mmdb keys when exporting (e.g., city=city.names.en)")
+	flag.BoolVar(&opts.Coalesce, "coalesce", false, "merge adjacent ranges with the same values for the output fields when dumping")
 }
 
 func main() {
@@ -32,55 +57,98 @@ func main() {
 		flag.Usage()
 		os.Exit(2)
 	}
-	if !opts.JSON {
-		ip2x.RecordStringColor = true
-		ip2x.RecordStringMultiline = !opts.Compact
+	if opts.JSON {
+		opts.Format = "json"
+	}
+	// a database named like a subcommand can be specified as ./name
+	cmd, nargs := lookup, 0
+	switch args[0] {
+	case "info":
+		cmd, nargs = info, 1
+	case "dump":
+		cmd, nargs = dump, 1
+	case "export":
+		cmd, nargs = export, 2
+	case "convert":
+		cmd, nargs = convert, 2
+	}
+	if nargs != 0 {
+		if args = args[1:]; len(args) != nargs {
+			flag.Usage()
+			os.Exit(2)
+		}
 	}
-	if err := lookup(args); err != nil {
+	if err := cmd(args); err != nil {
 		fmt.Fprintf(os.Stderr, "ip2x: fatal: %v\n", err)
 		os.Exit(1)
 	}
 }
 
+func open(name string) (*os.File, *ip2x.DB, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var o []ip2x.Option
+	if opts.Raw {
+		o = append(o, ip2x.Raw())
+	}
+	if opts.WarnAge > 0 {
+		o = append(o, ip2x.MaxAge(opts.WarnAge, func(err error) {
+			fmt.Fprintf(os.Stderr, "ip2x: warning: %v\n", err)
+		}))
+	}
+	if opts.MaxAge > 0 {
+		o = append(o, ip2x.MaxAge(opts.MaxAge, nil))
+	}
+
+	db, err := ip2x.New(f, o...)
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	return f, db, nil
+}
+
 func lookup(args []string) error {
-	f, err := os.Open(args[0])
+	f, db, err := open(args[0])
 	if err != nil {
 		return err
 	}
 	defer f.Close()
 
-	db, err := ip2x.New(f)
+	header, encode, err := encoder(db)
 	if err != nil {
 		return err
 	}
-
-	var enc *json.Encoder
-	if opts.JSON {
-		enc = json.NewEncoder(os.Stdout)
-		if !opts.Compact {
-			enc.SetIndent("", "  ")
-		}
-		enc.SetEscapeHTML(false)
-	}
 	if len(args) == 1 {
-		if opts.JSON {
+		if opts.Format == "json" {
+			enc := json.NewEncoder(os.Stdout)
+			if !opts.Compact {
+				enc.SetIndent("", "  ")
+			}
+			enc.SetEscapeHTML(false)
 			enc.Encode(db.String())
 		} else {
 			fmt.Println(db)
 		}
 		return nil
 	}
+	if header != nil {
+		os.Stdout.Write(append(header, '\n'))
+	}
+	var buf []byte
 	for _, f := range args[1:] {
 		r, err := db.LookupString(f)
 		if err != nil {
 			return fmt.Errorf("lookup %q: %w", f, err)
 		}
 		if r.IsValid() {
-			if opts.JSON {
-				enc.Encode(r)
-			} else {
-				fmt.Println(r)
+			if buf, err = encode(buf[:0], r); err != nil {
+				return fmt.Errorf("lookup %q: %w", f, err)
 			}
+			os.Stdout.Write(buf)
 		} else if opts.Strict {
 			return fmt.Errorf("lookup %q: not found", f)
 		}
@@ -88,6 +156,292 @@ func lookup(args []string) error {
 	return nil
 }
 
+func dump(args []string) error {
+	f, db, err := open(args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if opts.Format == "json" {
+		opts.Compact = true // one object per line
+	}
+	header, encode, err := encoder(db)
+	if err != nil {
+		return err
+	}
+	var prefix func(b []byte, rg ip2x.Range) []byte
+	switch opts.Format {
+	case "text":
+		prefix = func(b []byte, rg ip2x.Range) []byte {
+			b = rg.From.AppendTo(b)
+			b = append(b, '-')
+			b = rg.To.Prev().AppendTo(b)
+			return append(b, ' ')
+		}
+	case "json":
+		prefix = func(b []byte, rg ip2x.Range) []byte {
+			b = append(b, `{"ip_from":"`...)
+			b = rg.From.AppendTo(b)
+			b = append(b, `","ip_to":"`...)
+			b = rg.To.Prev().AppendTo(b)
+			return append(b, `",`...)
+		}
+	case "csv", "tsv":
+		sep := byte(',')
+		if opts.Format == "tsv" {
+			sep = '\t'
+		}
+		header = append([]byte("ip_from"+string(sep)+"ip_to"+string(sep)), header...)
+		prefix = func(b []byte, rg ip2x.Range) []byte {
+			b = rg.From.AppendTo(b)
+			b = append(b, sep)
+			b = rg.To.Prev().AppendTo(b)
+			return append(b, sep)
+		}
+	case "logfmt":
+		prefix = func(b []byte, rg ip2x.Range) []byte {
+			b = append(b, "ip_from="...)
+			b = rg.From.AppendTo(b)
+			b = append(b, " ip_to="...)
+			b = rg.To.Prev().AppendTo(b)
+			return append(b, ' ')
+		}
+	default:
+		return fmt.Errorf("output format %q is not supported for dumps", opts.Format)
+	}
+
+	each := db.EachErr
+	if opts.Coalesce {
+		each = func(fn func(ip2x.Range, ip2x.Record) bool) error {
+			return db.CoalesceErr(fn, opts.Fields...)
+		}
+	}
+	w := bufio.NewWriter(os.Stdout)
+	if header != nil {
+		w.Write(append(header, '\n'))
+	}
+	var buf []byte
+	if rerr := each(func(rg ip2x.Range, r ip2x.Record) bool {
+		buf = prefix(buf[:0], rg)
+		n := len(buf)
+		if buf, err = encode(buf, r); err != nil {
+			err = fmt.Errorf("dump %s: %w", rg.From, err)
+			return false
+		}
+		if opts.Format == "json" {
+			// merge the range into the record object
+			buf = append(buf[:n], buf[n+1:]...)
+			if buf[n] == '}' {
+				buf = append(buf[:n-1], buf[n:]...) // empty record
+			}
+		}
+		_, err = w.Write(buf)
+		return err == nil
+	}); rerr != nil {
+		w.Flush()
+		return fmt.Errorf("dump: read database: %w", rerr)
+	}
+	if err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// encoder returns the header (if any) and record encoder for the output
+// format.
+func encoder(db *ip2x.DB) (header []byte, encode func([]byte, ip2x.Record) ([]byte, error), err error) {
+	var ind bytes.Buffer
+	switch opts.Format {
+	case "text":
+		rf := ip2x.Formatter{
+			Color:     ip2x.DefaultColorScheme(),
+			Multiline: !opts.Compact,
+			Fields:    opts.Fields,
+		}
+		encode = func(b []byte, r ip2x.Record) ([]byte, error) {
+			return append(rf.Append(b, r), '\n'), nil
+		}
+	case "json":
+		encode = func(b []byte, r ip2x.Record) ([]byte, error) {
+			n := len(b)
+			b, err := r.AppendJSON(b, opts.Fields...)
+			if err == nil && !opts.Compact {
+				ind.Reset()
+				if err = json.Indent(&ind, b[n:], "", "  "); err != nil {
+					return b[:n], err
+				}
+				b = append(b[:n], ind.Bytes()...)
+			}
+			return append(b, '\n'), err
+		}
+	case "csv", "tsv":
+		if opts.Format == "csv" {
+			header = db.AppendCSVHeader(nil, opts.Fields...)
+			encode = func(b []byte, r ip2x.Record) ([]byte, error) {
+				b, err := r.AppendCSV(b, opts.Fields...)
+				return append(b, '\n'), err
+			}
+		} else {
+			header = db.AppendTSVHeader(nil, opts.Fields...)
+			encode = func(b []byte, r ip2x.Record) ([]byte, error) {
+				b, err := r.AppendTSV(b, opts.Fields...)
+				return append(b, '\n'), err
+			}
+		}
+	case "logfmt":
+		encode = func(b []byte, r ip2x.Record) ([]byte, error) {
+			b, err := r.AppendLogfmt(b, opts.Fields...)
+			return append(b, '\n'), err
+		}
+	case "cbor":
+		encode = func(b []byte, r ip2x.Record) ([]byte, error) {
+			return r.AppendCBOR(b, opts.Fields...)
+		}
+	case "msgpack":
+		encode = func(b []byte, r ip2x.Record) ([]byte, error) {
+			return r.AppendMsgpack(b, opts.Fields...)
+		}
+	default:
+		return nil, nil, fmt.Errorf("unknown output format %q", opts.Format)
+	}
+	if opts.Raw && opts.Format == "text" {
+		text := encode
+		encode = func(b []byte, r ip2x.Record) ([]byte, error) {
+			b, err := text(b, r)
+			for n := 2; err == nil; n++ {
+				v, ok := r.Column(n)
+				if !ok {
+					break
+				}
+				f, _ := r.ColumnFloat32(n)
+				b = append(b, "col"...)
+				b = strconv.AppendInt(b, int64(n), 10)
+				b = append(b, '\t', '0', 'x')
+				b = strconv.AppendUint(b, uint64(v), 16)
+				b = append(b, '\t')
+				b = strconv.AppendFloat(b, float64(f), 'g', -1, 32)
+				if s, err := r.ColumnString(n, 0); err == nil {
+					b = append(b, '\t')
+					b = strconv.AppendQuote(b, s)
+				}
+				b = append(b, '\n')
+			}
+			return b, err
+		}
+	}
+	return header, encode, nil
+}
+
+func info(args []string) error {
+	f, db, err := open(args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	st, err := db.Stats()
+	if err != nil {
+		return err
+	}
+
+	if opts.Format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		if !opts.Compact {
+			enc.SetIndent("", "  ")
+		}
+		enc.SetEscapeHTML(false)
+		return enc.Encode(struct {
+			Database string      `json:"database"`
+			Header   ip2x.Header `json:"header"`
+			Stats    ip2x.Stats  `json:"stats"`
+		}{db.String(), db.Header(), st})
+	}
+
+	h := db.Header()
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "database\t%s\n", db)
+	fmt.Fprintf(tw, "product\t%s (%d)\n", h.Product, h.ProductType)
+	fmt.Fprintf(tw, "type\t%d\n", h.Type)
+	fmt.Fprintf(tw, "date\t%04d-%02d-%02d\n", 2000+int(h.Year), h.Month, h.Day)
+	fmt.Fprintf(tw, "columns\t%d\n", h.Columns)
+	fmt.Fprintf(tw, "file size\t%d\n", h.FileSize)
+	fmt.Fprintf(tw, "ipv4 rows\t%d (base %d, %d bytes/row, index %t)\n", st.IPv4Rows, h.IPv4Base, st.IPv4RowSize, st.IPv4Index)
+	fmt.Fprintf(tw, "ipv6 rows\t%d (base %d, %d bytes/row, index %t)\n", st.IPv6Rows, h.IPv6Base, st.IPv6RowSize, st.IPv6Index)
+	fmt.Fprintf(tw, "ipv4 addresses\t%d\n", st.IPv4Addresses)
+	fmt.Fprintf(tw, "ipv6 addresses\t%s\n", st.IPv6Addresses)
+	fmt.Fprintf(tw, "string heap\t%d\n", st.StringHeapSize)
+	db.EachField(func(f ip2x.DBField) bool {
+		fmt.Fprintf(tw, "distinct %s\t%d\n", f, st.Distinct[f])
+		return true
+	})
+	return tw.Flush()
+}
+
+func convert(args []string) error {
+	f, db, err := open(args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var (
+		buf bytes.Buffer
+		sub = ip2x.Subset{Fields: opts.Fields}
+	)
+	if strings.EqualFold(filepath.Ext(args[1]), ".bin") {
+		err = sub.WriteBIN(&buf, db)
+	} else if len(opts.Fields) != 0 {
+		err = sub.WriteNative(&buf, db)
+	} else {
+		err = ip2x.WriteNative(&buf, db)
+	}
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(args[1], buf.Bytes(), 0644)
+}
+
+func export(args []string) error {
+	if opts.Format != "mmdb" {
+		return fmt.Errorf("unsupported export format %q (use convert for bin or native databases)", opts.Format)
+	}
+
+	f, db, err := open(args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	fields := opts.Fields
+	if len(fields) == 0 {
+		db.EachField(func(f ip2x.DBField) bool {
+			fields = append(fields, f)
+			return true
+		})
+	}
+	m := ip2x.DefaultMMDBMapping(fields...)
+	if opts.MMDBKeys != "" {
+		for _, x := range strings.Split(opts.MMDBKeys, ",") {
+			k, v, ok := strings.Cut(x, "=")
+			if !ok {
+				return fmt.Errorf("invalid mmdb key mapping %q", x)
+			}
+			f, err := ip2x.ParseDBField(strings.TrimSpace(k))
+			if err != nil {
+				return err
+			}
+			m[f] = strings.TrimSpace(v)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := (&ip2x.MMDBWriter{Fields: m}).Write(&buf, db); err != nil {
+		return err
+	}
+	return os.WriteFile(args[1], buf.Bytes(), 0644)
+}
+
 // pparse parses argv into f, but flags after non-flag arguments, stopping if an
 // argument is '--'.
 func pparse(f *flag.FlagSet, argv []string) (args []string, err error) {
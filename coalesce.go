@@ -0,0 +1,105 @@
+package ip2x
+
+import "bytes"
+
+// Coalesce returns a function like [DB.Each] which merges adjacent rows with
+// the same values for fields (or all fields if none are specified), yielding
+// fewer, larger ranges. The record for a merged range is the one for its first
+// row, so fields which weren't selected may not apply to the whole range.
+//
+// If you are using Go 1.23 or later, the returned function can be used with a
+// range-over-func loop. Errors reading the database stop the iteration, so use
+// [DB.CoalesceErr] if a partial result is not acceptable.
+func (db *DB) Coalesce(fields ...DBField) func(fn func(Range, Record) bool) {
+	return func(fn func(Range, Record) bool) {
+		db.CoalesceErr(fn, fields...)
+	}
+}
+
+// CoalesceErr is like [DB.Coalesce], but calls fn directly and returns the
+// error which stopped the iteration, if any.
+func (db *DB) CoalesceErr(fn func(Range, Record) bool, fields ...DBField) error {
+	if fn == nil || db.s == nil {
+		return nil
+	}
+	c := newCoalescer(db, fields)
+	for _, v6 := range []bool{false, true} {
+		ok, err := db.each(v6, func(rg Range, r Record) bool {
+			if rg, r, ok := c.add(rg, r); ok && !fn(rg, r) {
+				return false
+			}
+			return c.err == nil
+		})
+		if err != nil {
+			return err
+		}
+		if c.err != nil {
+			return c.err
+		}
+		if !ok {
+			return nil
+		}
+		if rg, r, ok := c.flush(); ok && !fn(rg, r) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// coalescer merges adjacent ranges with the same raw values for fields.
+type coalescer struct {
+	fields []DBField
+	buf    [getbufSize]byte
+	key    []byte // raw values of the current row
+	last   []byte // raw values of the pending row
+	rg     Range  // pending range
+	r      Record // pending record
+	ok     bool   // whether there is a pending range
+	err    error  // first error reading a field
+}
+
+// newCoalescer creates a new coalescer for fields in db, or all of them if
+// none are specified.
+func newCoalescer(db *DB, fields []DBField) *coalescer {
+	if len(fields) == 0 {
+		db.EachField(func(f DBField) bool {
+			fields = append(fields, f)
+			return true
+		})
+	}
+	return &coalescer{fields: fields}
+}
+
+// add adds a row, returning the previous range if it can't be merged with
+// it. If an error occurs reading the row, c.err is set, and the row is
+// dropped.
+func (c *coalescer) add(rg Range, r Record) (Range, Record, bool) {
+	c.key = c.key[:0]
+	for _, f := range c.fields {
+		dt, _, err := r.getb(f, c.buf[:])
+		if dt == nil && err != nil {
+			if c.err == nil {
+				c.err = err
+			}
+			return c.flush()
+		}
+		c.key = append(c.key, byte(len(dt)))
+		c.key = append(c.key, dt...)
+	}
+	if c.ok && c.rg.To == rg.From && bytes.Equal(c.key, c.last) {
+		c.rg.To = rg.To
+		return Range{}, Record{}, false
+	}
+	prg, pr, ok := c.flush()
+	c.rg, c.r, c.ok = rg, r, true
+	c.r.d = append([]byte(nil), r.d...) // the row may be reused by each
+	c.last = append(c.last[:0], c.key...)
+	return prg, pr, ok
+}
+
+// flush returns and clears the pending range, if any.
+func (c *coalescer) flush() (Range, Record, bool) {
+	rg, r, ok := c.rg, c.r, c.ok
+	c.rg, c.r, c.ok = Range{}, Record{}, false
+	return rg, r, ok
+}
@@ -4,17 +4,54 @@ package ip2x
 import (
 	"errors"
 	"io"
+	"math"
+	"math/big"
 	"net/netip"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 	"unsafe"
 )
 
 // DBProduct represents an IP2Location database product.
 type DBProduct uint8
 
+// ParseDBProduct parses a product name (e.g., IP2Location) or type prefix
+// (e.g., DB), case-insensitively.
+func ParseDBProduct(s string) (DBProduct, error) {
+	if p := productByName(strings.ToLower(s)); p != 0 {
+		return p, nil
+	}
+	return 0, errors.New("unknown database product " + strconv.Quote(s))
+}
+
 // String returns the name of the product.
 func (p DBProduct) String() string {
-	return p.product()
+	return p.name()
+}
+
+// MarshalText implements [encoding.TextMarshaler]. Unknown products are
+// encoded as a number.
+func (p DBProduct) MarshalText() ([]byte, error) {
+	if s := p.name(); s != "" {
+		return []byte(s), nil
+	}
+	return strconv.AppendUint(nil, uint64(p), 10), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler]. It accepts anything
+// accepted by [ParseDBProduct], or a product code.
+func (p *DBProduct) UnmarshalText(b []byte) error {
+	if v, err := strconv.ParseUint(string(b), 10, 8); err == nil {
+		*p = DBProduct(v)
+		return nil
+	}
+	v, err := ParseDBProduct(string(b))
+	if err == nil {
+		*p = v
+	}
+	return err
 }
 
 // DBType represents an IP2Location database variant. Each database type
@@ -26,18 +63,138 @@ func (t DBType) String() string {
 	return strconv.Itoa(int(t))
 }
 
+// MarshalText implements [encoding.TextMarshaler].
+func (t DBType) MarshalText() ([]byte, error) {
+	return strconv.AppendUint(nil, uint64(t), 10), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler]. The number may be
+// prefixed by a product's type prefix (e.g., DB11 or PX2).
+func (t *DBType) UnmarshalText(b []byte) error {
+	s := string(b)
+	if i := strings.IndexAny(s, "0123456789"); i > 0 {
+		if productByName(strings.ToLower(s[:i])) == 0 {
+			return errors.New("unknown database type prefix in " + strconv.Quote(s))
+		}
+		s = s[i:]
+	}
+	v, err := strconv.ParseUint(s, 10, 8)
+	if err != nil {
+		return errors.New("invalid database type " + strconv.Quote(string(b)))
+	}
+	*t = DBType(v)
+	return nil
+}
+
 // DBField represents a database column.
 type DBField uint
 
+// ParseDBField parses a column name (e.g., country_code) or field name (e.g.,
+// CountryCode), case-insensitively.
+func ParseDBField(s string) (DBField, error) {
+	if f := fieldByName(strings.ToLower(s)); f != 0 {
+		return f, nil
+	}
+	return 0, errors.New("unknown database field " + strconv.Quote(s))
+}
+
+// AllFields returns all known fields in order, including ones added by
+// [Register].
+func AllFields() []DBField {
+	fs := make([]DBField, 0, dbFieldMax)
+	for f := DBField(1); f <= dbFieldMax; f++ {
+		if f.column() != "" {
+			fs = append(fs, f)
+		}
+	}
+	return append(fs, registeredFields()...)
+}
+
 // String returns the name of the database column.
 func (f DBField) String() string {
-	return f.column()
+	return f.name()
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (f DBField) MarshalText() ([]byte, error) {
+	if s := f.name(); s != "" {
+		return []byte(s), nil
+	}
+	return nil, errors.New("unknown database field " + strconv.FormatUint(uint64(f), 10))
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler]. It accepts anything
+// accepted by [ParseDBField].
+func (f *DBField) UnmarshalText(b []byte) error {
+	v, err := ParseDBField(string(b))
+	if err == nil {
+		*f = v
+	}
+	return err
+}
+
+// DBFields is a list of fields. It implements [flag.Value] and
+// [encoding.TextUnmarshaler] using a comma-separated list of anything accepted
+// by [ParseDBField].
+type DBFields []DBField
+
+// String returns the comma-separated column names.
+func (fs DBFields) String() string {
+	b, _ := fs.MarshalText()
+	return string(b)
+}
+
+// Set parses and appends a comma-separated list of fields.
+func (fs *DBFields) Set(s string) error {
+	for _, x := range strings.Split(s, ",") {
+		if x = strings.TrimSpace(x); x != "" {
+			f, err := ParseDBField(x)
+			if err != nil {
+				return err
+			}
+			*fs = append(*fs, f)
+		}
+	}
+	return nil
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (fs DBFields) MarshalText() ([]byte, error) {
+	var b []byte
+	for i, f := range fs {
+		if i != 0 {
+			b = append(b, ',')
+		}
+		x, err := f.MarshalText()
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, x...)
+	}
+	return b, nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (fs *DBFields) UnmarshalText(b []byte) error {
+	*fs = nil
+	return fs.Set(string(b))
 }
 
 // DB reads an IP2Location binary database.
 type DB struct {
 	r io.ReaderAt
 	s *dbS
+	c *strCache // optional
+
+	// in-memory indexes (optional)
+	idx4 *memIndex
+	idx6 *memIndex
+	eyt4 *eytzinger
+	eyt6 *eytzinger
+
+	// native format tables (optional)
+	n4 *nativeTable
+	n6 *nativeTable
 
 	// header
 	dbtype   DBType
@@ -59,13 +216,34 @@ type DB struct {
 const (
 	dbtype_str = iota
 	dbtype_f32
+	dbtype_u32
+	dbtype_i32
+	dbtype_f64  // pointer only
+	dbtype_u128 // pointer only
 )
 
 // New opens an IP2Location binary database reading from r.
-func New(r io.ReaderAt) (*DB, error) {
+func New(r io.ReaderAt, opt ...Option) (*DB, error) {
+	var o options
+	for _, fn := range opt {
+		fn(&o)
+	}
 	var db DB
 	var row [64]byte // 64-byte header
-	if _, err := r.ReadAt(row[:], 0); err == nil {
+	if n, err := r.ReadAt(row[:], 0); n >= len(nativeMagic) && string(row[:len(nativeMagic)]) == nativeMagic {
+		if err := db.openNative(r); err != nil {
+			return nil, err
+		}
+		if o.intern > 0 {
+			db.c = newStrCache(o.intern)
+		}
+		for _, fn := range o.check {
+			if err := fn(&db); err != nil {
+				return nil, err
+			}
+		}
+		return &db, nil
+	} else if err == nil {
 		db.r = r
 		db.dbtype, db.dbcolumn = DBType(row[0]), row[1]
 		db.dbyear, db.dbmonth, db.dbday = row[2], row[3], row[4]
@@ -83,47 +261,67 @@ func New(r io.ReaderAt) (*DB, error) {
 	if db.dbmonth == 0 || db.dbmonth > 12 || db.dbday == 0 || db.dbday > 31 {
 		return nil, errors.New("database is corrupt")
 	}
-	if db.dbyear < 21 {
+	if db.dbcolumn == 0 {
+		return nil, errors.New("database is corrupt")
+	}
+	if db.dbyear < 21 && !o.raw {
 		// only has prcode field in >= 2021
 		return nil, errors.New("database is too old (date: " + db.Version() + ")")
 	}
-	if db.s = dbinfo(db.prcode, db.dbtype); db.s == nil {
+	if db.s = lookupSchema(db.prcode, db.dbtype, db.dbcolumn); db.s == nil && !o.raw {
 		return nil, errors.New("unsupported database " + strconv.Itoa(int(db.prcode)))
 	}
-	if db.prcode == IP2Location && db.dbtype == 26 && db.dbcolumn == 25 {
+	if db.prcode == IP2Location && db.dbtype == 26 && db.dbcolumn == 25 && db.s == dbinfo(db.prcode, db.dbtype) {
 		// DB26 from before as_domain, as_usage_type, and as_cidr fields were added in September 2025
 		db.s = withoutFields(db.s, ASDomain, ASUsageType, ASRange)
 	}
-	if c, _, _ := db.s.Info(); db.dbcolumn != c {
-		return nil, errors.New("database is corrupt or library is buggy: db " + db.prcode.product() + " " + db.prcode.prefix() + db.dbtype.String() + ": expected " + strconv.Itoa(int(c)) + "  cols, got " + strconv.Itoa(int(db.dbcolumn)))
+	if c, _, _ := db.s.Info(); db.dbcolumn != c && o.raw {
+		db.s = &dbS{f: dbF{dbField_extra: {db.dbcolumn, uint8(db.prcode), uint8(db.dbtype)}}}
+	} else if db.dbcolumn != c {
+		return nil, errors.New("database is corrupt or library is buggy: db " + db.prcode.name() + " " + db.prcode.typePrefix() + db.dbtype.String() + ": expected " + strconv.Itoa(int(c)) + "  cols, got " + strconv.Itoa(int(db.dbcolumn)))
 
 	}
+	if o.intern > 0 {
+		db.c = newStrCache(o.intern)
+	}
+	if err := db.loadIndex(&o); err != nil {
+		return nil, err
+	}
+	for _, fn := range o.check {
+		if err := fn(&db); err != nil {
+			return nil, err
+		}
+	}
 	return &db, nil
 }
 
 func withoutFields(i *dbS, f ...DBField) *dbS {
 	i2 := *i
 	for _, f := range f {
-		if i2[f].col == 0 {
+		if i2.f[f].col == 0 {
 			panic("missing field to remove")
 		}
-		i2[f] = dbI{}
+		i2.f[f] = dbI{}
 	}
-	i2[dbField_extra].col -= uint8(len(f))
+	i2.f[dbField_extra].col -= uint8(len(f))
 	return &i2
 }
 
 // String returns a human-readable string describing the database.
 func (db *DB) String() string {
 	s := make([]byte, 0, 256)
-	s = append(s, db.prcode.product()...)
+	if n := db.prcode.name(); n != "" {
+		s = append(s, n...)
+	} else {
+		s = append(s, db.prcode.GoString()...)
+	}
 	s = append(s, ' ')
-	s = append(s, db.prcode.prefix()...)
+	s = append(s, db.prcode.typePrefix()...)
 	s = strconv.AppendInt(s, int64(db.dbtype), 10)
 	s = append(s, ' ')
 	s = append(s, db.Version()...)
 	s = append(s, ' ', '[')
-	for n, f := 0, DBField(1); f <= dbFieldMax; f++ {
+	for n, f, m := 0, DBField(1), db.s.FieldMax(); f <= m; f++ {
 		if db.Has(f) {
 			if n != 0 {
 				s = append(s, ',')
@@ -166,6 +364,16 @@ func (db *DB) Version() string {
 	return as_strref_unsafe(b)
 }
 
+// Date returns the database date as midnight UTC.
+func (db *DB) Date() time.Time {
+	return time.Date(2000+int(db.dbyear), time.Month(db.dbmonth), int(db.dbday), 0, 0, 0, 0, time.UTC)
+}
+
+// Age returns the time elapsed between the database date and now.
+func (db *DB) Age(now time.Time) time.Duration {
+	return now.Sub(db.Date())
+}
+
 // Has returns true if the database contains f.
 func (db *DB) Has(f DBField) bool {
 	return db.s.Field(f).IsValid()
@@ -184,9 +392,8 @@ func (db *DB) HasIPv6() bool {
 // EachField calls fn for each column in the database until fn returns false.
 func (db *DB) EachField(fn func(DBField) bool) {
 	if fn != nil && db.s != nil {
-		i := *db.s
-		for f := DBField(1); f <= dbFieldMax; f++ {
-			if i.Field(f).IsValid() {
+		for f, m := DBField(1), db.s.FieldMax(); f <= m; f++ {
+			if db.s.Field(f).IsValid() {
 				if !fn(f) {
 					return
 				}
@@ -207,6 +414,29 @@ func (db *DB) LookupString(ip string) (r Record, err error) {
 // returned. If an i/o error occurs, an empty record and non-nil error is
 // returned.
 func (db *DB) Lookup(a netip.Addr) (r Record, err error) {
+	r, _, _, _, err = db.lookup(a)
+	return
+}
+
+// LookupRange is like [DB.Lookup], but also returns the range of addresses
+// which have the same record. IPv4 ranges are returned as IPv4 addresses even
+// if a was IPv4-mapped, 6to4, or Teredo.
+func (db *DB) LookupRange(a netip.Addr) (r Record, rg Range, err error) {
+	r, from, to, iplen, err := db.lookup(a)
+	if r.IsValid() {
+		if iplen == 4 {
+			rg.From = netip.AddrFrom4(to_be_u32(uint32(from.lo)))
+			rg.To = netip.AddrFrom4(to_be_u32(uint32(to.lo)))
+		} else {
+			rg.From = netip.AddrFrom16(to_be_u128(from))
+			rg.To = netip.AddrFrom16(to_be_u128(to))
+		}
+	}
+	return
+}
+
+// lookup looks up a, also returning the unmapped row range and ip length.
+func (db *DB) lookup(a netip.Addr) (r Record, ipfrom, ipto uint128, iplen int, err error) {
 	if !a.IsValid() {
 		return
 	}
@@ -214,6 +444,20 @@ func (db *DB) Lookup(a netip.Addr) (r Record, err error) {
 	// unmap the ip address into a native v4/v6
 	ip, iplen := unmap(as_ip6_uint128(a))
 
+	if db.n4 != nil || db.n6 != nil {
+		t := db.n4
+		if iplen != 4 {
+			t = db.n6
+		}
+		if t != nil {
+			if i, ok := t.search(ip); ok {
+				r = Record{r: db.r, s: db.s, d: t.data(i), c: db.c}
+				ipfrom, ipto = t.from[i], t.from[i+1]
+			}
+		}
+		return
+	}
+
 	// 4 bytes per column except for the first one (IPFrom)
 	var (
 		colsize = uint(iplen) + uint(db.dbcolumn-1)*4
@@ -232,18 +476,42 @@ func (db *DB) Lookup(a netip.Addr) (r Record, err error) {
 	)
 
 	// set the initial binary search range
+	// note: the count includes the final row, which only provides the end of
+	// the last range
 	var off, lower, upper uint32
 	if iplen == 4 {
-		if off = db.ip4idx; off > 0 {
+		if db.ip4count < 2 {
+			return
+		}
+		if db.eyt4 != nil {
+			var ok bool
+			if lower, ok = db.eyt4.search(ip, false); !ok {
+				return
+			}
+			upper = lower
+		} else if db.idx4 != nil {
+			lower, upper = db.idx4.bounds(ip, false)
+		} else if off = db.ip4idx; off > 0 {
 			off += uint32(ip.lo>>16<<3) - 1
 		} else {
-			upper = db.ip4count
+			upper = db.ip4count - 2
 		}
 	} else {
-		if off = db.ip6idx; off > 0 {
+		if db.ip6count < 2 {
+			return
+		}
+		if db.eyt6 != nil {
+			var ok bool
+			if lower, ok = db.eyt6.search(ip, true); !ok {
+				return
+			}
+			upper = lower
+		} else if db.idx6 != nil {
+			lower, upper = db.idx6.bounds(ip, true)
+		} else if off = db.ip6idx; off > 0 {
 			off += uint32(ip.hi>>48<<3) - 1
 		} else {
-			upper = db.ip6count
+			upper = db.ip6count - 2
 		}
 	}
 	if off != 0 {
@@ -272,7 +540,6 @@ func (db *DB) Lookup(a netip.Addr) (r Record, err error) {
 		}
 
 		// get the row start/end range
-		var ipfrom, ipto uint128
 		if iplen == 4 {
 			ipfrom = as_u32_u128(as_le_u32(row_ipfrom))
 			ipto = as_u32_u128(as_le_u32(row_ipto))
@@ -283,6 +550,9 @@ func (db *DB) Lookup(a netip.Addr) (r Record, err error) {
 
 		// binary search cases
 		if ip.Less(ipfrom) {
+			if mid == 0 {
+				break
+			}
 			upper = mid - 1
 			continue
 		}
@@ -295,6 +565,7 @@ func (db *DB) Lookup(a netip.Addr) (r Record, err error) {
 		r.r = db.r
 		r.s = db.s
 		r.d = row_data
+		r.c = db.c
 		break
 	}
 	return
@@ -325,15 +596,32 @@ type Range struct {
 	To   netip.Addr // exclusive
 }
 
-// Each iterates over all rows in the database until fn returns false.
+// Each iterates over all rows in the database until fn returns false. Errors
+// reading the database stop the iteration, so use [DB.EachErr] if a partial
+// result is not acceptable.
 func (db *DB) Each(fn func(Range, Record) bool) {
-	if fn != nil && db.s != nil {
-		db.each(false, fn)
-		db.each(true, fn)
+	db.EachErr(fn)
+}
+
+// EachErr is like [DB.Each], but returns the error which stopped the iteration,
+// if any.
+func (db *DB) EachErr(fn func(Range, Record) bool) error {
+	if fn == nil || db.s == nil {
+		return nil
+	}
+	ok, err := db.each(false, fn)
+	if ok && err == nil {
+		_, err = db.each(true, fn)
 	}
+	return err
 }
 
-func (db *DB) each(v6 bool, fn func(Range, Record) bool) {
+// each iterates over the IPv4 or IPv6 rows in the database until fn returns
+// false, returning false if fn returned false or an error occurred.
+func (db *DB) each(v6 bool, fn func(Range, Record) bool) (bool, error) {
+	if db.n4 != nil || db.n6 != nil {
+		return db.eachNative(v6, fn)
+	}
 	var (
 		iplen   int
 		ipcount uint32
@@ -367,12 +655,12 @@ func (db *DB) each(v6 bool, fn func(Range, Record) bool) {
 	)
 
 	// read all rows
-	for idx := uint32(0); idx < ipcount-1; idx++ {
-		off := idx*uint32(colsize) + (ipbase - 1)
+	for idx := uint32(1); idx < ipcount; idx++ {
+		off := (idx-1)*uint32(colsize) + (ipbase - 1)
 
 		// read the row
 		if _, err := db.r.ReadAt(row, int64(off)); err != nil {
-			return
+			return false, err
 		}
 
 		// get the row start/end range
@@ -394,14 +682,19 @@ func (db *DB) each(v6 bool, fn func(Range, Record) bool) {
 				r: db.r,
 				s: db.s,
 				d: row_data,
+				c: db.c,
 			},
 		) {
-			return
+			return false, nil
 		}
 	}
+	return true, nil
 }
 
 // Default options for [Record.String].
+//
+// Deprecated: These are global and cannot be safely changed while records are
+// being formatted. Use a [Formatter] instead.
 var (
 	RecordStringColor     = false
 	RecordStringMultiline = false
@@ -412,6 +705,7 @@ type Record struct {
 	r io.ReaderAt
 	s *dbS
 	d []byte
+	c *strCache // optional
 }
 
 // IsValid checks whether the record is pointing to a database row.
@@ -426,117 +720,139 @@ func (r Record) String() string {
 }
 
 // Format gets and formats all fields in the record as a human-readable
-// string. Note that this is highly inefficient.
+// string using a [Formatter] with the default color scheme. Note that this is
+// highly inefficient.
 func (r Record) Format(color, multiline bool) string {
+	return formatter(color, multiline, nil).Format(r)
+}
+
+// AppendFormat is like [Record.Format], but appends to dst instead. If fields
+// are specified, only those fields are included, in the specified order.
+func (r Record) AppendFormat(dst []byte, color, multiline bool, fields ...DBField) []byte {
+	return formatter(color, multiline, fields).Append(dst, r)
+}
+
+// MarshalJSON encodes the record as JSON.
+func (r Record) MarshalJSON() ([]byte, error) {
+	return r.AppendJSON(make([]byte, 0, 256))
+}
+
+// AppendJSON is like [Record.MarshalJSON], but appends to dst instead. If
+// fields are specified, only those fields are included, in the specified
+// order. If an error occurs, dst is returned unmodified.
+func (r Record) AppendJSON(dst []byte, fields ...DBField) ([]byte, error) {
 	if !r.IsValid() {
-		return ""
-	}
-	s := make([]byte, 0, 512)
-	if color {
-		s = append(s, "\x1b[34m"...)
-	}
-	_, p, t := r.s.Info()
-	s = append(s, p.product()...)
-	if color {
-		s = append(s, "\x1b[0m"...)
-	}
-	s = append(s, '<')
-	s = append(s, p.prefix()...)
-	s = strconv.AppendInt(s, int64(t), 10)
-	s = append(s, '>')
-	if color {
-		s = append(s, "\x1b[0m"...)
-	}
-	if multiline {
-		s = append(s, "{\n  "...)
-	} else {
-		s = append(s, '{')
-	}
-	for n, f := 0, DBField(1); f <= dbFieldMax; f++ {
-		if dt, fd, err := r.get(f); fd.IsValid() { // field exists
-			if n++; n > 1 {
-				if multiline {
-					s = append(s, "\n  "...)
-				} else {
-					s = append(s, ' ')
+		return append(dst, "null"...), nil
+	}
+	var rerr error
+	b, n := append(dst, '{'), 0
+	r.each(fields, false, func(f DBField, fd dbI, dt []byte, err error) bool {
+		if dt == nil {
+			rerr = err
+			return err == nil
+		}
+		if n++; n > 1 {
+			b = append(b, ',')
+		}
+		b = append(b, '"')
+		b = append(b, f.String()...)
+		b = append(b, '"', ':')
+		if fd.Type() == dbtype_str {
+			b = appendJSONString(b, as_strref_unsafe(dt))
+		} else {
+			b = appendNumber(b, fd.Type(), dt, -1)
+		}
+		return true
+	})
+	if rerr != nil {
+		return dst, rerr
+	}
+	b = append(b, '}')
+	return b, nil
+}
+
+// each calls fn for each field present in r until fn returns false. If fields
+// is empty, all fields are used in order. If missing is true, fn is also called
+// for specified fields which are not present, with a zero fd. If the data could
+// not be read, dt will be nil and err will be set. The data is only valid until
+// fn returns.
+func (r Record) each(fields []DBField, missing bool, fn func(f DBField, fd dbI, dt []byte, err error) bool) {
+	if len(fields) == 0 && !r.IsValid() {
+		return
+	}
+	buf := getbufPool.Get().(*[getbufSize]byte)
+	defer getbufPool.Put(buf)
+	if len(fields) == 0 {
+		for f, m := DBField(1), r.s.FieldMax(); f <= m; f++ {
+			if dt, fd, err := r.getb(f, buf[:]); fd.IsValid() {
+				if !fn(f, fd, dt, err) {
+					return
 				}
 			}
-			if color {
-				s = append(s, "\x1b[35m"...)
-			}
-			s = append(s, f.String()...)
-			if color {
-				s = append(s, "\x1b[0m"...)
-			}
-			if multiline {
-				s = append(s, " "...)
-			} else {
-				s = append(s, '=')
-			}
-			if dt != nil {
-				switch fd.Type() {
-				case dbtype_str:
-					if color {
-						s = append(s, "\x1b[33m"...)
-					}
-					s = strconv.AppendQuote(s, as_strref_unsafe(dt))
-				case dbtype_f32:
-					if color {
-						s = append(s, "\x1b[32m"...)
-					}
-					s = strconv.AppendFloat(s, float64(as_f32(as_le_u32(dt))), 'f', -1, 32)
-				}
-			} else if err != nil {
-				if color {
-					s = append(s, "\x1b[31m"...)
+		}
+	} else {
+		for _, f := range fields {
+			if dt, fd, err := r.getb(f, buf[:]); fd.IsValid() || missing {
+				if !fn(f, fd, dt, err) {
+					return
 				}
-				s = append(s, "<error: "...)
-				s = append(s, err.Error()...)
-				s = append(s, '>')
-			}
-			if color {
-				s = append(s, "\x1b[0m"...)
 			}
 		}
 	}
-	if multiline {
-		s = append(s, "\n}"...)
-	} else {
-		s = append(s, '}')
+}
+
+// decode calls fn with the index and data of each of fields, returning an
+// error if any are missing or could not be read. The data is only valid until
+// fn returns.
+func (r Record) decode(fields []DBField, fn func(i int, dt []byte)) error {
+	if !r.IsValid() {
+		return errors.New("record is not valid")
 	}
-	if color {
-		s = append(s, "\x1b[0m"...)
+	buf := getbufPool.Get().(*[getbufSize]byte)
+	defer getbufPool.Put(buf)
+	for i, f := range fields {
+		dt, fd, err := r.getb(f, buf[:])
+		if !fd.IsValid() {
+			return errors.New("record does not contain " + f.String())
+		}
+		if dt == nil {
+			return err
+		}
+		fn(i, dt)
 	}
-	return as_strref_unsafe(s)
+	return nil
 }
 
-// MarshalJSON encodes the record as JSON.
-func (r Record) MarshalJSON() ([]byte, error) {
-	if !r.IsValid() {
-		return []byte("null"), nil
-	}
-	b := make([]byte, 0, 256)
-	b = append(b, '{')
-	for n, f := 0, DBField(1); f <= dbFieldMax; f++ {
-		if dt, fd, err := r.get(f); dt != nil {
-			if n++; n > 1 {
-				b = append(b, ',')
+// count returns the number of fields which each would call fn for if missing
+// is false.
+func (r Record) count(fields []DBField) (n int) {
+	if r.IsValid() {
+		if len(fields) == 0 {
+			for f, m := DBField(1), r.s.FieldMax(); f <= m; f++ {
+				if r.s.Field(f).IsValid() {
+					n++
+				}
 			}
-			b = append(b, '"')
-			b = append(b, f.String()...)
-			b = append(b, '"', ':')
-			switch fd.Type() {
-			case dbtype_str:
-				b = strconv.AppendQuote(b, as_strref_unsafe(dt))
-			case dbtype_f32:
-				b = strconv.AppendFloat(b, float64(as_f32(as_le_u32(dt))), 'f', -1, 32)
+		} else {
+			for _, f := range fields {
+				if r.s.Field(f).IsValid() {
+					n++
+				}
 			}
-		} else if err != nil {
-			return nil, err
 		}
 	}
-	b = append(b, '}')
-	return b, nil
+	return
+}
+
+// getbufSize is the maximum size of a pointer field's data.
+const getbufSize = 1 + 0xFF // length byte + max length
+
+// getbufPool contains buffers for reading pointer fields when the data doesn't
+// need to outlive the caller.
+var getbufPool = sync.Pool{
+	New: func() any {
+		return new([getbufSize]byte)
+	},
 }
 
 // Get gets f as the default type. If an error occurs or the field is not
@@ -549,6 +865,14 @@ func (r Record) Get(f DBField) any {
 			return as_strref_unsafe(dt)
 		case dbtype_f32:
 			return as_f32(as_le_u32(dt))
+		case dbtype_u32:
+			return as_le_u32(dt)
+		case dbtype_i32:
+			return int32(as_le_u32(dt))
+		case dbtype_f64:
+			return as_f64(as_le_u64(dt))
+		case dbtype_u128:
+			return as_le_u128(dt).big()
 		}
 	}
 	return nil
@@ -560,8 +884,8 @@ func (r Record) GetString(f DBField) (string, bool) {
 		switch fd.Type() {
 		case dbtype_str:
 			return as_strref_unsafe(dt), true
-		case dbtype_f32:
-			return strconv.FormatFloat(float64(as_f32(as_le_u32(dt))), 'f', -1, 32), true
+		default:
+			return string(appendNumber(nil, fd.Type(), dt, -1)), true
 		}
 	}
 	return "", false
@@ -577,11 +901,140 @@ func (r Record) GetFloat32(f DBField) (float32, bool) {
 			}
 		case dbtype_f32:
 			return as_f32(as_le_u32(dt)), true
+		case dbtype_u32:
+			return float32(as_le_u32(dt)), true
+		case dbtype_i32:
+			return float32(int32(as_le_u32(dt))), true
+		case dbtype_f64:
+			return float32(as_f64(as_le_u64(dt))), true
+		}
+	}
+	return 0, false
+}
+
+// GetFloat64 gets f as a float64, if possible.
+func (r Record) GetFloat64(f DBField) (float64, bool) {
+	if dt, fd, _ := r.get(f); dt != nil {
+		switch fd.Type() {
+		case dbtype_str:
+			if v, err := strconv.ParseFloat(as_strref_unsafe(dt), 64); err == nil {
+				return v, true
+			}
+		case dbtype_f32:
+			return float64(as_f32(as_le_u32(dt))), true
+		case dbtype_u32:
+			return float64(as_le_u32(dt)), true
+		case dbtype_i32:
+			return float64(int32(as_le_u32(dt))), true
+		case dbtype_f64:
+			return as_f64(as_le_u64(dt)), true
+		case dbtype_u128:
+			v, _ := new(big.Float).SetInt(as_le_u128(dt).big()).Float64()
+			return v, true
+		}
+	}
+	return 0, false
+}
+
+// GetUint32 gets f as a uint32, if it is an integer which fits.
+func (r Record) GetUint32(f DBField) (uint32, bool) {
+	if dt, fd, _ := r.get(f); dt != nil {
+		switch fd.Type() {
+		case dbtype_str:
+			if v, err := strconv.ParseUint(as_strref_unsafe(dt), 10, 32); err == nil {
+				return uint32(v), true
+			}
+		case dbtype_u32:
+			return as_le_u32(dt), true
+		case dbtype_i32:
+			if v := int32(as_le_u32(dt)); v >= 0 {
+				return uint32(v), true
+			}
+		case dbtype_u128:
+			if v := as_le_u128(dt); v.hi == 0 && v.lo <= math.MaxUint32 {
+				return uint32(v.lo), true
+			}
 		}
 	}
 	return 0, false
 }
 
+// GetInt32 gets f as an int32, if it is an integer which fits.
+func (r Record) GetInt32(f DBField) (int32, bool) {
+	if dt, fd, _ := r.get(f); dt != nil {
+		switch fd.Type() {
+		case dbtype_str:
+			if v, err := strconv.ParseInt(as_strref_unsafe(dt), 10, 32); err == nil {
+				return int32(v), true
+			}
+		case dbtype_u32:
+			if v := as_le_u32(dt); v <= math.MaxInt32 {
+				return int32(v), true
+			}
+		case dbtype_i32:
+			return int32(as_le_u32(dt)), true
+		case dbtype_u128:
+			if v := as_le_u128(dt); v.hi == 0 && v.lo <= math.MaxInt32 {
+				return int32(v.lo), true
+			}
+		}
+	}
+	return 0, false
+}
+
+// GetBigInt gets f as a new big.Int, if it is an integer.
+func (r Record) GetBigInt(f DBField) (*big.Int, bool) {
+	if dt, fd, _ := r.get(f); dt != nil {
+		switch fd.Type() {
+		case dbtype_str:
+			return new(big.Int).SetString(as_strref_unsafe(dt), 10)
+		case dbtype_u32:
+			return new(big.Int).SetUint64(uint64(as_le_u32(dt))), true
+		case dbtype_i32:
+			return big.NewInt(int64(int32(as_le_u32(dt)))), true
+		case dbtype_u128:
+			return as_le_u128(dt).big(), true
+		}
+	}
+	return nil, false
+}
+
+// Column gets the raw value of column n, starting at 2 since column 1 is
+// always ip_from. This is mainly useful for debugging or reading databases
+// opened with [Raw].
+func (r Record) Column(n int) (uint32, bool) {
+	if r.IsValid() && n >= 2 {
+		if off := (n - 2) * 4; off+4 <= len(r.d) {
+			return as_le_u32(r.d[off:]), true
+		}
+	}
+	return 0, false
+}
+
+// ColumnFloat32 gets the raw value of column n as a float32.
+func (r Record) ColumnFloat32(n int) (float32, bool) {
+	v, ok := r.Column(n)
+	return as_f32(v), ok
+}
+
+// ColumnString reads the length-prefixed string pointed to by the raw value of
+// column n plus add.
+func (r Record) ColumnString(n int, add uint32) (string, error) {
+	v, ok := r.Column(n)
+	if !ok {
+		return "", errors.New("column " + strconv.Itoa(n) + " does not exist")
+	}
+	var b [getbufSize]byte
+	c, err := r.r.ReadAt(b[:], int64(v)+int64(add))
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	if c == 0 || c <= int(b[0]) {
+		return "", io.ErrUnexpectedEOF
+	}
+	return string(b[1 : 1+b[0]]), nil
+}
+
 // get gets the raw bytes and field descriptor f in r.
 //   - If !r.IsValid or the field does not exist, dt, fd, and err will be zero.
 //   - If an error occurs while reading the data, dt will be nil, fd will be
@@ -590,6 +1043,11 @@ func (r Record) GetFloat32(f DBField) (float32, bool) {
 //     unexpected EOF), dt will be nil, fd will be valid, and err will be set.
 //   - Otherwise, dt will be set, fd will be valid, and err will be nil.
 func (r Record) get(f DBField) (dt []byte, fd dbI, err error) {
+	return r.getb(f, nil)
+}
+
+// getb is like get, but reads pointer fields into buf if it is large enough.
+func (r Record) getb(f DBField, buf []byte) (dt []byte, fd dbI, err error) {
 	if !r.IsValid() {
 		return
 	}
@@ -602,25 +1060,44 @@ func (r Record) get(f DBField) (dt []byte, fd dbI, err error) {
 	var sz int
 	switch fd.Type() {
 	case dbtype_str:
-		sz = 1 + 0xFF // length byte + max length
-	case dbtype_f32:
-		sz = 32 / 4
+		sz = getbufSize
+	case dbtype_f32, dbtype_u32, dbtype_i32:
+		sz = 4
+	case dbtype_f64:
+		sz = 8
+	case dbtype_u128:
+		sz = 16
 	default:
 		panic("unhandled dbft")
 	}
 
 	// get column data offset (relative to end of IPFrom column)
-	off := (fd.Column() - 2) * 4
+	off := (int(fd.Column()) - 2) * 4
 
 	// get field data
-	var data []byte
+	var (
+		data   []byte
+		ptr    uint32
+		intern bool
+	)
 	if ^fd.PtrOffset() == 0 {
 		if data = r.d[off:]; len(data) > int(sz) {
 			data = data[:sz]
 		}
 	} else {
 		if data = r.d[off:]; len(data) >= 4 {
-			b := make([]byte, sz)
+			if intern = fd.Type() == dbtype_str && r.c != nil; intern {
+				ptr = as_le_u32(data) + uint32(fd.PtrOffset())
+				if dt, ok := r.c.get(ptr); ok {
+					return dt, fd, nil
+				}
+			}
+			b := buf
+			if len(b) < sz {
+				b = make([]byte, sz)
+			} else {
+				b = b[:sz]
+			}
 			var n int
 			if n, err = r.r.ReadAt(b, int64(as_le_u32(data)+uint32(fd.PtrOffset()))); err == nil || err == io.EOF {
 				data = b[:n]
@@ -637,7 +1114,7 @@ func (r Record) get(f DBField) (dt []byte, fd dbI, err error) {
 			if len(data) > int(data[0]) {
 				dt = data[1 : 1+data[0]]
 			}
-		case dbtype_f32:
+		case dbtype_f32, dbtype_u32, dbtype_i32, dbtype_f64, dbtype_u128:
 			if len(data) >= int(sz) {
 				dt = data
 			}
@@ -647,10 +1124,35 @@ func (r Record) get(f DBField) (dt []byte, fd dbI, err error) {
 	}
 	if dt == nil {
 		err = io.ErrUnexpectedEOF // too short
+	} else if intern {
+		dt = r.c.put(ptr, dt)
 	}
 	return
 }
 
+// appendNumber appends the numeric field data dt of type t in decimal, using
+// the specified precision for floats.
+func appendNumber(b []byte, t uint8, dt []byte, prec int) []byte {
+	switch t {
+	case dbtype_f32:
+		return strconv.AppendFloat(b, float64(as_f32(as_le_u32(dt))), 'f', prec, 32)
+	case dbtype_u32:
+		return strconv.AppendUint(b, uint64(as_le_u32(dt)), 10)
+	case dbtype_i32:
+		return strconv.AppendInt(b, int64(int32(as_le_u32(dt))), 10)
+	case dbtype_f64:
+		return strconv.AppendFloat(b, as_f64(as_le_u64(dt)), 'f', prec, 64)
+	case dbtype_u128:
+		if v := as_le_u128(dt); v.hi == 0 {
+			return strconv.AppendUint(b, v.lo, 10)
+		} else {
+			return v.big().Append(b, 10)
+		}
+	default:
+		panic("unhandled dbft")
+	}
+}
+
 // as_le_u32 returns the uint32 represented by the little-endian b.
 func as_le_u32(b []byte) uint32 {
 	_ = b[3] // bounds check hint to compiler; see golang.org/issue/14808
@@ -685,11 +1187,25 @@ func as_f32(u uint32) float32 {
 	return *(*float32)(unsafe.Pointer(&u)) // math.Float32frombits
 }
 
+// as_f64 returns the float64 represented by u.
+func as_f64(u uint64) float64 {
+	return *(*float64)(unsafe.Pointer(&u)) // math.Float64frombits
+}
+
 // as_strref_unsafe returns b as a string sharing the underlying data.
 func as_strref_unsafe(b []byte) string {
 	return *(*string)(unsafe.Pointer(&b)) // strings.Builder
 }
 
+// as_bytesref_unsafe returns s as a byte slice without copying. It must not be
+// modified.
+func as_bytesref_unsafe(s string) []byte {
+	return *(*[]byte)(unsafe.Pointer(&struct {
+		string
+		int
+	}{s, len(s)}))
+}
+
 // as_ip6_uint128 returns a as a uint128 representing an IPv4-mapped or native
 // IPv6.
 func as_ip6_uint128(a netip.Addr) uint128 {
@@ -705,7 +1221,7 @@ func as_u32_u128(u32 uint32) uint128 {
 	return uint128{lo: uint64(u32)}
 }
 
-// as_le_u128 reads a big-endian uint128 from b.
+// as_le_u128 reads a little-endian uint128 from b.
 func as_le_u128(b []byte) uint128 {
 	_ = b[15] // bounds check hint to compiler; see golang.org/issue/14808
 	return uint128{
@@ -82,10 +82,10 @@ const AddressType codegen.Field = "address_type"
 // See https://www.ip2location.com/area-code-coverage.
 const AreaCode codegen.Field = "area_code"
 
-// Autonomous system number (ASN).
+// Autonomous system (AS) name.
 const AS codegen.Field = "as"
 
-// Autonomous system (AS) name.
+// Autonomous system number (ASN).
 const ASN codegen.Field = "asn"
 
 // The domain category is based on IAB Tech Lab Content Taxonomy.
@@ -4,7 +4,7 @@ package ip2x
 
 import "strconv"
 
-//go:generate go run dbdata.go
+//go:generate go run dbdata.go -jsonschema schema -openapi schema/openapi.json -proto schema/ip2x.proto -structs
 
 // IP2Location™ IP Address Geolocation Database provides a solution to deduce
 // the geolocation of a device connected to the Internet and to determine the
@@ -40,12 +40,12 @@ const AddressType DBField = 1
 // In DB15-16, DB18, DB20-22, DB24-26.
 const AreaCode DBField = 2
 
-// Autonomous system number (ASN).
+// Autonomous system (AS) name.
 //
 // In DB26, PX7-12.
 const AS DBField = 3
 
-// Autonomous system (AS) name.
+// Autonomous system number (ASN).
 //
 // In DB26, PX7-12.
 const ASN DBField = 4
@@ -263,46 +263,46 @@ const ASRange DBField = 33
 
 var _dbs = dbs{
 	IP2Location: {
-		1:  {CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, dbField_extra: {2, uint8(IP2Location), 1}},
-		2:  {CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, ISP: {3, 0, dbtype_str}, dbField_extra: {3, uint8(IP2Location), 2}},
-		3:  {CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, dbField_extra: {4, uint8(IP2Location), 3}},
-		4:  {CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, ISP: {5, 0, dbtype_str}, dbField_extra: {5, uint8(IP2Location), 4}},
-		5:  {CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, Latitude: {5, 255, dbtype_f32}, Longitude: {6, 255, dbtype_f32}, dbField_extra: {6, uint8(IP2Location), 5}},
-		6:  {CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, Latitude: {5, 255, dbtype_f32}, Longitude: {6, 255, dbtype_f32}, ISP: {7, 0, dbtype_str}, dbField_extra: {7, uint8(IP2Location), 6}},
-		7:  {CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, ISP: {5, 0, dbtype_str}, Domain: {6, 0, dbtype_str}, dbField_extra: {6, uint8(IP2Location), 7}},
-		8:  {CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, Latitude: {5, 255, dbtype_f32}, Longitude: {6, 255, dbtype_f32}, ISP: {7, 0, dbtype_str}, Domain: {8, 0, dbtype_str}, dbField_extra: {8, uint8(IP2Location), 8}},
-		9:  {CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, Latitude: {5, 255, dbtype_f32}, Longitude: {6, 255, dbtype_f32}, Zipcode: {7, 0, dbtype_str}, dbField_extra: {7, uint8(IP2Location), 9}},
-		10: {CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, Latitude: {5, 255, dbtype_f32}, Longitude: {6, 255, dbtype_f32}, Zipcode: {7, 0, dbtype_str}, ISP: {8, 0, dbtype_str}, Domain: {9, 0, dbtype_str}, dbField_extra: {9, uint8(IP2Location), 10}},
-		11: {CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, Latitude: {5, 255, dbtype_f32}, Longitude: {6, 255, dbtype_f32}, Zipcode: {7, 0, dbtype_str}, Timezone: {8, 0, dbtype_str}, dbField_extra: {8, uint8(IP2Location), 11}},
-		12: {CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, Latitude: {5, 255, dbtype_f32}, Longitude: {6, 255, dbtype_f32}, Zipcode: {7, 0, dbtype_str}, Timezone: {8, 0, dbtype_str}, ISP: {9, 0, dbtype_str}, Domain: {10, 0, dbtype_str}, dbField_extra: {10, uint8(IP2Location), 12}},
-		13: {CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, Latitude: {5, 255, dbtype_f32}, Longitude: {6, 255, dbtype_f32}, Timezone: {7, 0, dbtype_str}, NetSpeed: {8, 0, dbtype_str}, dbField_extra: {8, uint8(IP2Location), 13}},
-		14: {CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, Latitude: {5, 255, dbtype_f32}, Longitude: {6, 255, dbtype_f32}, Zipcode: {7, 0, dbtype_str}, Timezone: {8, 0, dbtype_str}, ISP: {9, 0, dbtype_str}, Domain: {10, 0, dbtype_str}, NetSpeed: {11, 0, dbtype_str}, dbField_extra: {11, uint8(IP2Location), 14}},
-		15: {CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, Latitude: {5, 255, dbtype_f32}, Longitude: {6, 255, dbtype_f32}, Zipcode: {7, 0, dbtype_str}, Timezone: {8, 0, dbtype_str}, IDDCode: {9, 0, dbtype_str}, AreaCode: {10, 0, dbtype_str}, dbField_extra: {10, uint8(IP2Location), 15}},
-		16: {CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, Latitude: {5, 255, dbtype_f32}, Longitude: {6, 255, dbtype_f32}, Zipcode: {7, 0, dbtype_str}, Timezone: {8, 0, dbtype_str}, ISP: {9, 0, dbtype_str}, Domain: {10, 0, dbtype_str}, NetSpeed: {11, 0, dbtype_str}, IDDCode: {12, 0, dbtype_str}, AreaCode: {13, 0, dbtype_str}, dbField_extra: {13, uint8(IP2Location), 16}},
-		17: {CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, Latitude: {5, 255, dbtype_f32}, Longitude: {6, 255, dbtype_f32}, Timezone: {7, 0, dbtype_str}, NetSpeed: {8, 0, dbtype_str}, WeatherStationCode: {9, 0, dbtype_str}, WeatherStationName: {10, 0, dbtype_str}, dbField_extra: {10, uint8(IP2Location), 17}},
-		18: {CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, Latitude: {5, 255, dbtype_f32}, Longitude: {6, 255, dbtype_f32}, Zipcode: {7, 0, dbtype_str}, Timezone: {8, 0, dbtype_str}, ISP: {9, 0, dbtype_str}, Domain: {10, 0, dbtype_str}, NetSpeed: {11, 0, dbtype_str}, IDDCode: {12, 0, dbtype_str}, AreaCode: {13, 0, dbtype_str}, WeatherStationCode: {14, 0, dbtype_str}, WeatherStationName: {15, 0, dbtype_str}, dbField_extra: {15, uint8(IP2Location), 18}},
-		19: {CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, Latitude: {5, 255, dbtype_f32}, Longitude: {6, 255, dbtype_f32}, ISP: {7, 0, dbtype_str}, Domain: {8, 0, dbtype_str}, MCC: {9, 0, dbtype_str}, MNC: {10, 0, dbtype_str}, MobileBrand: {11, 0, dbtype_str}, dbField_extra: {11, uint8(IP2Location), 19}},
-		20: {CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, Latitude: {5, 255, dbtype_f32}, Longitude: {6, 255, dbtype_f32}, Zipcode: {7, 0, dbtype_str}, Timezone: {8, 0, dbtype_str}, ISP: {9, 0, dbtype_str}, Domain: {10, 0, dbtype_str}, NetSpeed: {11, 0, dbtype_str}, IDDCode: {12, 0, dbtype_str}, AreaCode: {13, 0, dbtype_str}, WeatherStationCode: {14, 0, dbtype_str}, WeatherStationName: {15, 0, dbtype_str}, MCC: {16, 0, dbtype_str}, MNC: {17, 0, dbtype_str}, MobileBrand: {18, 0, dbtype_str}, dbField_extra: {18, uint8(IP2Location), 20}},
-		21: {CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, Latitude: {5, 255, dbtype_f32}, Longitude: {6, 255, dbtype_f32}, Zipcode: {7, 0, dbtype_str}, Timezone: {8, 0, dbtype_str}, IDDCode: {9, 0, dbtype_str}, AreaCode: {10, 0, dbtype_str}, Elevation: {11, 0, dbtype_str}, dbField_extra: {11, uint8(IP2Location), 21}},
-		22: {CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, Latitude: {5, 255, dbtype_f32}, Longitude: {6, 255, dbtype_f32}, Zipcode: {7, 0, dbtype_str}, Timezone: {8, 0, dbtype_str}, ISP: {9, 0, dbtype_str}, Domain: {10, 0, dbtype_str}, NetSpeed: {11, 0, dbtype_str}, IDDCode: {12, 0, dbtype_str}, AreaCode: {13, 0, dbtype_str}, WeatherStationCode: {14, 0, dbtype_str}, WeatherStationName: {15, 0, dbtype_str}, MCC: {16, 0, dbtype_str}, MNC: {17, 0, dbtype_str}, MobileBrand: {18, 0, dbtype_str}, Elevation: {19, 0, dbtype_str}, dbField_extra: {19, uint8(IP2Location), 22}},
-		23: {CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, Latitude: {5, 255, dbtype_f32}, Longitude: {6, 255, dbtype_f32}, ISP: {7, 0, dbtype_str}, Domain: {8, 0, dbtype_str}, MCC: {9, 0, dbtype_str}, MNC: {10, 0, dbtype_str}, MobileBrand: {11, 0, dbtype_str}, UsageType: {12, 0, dbtype_str}, dbField_extra: {12, uint8(IP2Location), 23}},
-		24: {CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, Latitude: {5, 255, dbtype_f32}, Longitude: {6, 255, dbtype_f32}, Zipcode: {7, 0, dbtype_str}, Timezone: {8, 0, dbtype_str}, ISP: {9, 0, dbtype_str}, Domain: {10, 0, dbtype_str}, NetSpeed: {11, 0, dbtype_str}, IDDCode: {12, 0, dbtype_str}, AreaCode: {13, 0, dbtype_str}, WeatherStationCode: {14, 0, dbtype_str}, WeatherStationName: {15, 0, dbtype_str}, MCC: {16, 0, dbtype_str}, MNC: {17, 0, dbtype_str}, MobileBrand: {18, 0, dbtype_str}, Elevation: {19, 0, dbtype_str}, UsageType: {20, 0, dbtype_str}, dbField_extra: {20, uint8(IP2Location), 24}},
-		25: {CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, Latitude: {5, 255, dbtype_f32}, Longitude: {6, 255, dbtype_f32}, Zipcode: {7, 0, dbtype_str}, Timezone: {8, 0, dbtype_str}, ISP: {9, 0, dbtype_str}, Domain: {10, 0, dbtype_str}, NetSpeed: {11, 0, dbtype_str}, IDDCode: {12, 0, dbtype_str}, AreaCode: {13, 0, dbtype_str}, WeatherStationCode: {14, 0, dbtype_str}, WeatherStationName: {15, 0, dbtype_str}, MCC: {16, 0, dbtype_str}, MNC: {17, 0, dbtype_str}, MobileBrand: {18, 0, dbtype_str}, Elevation: {19, 0, dbtype_str}, UsageType: {20, 0, dbtype_str}, AddressType: {21, 0, dbtype_str}, Category: {22, 0, dbtype_str}, dbField_extra: {22, uint8(IP2Location), 25}},
-		26: {CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, Latitude: {5, 255, dbtype_f32}, Longitude: {6, 255, dbtype_f32}, Zipcode: {7, 0, dbtype_str}, Timezone: {8, 0, dbtype_str}, ISP: {9, 0, dbtype_str}, Domain: {10, 0, dbtype_str}, NetSpeed: {11, 0, dbtype_str}, IDDCode: {12, 0, dbtype_str}, AreaCode: {13, 0, dbtype_str}, WeatherStationCode: {14, 0, dbtype_str}, WeatherStationName: {15, 0, dbtype_str}, MCC: {16, 0, dbtype_str}, MNC: {17, 0, dbtype_str}, MobileBrand: {18, 0, dbtype_str}, Elevation: {19, 0, dbtype_str}, UsageType: {20, 0, dbtype_str}, AddressType: {21, 0, dbtype_str}, Category: {22, 0, dbtype_str}, District: {23, 0, dbtype_str}, ASN: {24, 0, dbtype_str}, AS: {25, 0, dbtype_str}, ASDomain: {26, 0, dbtype_str}, ASUsageType: {27, 0, dbtype_str}, ASRange: {28, 0, dbtype_str}, dbField_extra: {28, uint8(IP2Location), 26}},
+		1:  {f: dbF{CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, dbField_extra: {2, uint8(IP2Location), 1}}},
+		2:  {f: dbF{CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, ISP: {3, 0, dbtype_str}, dbField_extra: {3, uint8(IP2Location), 2}}},
+		3:  {f: dbF{CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, dbField_extra: {4, uint8(IP2Location), 3}}},
+		4:  {f: dbF{CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, ISP: {5, 0, dbtype_str}, dbField_extra: {5, uint8(IP2Location), 4}}},
+		5:  {f: dbF{CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, Latitude: {5, 255, dbtype_f32}, Longitude: {6, 255, dbtype_f32}, dbField_extra: {6, uint8(IP2Location), 5}}},
+		6:  {f: dbF{CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, Latitude: {5, 255, dbtype_f32}, Longitude: {6, 255, dbtype_f32}, ISP: {7, 0, dbtype_str}, dbField_extra: {7, uint8(IP2Location), 6}}},
+		7:  {f: dbF{CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, ISP: {5, 0, dbtype_str}, Domain: {6, 0, dbtype_str}, dbField_extra: {6, uint8(IP2Location), 7}}},
+		8:  {f: dbF{CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, Latitude: {5, 255, dbtype_f32}, Longitude: {6, 255, dbtype_f32}, ISP: {7, 0, dbtype_str}, Domain: {8, 0, dbtype_str}, dbField_extra: {8, uint8(IP2Location), 8}}},
+		9:  {f: dbF{CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, Latitude: {5, 255, dbtype_f32}, Longitude: {6, 255, dbtype_f32}, Zipcode: {7, 0, dbtype_str}, dbField_extra: {7, uint8(IP2Location), 9}}},
+		10: {f: dbF{CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, Latitude: {5, 255, dbtype_f32}, Longitude: {6, 255, dbtype_f32}, Zipcode: {7, 0, dbtype_str}, ISP: {8, 0, dbtype_str}, Domain: {9, 0, dbtype_str}, dbField_extra: {9, uint8(IP2Location), 10}}},
+		11: {f: dbF{CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, Latitude: {5, 255, dbtype_f32}, Longitude: {6, 255, dbtype_f32}, Zipcode: {7, 0, dbtype_str}, Timezone: {8, 0, dbtype_str}, dbField_extra: {8, uint8(IP2Location), 11}}},
+		12: {f: dbF{CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, Latitude: {5, 255, dbtype_f32}, Longitude: {6, 255, dbtype_f32}, Zipcode: {7, 0, dbtype_str}, Timezone: {8, 0, dbtype_str}, ISP: {9, 0, dbtype_str}, Domain: {10, 0, dbtype_str}, dbField_extra: {10, uint8(IP2Location), 12}}},
+		13: {f: dbF{CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, Latitude: {5, 255, dbtype_f32}, Longitude: {6, 255, dbtype_f32}, Timezone: {7, 0, dbtype_str}, NetSpeed: {8, 0, dbtype_str}, dbField_extra: {8, uint8(IP2Location), 13}}},
+		14: {f: dbF{CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, Latitude: {5, 255, dbtype_f32}, Longitude: {6, 255, dbtype_f32}, Zipcode: {7, 0, dbtype_str}, Timezone: {8, 0, dbtype_str}, ISP: {9, 0, dbtype_str}, Domain: {10, 0, dbtype_str}, NetSpeed: {11, 0, dbtype_str}, dbField_extra: {11, uint8(IP2Location), 14}}},
+		15: {f: dbF{CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, Latitude: {5, 255, dbtype_f32}, Longitude: {6, 255, dbtype_f32}, Zipcode: {7, 0, dbtype_str}, Timezone: {8, 0, dbtype_str}, IDDCode: {9, 0, dbtype_str}, AreaCode: {10, 0, dbtype_str}, dbField_extra: {10, uint8(IP2Location), 15}}},
+		16: {f: dbF{CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, Latitude: {5, 255, dbtype_f32}, Longitude: {6, 255, dbtype_f32}, Zipcode: {7, 0, dbtype_str}, Timezone: {8, 0, dbtype_str}, ISP: {9, 0, dbtype_str}, Domain: {10, 0, dbtype_str}, NetSpeed: {11, 0, dbtype_str}, IDDCode: {12, 0, dbtype_str}, AreaCode: {13, 0, dbtype_str}, dbField_extra: {13, uint8(IP2Location), 16}}},
+		17: {f: dbF{CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, Latitude: {5, 255, dbtype_f32}, Longitude: {6, 255, dbtype_f32}, Timezone: {7, 0, dbtype_str}, NetSpeed: {8, 0, dbtype_str}, WeatherStationCode: {9, 0, dbtype_str}, WeatherStationName: {10, 0, dbtype_str}, dbField_extra: {10, uint8(IP2Location), 17}}},
+		18: {f: dbF{CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, Latitude: {5, 255, dbtype_f32}, Longitude: {6, 255, dbtype_f32}, Zipcode: {7, 0, dbtype_str}, Timezone: {8, 0, dbtype_str}, ISP: {9, 0, dbtype_str}, Domain: {10, 0, dbtype_str}, NetSpeed: {11, 0, dbtype_str}, IDDCode: {12, 0, dbtype_str}, AreaCode: {13, 0, dbtype_str}, WeatherStationCode: {14, 0, dbtype_str}, WeatherStationName: {15, 0, dbtype_str}, dbField_extra: {15, uint8(IP2Location), 18}}},
+		19: {f: dbF{CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, Latitude: {5, 255, dbtype_f32}, Longitude: {6, 255, dbtype_f32}, ISP: {7, 0, dbtype_str}, Domain: {8, 0, dbtype_str}, MCC: {9, 0, dbtype_str}, MNC: {10, 0, dbtype_str}, MobileBrand: {11, 0, dbtype_str}, dbField_extra: {11, uint8(IP2Location), 19}}},
+		20: {f: dbF{CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, Latitude: {5, 255, dbtype_f32}, Longitude: {6, 255, dbtype_f32}, Zipcode: {7, 0, dbtype_str}, Timezone: {8, 0, dbtype_str}, ISP: {9, 0, dbtype_str}, Domain: {10, 0, dbtype_str}, NetSpeed: {11, 0, dbtype_str}, IDDCode: {12, 0, dbtype_str}, AreaCode: {13, 0, dbtype_str}, WeatherStationCode: {14, 0, dbtype_str}, WeatherStationName: {15, 0, dbtype_str}, MCC: {16, 0, dbtype_str}, MNC: {17, 0, dbtype_str}, MobileBrand: {18, 0, dbtype_str}, dbField_extra: {18, uint8(IP2Location), 20}}},
+		21: {f: dbF{CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, Latitude: {5, 255, dbtype_f32}, Longitude: {6, 255, dbtype_f32}, Zipcode: {7, 0, dbtype_str}, Timezone: {8, 0, dbtype_str}, IDDCode: {9, 0, dbtype_str}, AreaCode: {10, 0, dbtype_str}, Elevation: {11, 0, dbtype_str}, dbField_extra: {11, uint8(IP2Location), 21}}},
+		22: {f: dbF{CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, Latitude: {5, 255, dbtype_f32}, Longitude: {6, 255, dbtype_f32}, Zipcode: {7, 0, dbtype_str}, Timezone: {8, 0, dbtype_str}, ISP: {9, 0, dbtype_str}, Domain: {10, 0, dbtype_str}, NetSpeed: {11, 0, dbtype_str}, IDDCode: {12, 0, dbtype_str}, AreaCode: {13, 0, dbtype_str}, WeatherStationCode: {14, 0, dbtype_str}, WeatherStationName: {15, 0, dbtype_str}, MCC: {16, 0, dbtype_str}, MNC: {17, 0, dbtype_str}, MobileBrand: {18, 0, dbtype_str}, Elevation: {19, 0, dbtype_str}, dbField_extra: {19, uint8(IP2Location), 22}}},
+		23: {f: dbF{CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, Latitude: {5, 255, dbtype_f32}, Longitude: {6, 255, dbtype_f32}, ISP: {7, 0, dbtype_str}, Domain: {8, 0, dbtype_str}, MCC: {9, 0, dbtype_str}, MNC: {10, 0, dbtype_str}, MobileBrand: {11, 0, dbtype_str}, UsageType: {12, 0, dbtype_str}, dbField_extra: {12, uint8(IP2Location), 23}}},
+		24: {f: dbF{CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, Latitude: {5, 255, dbtype_f32}, Longitude: {6, 255, dbtype_f32}, Zipcode: {7, 0, dbtype_str}, Timezone: {8, 0, dbtype_str}, ISP: {9, 0, dbtype_str}, Domain: {10, 0, dbtype_str}, NetSpeed: {11, 0, dbtype_str}, IDDCode: {12, 0, dbtype_str}, AreaCode: {13, 0, dbtype_str}, WeatherStationCode: {14, 0, dbtype_str}, WeatherStationName: {15, 0, dbtype_str}, MCC: {16, 0, dbtype_str}, MNC: {17, 0, dbtype_str}, MobileBrand: {18, 0, dbtype_str}, Elevation: {19, 0, dbtype_str}, UsageType: {20, 0, dbtype_str}, dbField_extra: {20, uint8(IP2Location), 24}}},
+		25: {f: dbF{CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, Latitude: {5, 255, dbtype_f32}, Longitude: {6, 255, dbtype_f32}, Zipcode: {7, 0, dbtype_str}, Timezone: {8, 0, dbtype_str}, ISP: {9, 0, dbtype_str}, Domain: {10, 0, dbtype_str}, NetSpeed: {11, 0, dbtype_str}, IDDCode: {12, 0, dbtype_str}, AreaCode: {13, 0, dbtype_str}, WeatherStationCode: {14, 0, dbtype_str}, WeatherStationName: {15, 0, dbtype_str}, MCC: {16, 0, dbtype_str}, MNC: {17, 0, dbtype_str}, MobileBrand: {18, 0, dbtype_str}, Elevation: {19, 0, dbtype_str}, UsageType: {20, 0, dbtype_str}, AddressType: {21, 0, dbtype_str}, Category: {22, 0, dbtype_str}, dbField_extra: {22, uint8(IP2Location), 25}}},
+		26: {f: dbF{CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, Region: {3, 0, dbtype_str}, City: {4, 0, dbtype_str}, Latitude: {5, 255, dbtype_f32}, Longitude: {6, 255, dbtype_f32}, Zipcode: {7, 0, dbtype_str}, Timezone: {8, 0, dbtype_str}, ISP: {9, 0, dbtype_str}, Domain: {10, 0, dbtype_str}, NetSpeed: {11, 0, dbtype_str}, IDDCode: {12, 0, dbtype_str}, AreaCode: {13, 0, dbtype_str}, WeatherStationCode: {14, 0, dbtype_str}, WeatherStationName: {15, 0, dbtype_str}, MCC: {16, 0, dbtype_str}, MNC: {17, 0, dbtype_str}, MobileBrand: {18, 0, dbtype_str}, Elevation: {19, 0, dbtype_str}, UsageType: {20, 0, dbtype_str}, AddressType: {21, 0, dbtype_str}, Category: {22, 0, dbtype_str}, District: {23, 0, dbtype_str}, ASN: {24, 0, dbtype_str}, AS: {25, 0, dbtype_str}, ASDomain: {26, 0, dbtype_str}, ASUsageType: {27, 0, dbtype_str}, ASRange: {28, 0, dbtype_str}, dbField_extra: {28, uint8(IP2Location), 26}}},
 	},
 	IP2Proxy: {
-		1:  {CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, dbField_extra: {2, uint8(IP2Proxy), 1}},
-		2:  {CountryCode: {3, 0, dbtype_str}, CountryName: {3, 3, dbtype_str}, ProxyType: {2, 0, dbtype_str}, dbField_extra: {3, uint8(IP2Proxy), 2}},
-		3:  {CountryCode: {3, 0, dbtype_str}, CountryName: {3, 3, dbtype_str}, ProxyType: {2, 0, dbtype_str}, Region: {4, 0, dbtype_str}, City: {5, 0, dbtype_str}, dbField_extra: {5, uint8(IP2Proxy), 3}},
-		4:  {CountryCode: {3, 0, dbtype_str}, CountryName: {3, 3, dbtype_str}, ProxyType: {2, 0, dbtype_str}, Region: {4, 0, dbtype_str}, City: {5, 0, dbtype_str}, ISP: {6, 0, dbtype_str}, dbField_extra: {6, uint8(IP2Proxy), 4}},
-		5:  {CountryCode: {3, 0, dbtype_str}, CountryName: {3, 3, dbtype_str}, ProxyType: {2, 0, dbtype_str}, Region: {4, 0, dbtype_str}, City: {5, 0, dbtype_str}, ISP: {6, 0, dbtype_str}, Domain: {7, 0, dbtype_str}, dbField_extra: {7, uint8(IP2Proxy), 5}},
-		6:  {CountryCode: {3, 0, dbtype_str}, CountryName: {3, 3, dbtype_str}, ProxyType: {2, 0, dbtype_str}, Region: {4, 0, dbtype_str}, City: {5, 0, dbtype_str}, ISP: {6, 0, dbtype_str}, Domain: {7, 0, dbtype_str}, UsageType: {8, 0, dbtype_str}, dbField_extra: {8, uint8(IP2Proxy), 6}},
-		7:  {CountryCode: {3, 0, dbtype_str}, CountryName: {3, 3, dbtype_str}, ProxyType: {2, 0, dbtype_str}, Region: {4, 0, dbtype_str}, City: {5, 0, dbtype_str}, ISP: {6, 0, dbtype_str}, Domain: {7, 0, dbtype_str}, UsageType: {8, 0, dbtype_str}, ASN: {9, 0, dbtype_str}, AS: {10, 0, dbtype_str}, dbField_extra: {10, uint8(IP2Proxy), 7}},
-		8:  {CountryCode: {3, 0, dbtype_str}, CountryName: {3, 3, dbtype_str}, ProxyType: {2, 0, dbtype_str}, Region: {4, 0, dbtype_str}, City: {5, 0, dbtype_str}, ISP: {6, 0, dbtype_str}, Domain: {7, 0, dbtype_str}, UsageType: {8, 0, dbtype_str}, ASN: {9, 0, dbtype_str}, AS: {10, 0, dbtype_str}, LastSeen: {11, 0, dbtype_str}, dbField_extra: {11, uint8(IP2Proxy), 8}},
-		9:  {CountryCode: {3, 0, dbtype_str}, CountryName: {3, 3, dbtype_str}, ProxyType: {2, 0, dbtype_str}, Region: {4, 0, dbtype_str}, City: {5, 0, dbtype_str}, ISP: {6, 0, dbtype_str}, Domain: {7, 0, dbtype_str}, UsageType: {8, 0, dbtype_str}, ASN: {9, 0, dbtype_str}, AS: {10, 0, dbtype_str}, LastSeen: {11, 0, dbtype_str}, Threat: {12, 0, dbtype_str}, dbField_extra: {12, uint8(IP2Proxy), 9}},
-		10: {CountryCode: {3, 0, dbtype_str}, CountryName: {3, 3, dbtype_str}, ProxyType: {2, 0, dbtype_str}, Region: {4, 0, dbtype_str}, City: {5, 0, dbtype_str}, ISP: {6, 0, dbtype_str}, Domain: {7, 0, dbtype_str}, UsageType: {8, 0, dbtype_str}, ASN: {9, 0, dbtype_str}, AS: {10, 0, dbtype_str}, LastSeen: {11, 0, dbtype_str}, Threat: {12, 0, dbtype_str}, dbField_extra: {12, uint8(IP2Proxy), 10}},
-		11: {CountryCode: {3, 0, dbtype_str}, CountryName: {3, 3, dbtype_str}, ProxyType: {2, 0, dbtype_str}, Region: {4, 0, dbtype_str}, City: {5, 0, dbtype_str}, ISP: {6, 0, dbtype_str}, Domain: {7, 0, dbtype_str}, UsageType: {8, 0, dbtype_str}, ASN: {9, 0, dbtype_str}, AS: {10, 0, dbtype_str}, LastSeen: {11, 0, dbtype_str}, Threat: {12, 0, dbtype_str}, Provider: {13, 0, dbtype_str}, dbField_extra: {13, uint8(IP2Proxy), 11}},
-		12: {CountryCode: {3, 0, dbtype_str}, CountryName: {3, 3, dbtype_str}, ProxyType: {2, 0, dbtype_str}, Region: {4, 0, dbtype_str}, City: {5, 0, dbtype_str}, ISP: {6, 0, dbtype_str}, Domain: {7, 0, dbtype_str}, UsageType: {8, 0, dbtype_str}, ASN: {9, 0, dbtype_str}, AS: {10, 0, dbtype_str}, LastSeen: {11, 0, dbtype_str}, Threat: {12, 0, dbtype_str}, Provider: {13, 0, dbtype_str}, FraudScore: {14, 0, dbtype_str}, dbField_extra: {14, uint8(IP2Proxy), 12}},
+		1:  {f: dbF{CountryCode: {2, 0, dbtype_str}, CountryName: {2, 3, dbtype_str}, dbField_extra: {2, uint8(IP2Proxy), 1}}},
+		2:  {f: dbF{CountryCode: {3, 0, dbtype_str}, CountryName: {3, 3, dbtype_str}, ProxyType: {2, 0, dbtype_str}, dbField_extra: {3, uint8(IP2Proxy), 2}}},
+		3:  {f: dbF{CountryCode: {3, 0, dbtype_str}, CountryName: {3, 3, dbtype_str}, ProxyType: {2, 0, dbtype_str}, Region: {4, 0, dbtype_str}, City: {5, 0, dbtype_str}, dbField_extra: {5, uint8(IP2Proxy), 3}}},
+		4:  {f: dbF{CountryCode: {3, 0, dbtype_str}, CountryName: {3, 3, dbtype_str}, ProxyType: {2, 0, dbtype_str}, Region: {4, 0, dbtype_str}, City: {5, 0, dbtype_str}, ISP: {6, 0, dbtype_str}, dbField_extra: {6, uint8(IP2Proxy), 4}}},
+		5:  {f: dbF{CountryCode: {3, 0, dbtype_str}, CountryName: {3, 3, dbtype_str}, ProxyType: {2, 0, dbtype_str}, Region: {4, 0, dbtype_str}, City: {5, 0, dbtype_str}, ISP: {6, 0, dbtype_str}, Domain: {7, 0, dbtype_str}, dbField_extra: {7, uint8(IP2Proxy), 5}}},
+		6:  {f: dbF{CountryCode: {3, 0, dbtype_str}, CountryName: {3, 3, dbtype_str}, ProxyType: {2, 0, dbtype_str}, Region: {4, 0, dbtype_str}, City: {5, 0, dbtype_str}, ISP: {6, 0, dbtype_str}, Domain: {7, 0, dbtype_str}, UsageType: {8, 0, dbtype_str}, dbField_extra: {8, uint8(IP2Proxy), 6}}},
+		7:  {f: dbF{CountryCode: {3, 0, dbtype_str}, CountryName: {3, 3, dbtype_str}, ProxyType: {2, 0, dbtype_str}, Region: {4, 0, dbtype_str}, City: {5, 0, dbtype_str}, ISP: {6, 0, dbtype_str}, Domain: {7, 0, dbtype_str}, UsageType: {8, 0, dbtype_str}, ASN: {9, 0, dbtype_str}, AS: {10, 0, dbtype_str}, dbField_extra: {10, uint8(IP2Proxy), 7}}},
+		8:  {f: dbF{CountryCode: {3, 0, dbtype_str}, CountryName: {3, 3, dbtype_str}, ProxyType: {2, 0, dbtype_str}, Region: {4, 0, dbtype_str}, City: {5, 0, dbtype_str}, ISP: {6, 0, dbtype_str}, Domain: {7, 0, dbtype_str}, UsageType: {8, 0, dbtype_str}, ASN: {9, 0, dbtype_str}, AS: {10, 0, dbtype_str}, LastSeen: {11, 0, dbtype_str}, dbField_extra: {11, uint8(IP2Proxy), 8}}},
+		9:  {f: dbF{CountryCode: {3, 0, dbtype_str}, CountryName: {3, 3, dbtype_str}, ProxyType: {2, 0, dbtype_str}, Region: {4, 0, dbtype_str}, City: {5, 0, dbtype_str}, ISP: {6, 0, dbtype_str}, Domain: {7, 0, dbtype_str}, UsageType: {8, 0, dbtype_str}, ASN: {9, 0, dbtype_str}, AS: {10, 0, dbtype_str}, LastSeen: {11, 0, dbtype_str}, Threat: {12, 0, dbtype_str}, dbField_extra: {12, uint8(IP2Proxy), 9}}},
+		10: {f: dbF{CountryCode: {3, 0, dbtype_str}, CountryName: {3, 3, dbtype_str}, ProxyType: {2, 0, dbtype_str}, Region: {4, 0, dbtype_str}, City: {5, 0, dbtype_str}, ISP: {6, 0, dbtype_str}, Domain: {7, 0, dbtype_str}, UsageType: {8, 0, dbtype_str}, ASN: {9, 0, dbtype_str}, AS: {10, 0, dbtype_str}, LastSeen: {11, 0, dbtype_str}, Threat: {12, 0, dbtype_str}, dbField_extra: {12, uint8(IP2Proxy), 10}}},
+		11: {f: dbF{CountryCode: {3, 0, dbtype_str}, CountryName: {3, 3, dbtype_str}, ProxyType: {2, 0, dbtype_str}, Region: {4, 0, dbtype_str}, City: {5, 0, dbtype_str}, ISP: {6, 0, dbtype_str}, Domain: {7, 0, dbtype_str}, UsageType: {8, 0, dbtype_str}, ASN: {9, 0, dbtype_str}, AS: {10, 0, dbtype_str}, LastSeen: {11, 0, dbtype_str}, Threat: {12, 0, dbtype_str}, Provider: {13, 0, dbtype_str}, dbField_extra: {13, uint8(IP2Proxy), 11}}},
+		12: {f: dbF{CountryCode: {3, 0, dbtype_str}, CountryName: {3, 3, dbtype_str}, ProxyType: {2, 0, dbtype_str}, Region: {4, 0, dbtype_str}, City: {5, 0, dbtype_str}, ISP: {6, 0, dbtype_str}, Domain: {7, 0, dbtype_str}, UsageType: {8, 0, dbtype_str}, ASN: {9, 0, dbtype_str}, AS: {10, 0, dbtype_str}, LastSeen: {11, 0, dbtype_str}, Threat: {12, 0, dbtype_str}, Provider: {13, 0, dbtype_str}, FraudScore: {14, 0, dbtype_str}, dbField_extra: {14, uint8(IP2Proxy), 12}}},
 	},
 }
 
@@ -318,7 +318,11 @@ type dbI struct {
 	ptr uint8
 	typ uint8
 }
-type dbS [dbFieldMax + 2]dbI
+type dbF [dbFieldMax + 2]dbI
+type dbS struct {
+	f dbF
+	x []dbI // registered fields after dbField_extra
+}
 type dbs [dbProductMax + 1][dbTypeMax + 1]dbS
 
 func dbinfo(p DBProduct, t DBType) (r *dbS) {
@@ -329,15 +333,26 @@ func dbinfo(p DBProduct, t DBType) (r *dbS) {
 }
 
 func (i *dbS) Field(f DBField) (r dbI) {
-	if i != nil && f <= dbFieldMax {
-		r = i[f]
+	if i != nil {
+		if f <= dbFieldMax {
+			r = i.f[f]
+		} else if f > dbField_extra && f-dbField_extra <= DBField(len(i.x)) {
+			r = i.x[f-dbField_extra-1]
+		}
 	}
 	return
 }
 
+func (i *dbS) FieldMax() DBField {
+	if i != nil && len(i.x) != 0 {
+		return dbField_extra + DBField(len(i.x))
+	}
+	return dbFieldMax
+}
+
 func (i *dbS) Info() (c uint8, p DBProduct, t DBType) {
 	if i != nil {
-		x := i[dbField_extra]
+		x := i.f[dbField_extra]
 		c, p, t = x.col, DBProduct(x.ptr), DBType(x.typ)
 	}
 	return
@@ -349,44 +364,2075 @@ func (c dbI) IsPointer() bool  { return ^c.ptr == 0 }
 func (c dbI) PtrOffset() uint8 { return c.ptr }
 func (c dbI) Type() uint8      { return c.typ }
 
+func dbProductByName(s string) DBProduct {
+	switch s {
+	case "ip2location", "db":
+		return IP2Location
+	case "ip2proxy", "px":
+		return IP2Proxy
+	}
+	return 0
+}
+
+func dbFieldByName(s string) DBField {
+	switch s {
+	case "address_type", "addresstype":
+		return AddressType
+	case "area_code", "areacode":
+		return AreaCode
+	case "as":
+		return AS
+	case "asn":
+		return ASN
+	case "category":
+		return Category
+	case "city":
+		return City
+	case "country_code", "countrycode":
+		return CountryCode
+	case "country_name", "countryname":
+		return CountryName
+	case "domain":
+		return Domain
+	case "district":
+		return District
+	case "elevation":
+		return Elevation
+	case "idd_code", "iddcode":
+		return IDDCode
+	case "isp":
+		return ISP
+	case "last_seen", "lastseen":
+		return LastSeen
+	case "latitude":
+		return Latitude
+	case "longitude":
+		return Longitude
+	case "mcc":
+		return MCC
+	case "mnc":
+		return MNC
+	case "mobile_brand", "mobilebrand":
+		return MobileBrand
+	case "net_speed", "netspeed":
+		return NetSpeed
+	case "provider":
+		return Provider
+	case "proxy_type", "proxytype":
+		return ProxyType
+	case "region":
+		return Region
+	case "threat":
+		return Threat
+	case "time_zone", "timezone":
+		return Timezone
+	case "usage_type", "usagetype":
+		return UsageType
+	case "weather_station_code", "weatherstationcode":
+		return WeatherStationCode
+	case "weather_station_name", "weatherstationname":
+		return WeatherStationName
+	case "zip_code", "zipcode":
+		return Zipcode
+	case "fraud_score", "fraudscore":
+		return FraudScore
+	case "as_domain", "asdomain":
+		return ASDomain
+	case "as_usage_type", "asusagetype":
+		return ASUsageType
+	case "as_cidr", "asrange":
+		return ASRange
+	}
+	return 0
+}
+
+func (f DBField) typ() (t uint8, ok bool) {
+	switch f {
+	case AddressType, AreaCode, AS, ASN, Category, City, CountryCode, CountryName, Domain, District, Elevation, IDDCode, ISP, LastSeen, MCC, MNC, MobileBrand, NetSpeed, Provider, ProxyType, Region, Threat, Timezone, UsageType, WeatherStationCode, WeatherStationName, Zipcode, FraudScore, ASDomain, ASUsageType, ASRange:
+		return dbtype_str, true
+	case Latitude, Longitude:
+		return dbtype_f32, true
+	}
+	return
+}
+
+// AddressType gets the [AddressType] field.
+func (r Record) AddressType() (string, bool) {
+	return r.GetString(AddressType)
+}
+
+// AreaCode gets the [AreaCode] field.
+func (r Record) AreaCode() (string, bool) {
+	return r.GetString(AreaCode)
+}
+
+// AS gets the [AS] field.
+func (r Record) AS() (string, bool) {
+	return r.GetString(AS)
+}
+
+// ASN gets the [ASN] field.
+func (r Record) ASN() (string, bool) {
+	return r.GetString(ASN)
+}
+
+// Category gets the [Category] field.
+func (r Record) Category() (string, bool) {
+	return r.GetString(Category)
+}
+
+// City gets the [City] field.
+func (r Record) City() (string, bool) {
+	return r.GetString(City)
+}
+
+// CountryCode gets the [CountryCode] field.
+func (r Record) CountryCode() (string, bool) {
+	return r.GetString(CountryCode)
+}
+
+// CountryName gets the [CountryName] field.
+func (r Record) CountryName() (string, bool) {
+	return r.GetString(CountryName)
+}
+
+// Domain gets the [Domain] field.
+func (r Record) Domain() (string, bool) {
+	return r.GetString(Domain)
+}
+
+// District gets the [District] field.
+func (r Record) District() (string, bool) {
+	return r.GetString(District)
+}
+
+// Elevation gets the [Elevation] field.
+func (r Record) Elevation() (string, bool) {
+	return r.GetString(Elevation)
+}
+
+// IDDCode gets the [IDDCode] field.
+func (r Record) IDDCode() (string, bool) {
+	return r.GetString(IDDCode)
+}
+
+// ISP gets the [ISP] field.
+func (r Record) ISP() (string, bool) {
+	return r.GetString(ISP)
+}
+
+// LastSeen gets the [LastSeen] field.
+func (r Record) LastSeen() (string, bool) {
+	return r.GetString(LastSeen)
+}
+
+// Latitude gets the [Latitude] field.
+func (r Record) Latitude() (float32, bool) {
+	return r.GetFloat32(Latitude)
+}
+
+// Longitude gets the [Longitude] field.
+func (r Record) Longitude() (float32, bool) {
+	return r.GetFloat32(Longitude)
+}
+
+// MCC gets the [MCC] field.
+func (r Record) MCC() (string, bool) {
+	return r.GetString(MCC)
+}
+
+// MNC gets the [MNC] field.
+func (r Record) MNC() (string, bool) {
+	return r.GetString(MNC)
+}
+
+// MobileBrand gets the [MobileBrand] field.
+func (r Record) MobileBrand() (string, bool) {
+	return r.GetString(MobileBrand)
+}
+
+// NetSpeed gets the [NetSpeed] field.
+func (r Record) NetSpeed() (string, bool) {
+	return r.GetString(NetSpeed)
+}
+
+// Provider gets the [Provider] field.
+func (r Record) Provider() (string, bool) {
+	return r.GetString(Provider)
+}
+
+// ProxyType gets the [ProxyType] field.
+func (r Record) ProxyType() (string, bool) {
+	return r.GetString(ProxyType)
+}
+
+// Region gets the [Region] field.
+func (r Record) Region() (string, bool) {
+	return r.GetString(Region)
+}
+
+// Threat gets the [Threat] field.
+func (r Record) Threat() (string, bool) {
+	return r.GetString(Threat)
+}
+
+// Timezone gets the [Timezone] field.
+func (r Record) Timezone() (string, bool) {
+	return r.GetString(Timezone)
+}
+
+// UsageType gets the [UsageType] field.
+func (r Record) UsageType() (string, bool) {
+	return r.GetString(UsageType)
+}
+
+// WeatherStationCode gets the [WeatherStationCode] field.
+func (r Record) WeatherStationCode() (string, bool) {
+	return r.GetString(WeatherStationCode)
+}
+
+// WeatherStationName gets the [WeatherStationName] field.
+func (r Record) WeatherStationName() (string, bool) {
+	return r.GetString(WeatherStationName)
+}
+
+// Zipcode gets the [Zipcode] field.
+func (r Record) Zipcode() (string, bool) {
+	return r.GetString(Zipcode)
+}
+
+// FraudScore gets the [FraudScore] field.
+func (r Record) FraudScore() (string, bool) {
+	return r.GetString(FraudScore)
+}
+
+// ASDomain gets the [ASDomain] field.
+func (r Record) ASDomain() (string, bool) {
+	return r.GetString(ASDomain)
+}
+
+// ASUsageType gets the [ASUsageType] field.
+func (r Record) ASUsageType() (string, bool) {
+	return r.GetString(ASUsageType)
+}
+
+// ASRange gets the [ASRange] field.
+func (r Record) ASRange() (string, bool) {
+	return r.GetString(ASRange)
+}
+
+// DB1Record contains the fields of IP2Location DB1 databases.
+type DB1Record struct {
+	CountryCode string
+	CountryName string
+}
+
+var _DB1Record_fields = [...]DBField{CountryCode, CountryName}
+
+// ToDB1 gets the fields of a [DB1Record] from r, which must contain all of
+// them (i.e., be from a DB1 database or any other type containing its fields).
+func (r Record) ToDB1() (v DB1Record, err error) {
+	err = r.decode(_DB1Record_fields[:], func(i int, dt []byte) {
+		switch i {
+		case 0:
+			v.CountryCode = string(dt)
+		case 1:
+			v.CountryName = string(dt)
+		}
+	})
+	return
+}
+
+// DB2Record contains the fields of IP2Location DB2 databases.
+type DB2Record struct {
+	CountryCode string
+	CountryName string
+	ISP         string
+}
+
+var _DB2Record_fields = [...]DBField{CountryCode, CountryName, ISP}
+
+// ToDB2 gets the fields of a [DB2Record] from r, which must contain all of
+// them (i.e., be from a DB2 database or any other type containing its fields).
+func (r Record) ToDB2() (v DB2Record, err error) {
+	err = r.decode(_DB2Record_fields[:], func(i int, dt []byte) {
+		switch i {
+		case 0:
+			v.CountryCode = string(dt)
+		case 1:
+			v.CountryName = string(dt)
+		case 2:
+			v.ISP = string(dt)
+		}
+	})
+	return
+}
+
+// DB3Record contains the fields of IP2Location DB3 databases.
+type DB3Record struct {
+	CountryCode string
+	CountryName string
+	Region      string
+	City        string
+}
+
+var _DB3Record_fields = [...]DBField{CountryCode, CountryName, Region, City}
+
+// ToDB3 gets the fields of a [DB3Record] from r, which must contain all of
+// them (i.e., be from a DB3 database or any other type containing its fields).
+func (r Record) ToDB3() (v DB3Record, err error) {
+	err = r.decode(_DB3Record_fields[:], func(i int, dt []byte) {
+		switch i {
+		case 0:
+			v.CountryCode = string(dt)
+		case 1:
+			v.CountryName = string(dt)
+		case 2:
+			v.Region = string(dt)
+		case 3:
+			v.City = string(dt)
+		}
+	})
+	return
+}
+
+// DB4Record contains the fields of IP2Location DB4 databases.
+type DB4Record struct {
+	CountryCode string
+	CountryName string
+	Region      string
+	City        string
+	ISP         string
+}
+
+var _DB4Record_fields = [...]DBField{CountryCode, CountryName, Region, City, ISP}
+
+// ToDB4 gets the fields of a [DB4Record] from r, which must contain all of
+// them (i.e., be from a DB4 database or any other type containing its fields).
+func (r Record) ToDB4() (v DB4Record, err error) {
+	err = r.decode(_DB4Record_fields[:], func(i int, dt []byte) {
+		switch i {
+		case 0:
+			v.CountryCode = string(dt)
+		case 1:
+			v.CountryName = string(dt)
+		case 2:
+			v.Region = string(dt)
+		case 3:
+			v.City = string(dt)
+		case 4:
+			v.ISP = string(dt)
+		}
+	})
+	return
+}
+
+// DB5Record contains the fields of IP2Location DB5 databases.
+type DB5Record struct {
+	CountryCode string
+	CountryName string
+	Region      string
+	City        string
+	Latitude    float32
+	Longitude   float32
+}
+
+var _DB5Record_fields = [...]DBField{CountryCode, CountryName, Region, City, Latitude, Longitude}
+
+// ToDB5 gets the fields of a [DB5Record] from r, which must contain all of
+// them (i.e., be from a DB5 database or any other type containing its fields).
+func (r Record) ToDB5() (v DB5Record, err error) {
+	err = r.decode(_DB5Record_fields[:], func(i int, dt []byte) {
+		switch i {
+		case 0:
+			v.CountryCode = string(dt)
+		case 1:
+			v.CountryName = string(dt)
+		case 2:
+			v.Region = string(dt)
+		case 3:
+			v.City = string(dt)
+		case 4:
+			v.Latitude = as_f32(as_le_u32(dt))
+		case 5:
+			v.Longitude = as_f32(as_le_u32(dt))
+		}
+	})
+	return
+}
+
+// DB6Record contains the fields of IP2Location DB6 databases.
+type DB6Record struct {
+	CountryCode string
+	CountryName string
+	Region      string
+	City        string
+	Latitude    float32
+	Longitude   float32
+	ISP         string
+}
+
+var _DB6Record_fields = [...]DBField{CountryCode, CountryName, Region, City, Latitude, Longitude, ISP}
+
+// ToDB6 gets the fields of a [DB6Record] from r, which must contain all of
+// them (i.e., be from a DB6 database or any other type containing its fields).
+func (r Record) ToDB6() (v DB6Record, err error) {
+	err = r.decode(_DB6Record_fields[:], func(i int, dt []byte) {
+		switch i {
+		case 0:
+			v.CountryCode = string(dt)
+		case 1:
+			v.CountryName = string(dt)
+		case 2:
+			v.Region = string(dt)
+		case 3:
+			v.City = string(dt)
+		case 4:
+			v.Latitude = as_f32(as_le_u32(dt))
+		case 5:
+			v.Longitude = as_f32(as_le_u32(dt))
+		case 6:
+			v.ISP = string(dt)
+		}
+	})
+	return
+}
+
+// DB7Record contains the fields of IP2Location DB7 databases.
+type DB7Record struct {
+	CountryCode string
+	CountryName string
+	Region      string
+	City        string
+	ISP         string
+	Domain      string
+}
+
+var _DB7Record_fields = [...]DBField{CountryCode, CountryName, Region, City, ISP, Domain}
+
+// ToDB7 gets the fields of a [DB7Record] from r, which must contain all of
+// them (i.e., be from a DB7 database or any other type containing its fields).
+func (r Record) ToDB7() (v DB7Record, err error) {
+	err = r.decode(_DB7Record_fields[:], func(i int, dt []byte) {
+		switch i {
+		case 0:
+			v.CountryCode = string(dt)
+		case 1:
+			v.CountryName = string(dt)
+		case 2:
+			v.Region = string(dt)
+		case 3:
+			v.City = string(dt)
+		case 4:
+			v.ISP = string(dt)
+		case 5:
+			v.Domain = string(dt)
+		}
+	})
+	return
+}
+
+// DB8Record contains the fields of IP2Location DB8 databases.
+type DB8Record struct {
+	CountryCode string
+	CountryName string
+	Region      string
+	City        string
+	Latitude    float32
+	Longitude   float32
+	ISP         string
+	Domain      string
+}
+
+var _DB8Record_fields = [...]DBField{CountryCode, CountryName, Region, City, Latitude, Longitude, ISP, Domain}
+
+// ToDB8 gets the fields of a [DB8Record] from r, which must contain all of
+// them (i.e., be from a DB8 database or any other type containing its fields).
+func (r Record) ToDB8() (v DB8Record, err error) {
+	err = r.decode(_DB8Record_fields[:], func(i int, dt []byte) {
+		switch i {
+		case 0:
+			v.CountryCode = string(dt)
+		case 1:
+			v.CountryName = string(dt)
+		case 2:
+			v.Region = string(dt)
+		case 3:
+			v.City = string(dt)
+		case 4:
+			v.Latitude = as_f32(as_le_u32(dt))
+		case 5:
+			v.Longitude = as_f32(as_le_u32(dt))
+		case 6:
+			v.ISP = string(dt)
+		case 7:
+			v.Domain = string(dt)
+		}
+	})
+	return
+}
+
+// DB9Record contains the fields of IP2Location DB9 databases.
+type DB9Record struct {
+	CountryCode string
+	CountryName string
+	Region      string
+	City        string
+	Latitude    float32
+	Longitude   float32
+	Zipcode     string
+}
+
+var _DB9Record_fields = [...]DBField{CountryCode, CountryName, Region, City, Latitude, Longitude, Zipcode}
+
+// ToDB9 gets the fields of a [DB9Record] from r, which must contain all of
+// them (i.e., be from a DB9 database or any other type containing its fields).
+func (r Record) ToDB9() (v DB9Record, err error) {
+	err = r.decode(_DB9Record_fields[:], func(i int, dt []byte) {
+		switch i {
+		case 0:
+			v.CountryCode = string(dt)
+		case 1:
+			v.CountryName = string(dt)
+		case 2:
+			v.Region = string(dt)
+		case 3:
+			v.City = string(dt)
+		case 4:
+			v.Latitude = as_f32(as_le_u32(dt))
+		case 5:
+			v.Longitude = as_f32(as_le_u32(dt))
+		case 6:
+			v.Zipcode = string(dt)
+		}
+	})
+	return
+}
+
+// DB10Record contains the fields of IP2Location DB10 databases.
+type DB10Record struct {
+	CountryCode string
+	CountryName string
+	Region      string
+	City        string
+	Latitude    float32
+	Longitude   float32
+	Zipcode     string
+	ISP         string
+	Domain      string
+}
+
+var _DB10Record_fields = [...]DBField{CountryCode, CountryName, Region, City, Latitude, Longitude, Zipcode, ISP, Domain}
+
+// ToDB10 gets the fields of a [DB10Record] from r, which must contain all of
+// them (i.e., be from a DB10 database or any other type containing its fields).
+func (r Record) ToDB10() (v DB10Record, err error) {
+	err = r.decode(_DB10Record_fields[:], func(i int, dt []byte) {
+		switch i {
+		case 0:
+			v.CountryCode = string(dt)
+		case 1:
+			v.CountryName = string(dt)
+		case 2:
+			v.Region = string(dt)
+		case 3:
+			v.City = string(dt)
+		case 4:
+			v.Latitude = as_f32(as_le_u32(dt))
+		case 5:
+			v.Longitude = as_f32(as_le_u32(dt))
+		case 6:
+			v.Zipcode = string(dt)
+		case 7:
+			v.ISP = string(dt)
+		case 8:
+			v.Domain = string(dt)
+		}
+	})
+	return
+}
+
+// DB11Record contains the fields of IP2Location DB11 databases.
+type DB11Record struct {
+	CountryCode string
+	CountryName string
+	Region      string
+	City        string
+	Latitude    float32
+	Longitude   float32
+	Zipcode     string
+	Timezone    string
+}
+
+var _DB11Record_fields = [...]DBField{CountryCode, CountryName, Region, City, Latitude, Longitude, Zipcode, Timezone}
+
+// ToDB11 gets the fields of a [DB11Record] from r, which must contain all of
+// them (i.e., be from a DB11 database or any other type containing its fields).
+func (r Record) ToDB11() (v DB11Record, err error) {
+	err = r.decode(_DB11Record_fields[:], func(i int, dt []byte) {
+		switch i {
+		case 0:
+			v.CountryCode = string(dt)
+		case 1:
+			v.CountryName = string(dt)
+		case 2:
+			v.Region = string(dt)
+		case 3:
+			v.City = string(dt)
+		case 4:
+			v.Latitude = as_f32(as_le_u32(dt))
+		case 5:
+			v.Longitude = as_f32(as_le_u32(dt))
+		case 6:
+			v.Zipcode = string(dt)
+		case 7:
+			v.Timezone = string(dt)
+		}
+	})
+	return
+}
+
+// DB12Record contains the fields of IP2Location DB12 databases.
+type DB12Record struct {
+	CountryCode string
+	CountryName string
+	Region      string
+	City        string
+	Latitude    float32
+	Longitude   float32
+	Zipcode     string
+	Timezone    string
+	ISP         string
+	Domain      string
+}
+
+var _DB12Record_fields = [...]DBField{CountryCode, CountryName, Region, City, Latitude, Longitude, Zipcode, Timezone, ISP, Domain}
+
+// ToDB12 gets the fields of a [DB12Record] from r, which must contain all of
+// them (i.e., be from a DB12 database or any other type containing its fields).
+func (r Record) ToDB12() (v DB12Record, err error) {
+	err = r.decode(_DB12Record_fields[:], func(i int, dt []byte) {
+		switch i {
+		case 0:
+			v.CountryCode = string(dt)
+		case 1:
+			v.CountryName = string(dt)
+		case 2:
+			v.Region = string(dt)
+		case 3:
+			v.City = string(dt)
+		case 4:
+			v.Latitude = as_f32(as_le_u32(dt))
+		case 5:
+			v.Longitude = as_f32(as_le_u32(dt))
+		case 6:
+			v.Zipcode = string(dt)
+		case 7:
+			v.Timezone = string(dt)
+		case 8:
+			v.ISP = string(dt)
+		case 9:
+			v.Domain = string(dt)
+		}
+	})
+	return
+}
+
+// DB13Record contains the fields of IP2Location DB13 databases.
+type DB13Record struct {
+	CountryCode string
+	CountryName string
+	Region      string
+	City        string
+	Latitude    float32
+	Longitude   float32
+	Timezone    string
+	NetSpeed    string
+}
+
+var _DB13Record_fields = [...]DBField{CountryCode, CountryName, Region, City, Latitude, Longitude, Timezone, NetSpeed}
+
+// ToDB13 gets the fields of a [DB13Record] from r, which must contain all of
+// them (i.e., be from a DB13 database or any other type containing its fields).
+func (r Record) ToDB13() (v DB13Record, err error) {
+	err = r.decode(_DB13Record_fields[:], func(i int, dt []byte) {
+		switch i {
+		case 0:
+			v.CountryCode = string(dt)
+		case 1:
+			v.CountryName = string(dt)
+		case 2:
+			v.Region = string(dt)
+		case 3:
+			v.City = string(dt)
+		case 4:
+			v.Latitude = as_f32(as_le_u32(dt))
+		case 5:
+			v.Longitude = as_f32(as_le_u32(dt))
+		case 6:
+			v.Timezone = string(dt)
+		case 7:
+			v.NetSpeed = string(dt)
+		}
+	})
+	return
+}
+
+// DB14Record contains the fields of IP2Location DB14 databases.
+type DB14Record struct {
+	CountryCode string
+	CountryName string
+	Region      string
+	City        string
+	Latitude    float32
+	Longitude   float32
+	Zipcode     string
+	Timezone    string
+	ISP         string
+	Domain      string
+	NetSpeed    string
+}
+
+var _DB14Record_fields = [...]DBField{CountryCode, CountryName, Region, City, Latitude, Longitude, Zipcode, Timezone, ISP, Domain, NetSpeed}
+
+// ToDB14 gets the fields of a [DB14Record] from r, which must contain all of
+// them (i.e., be from a DB14 database or any other type containing its fields).
+func (r Record) ToDB14() (v DB14Record, err error) {
+	err = r.decode(_DB14Record_fields[:], func(i int, dt []byte) {
+		switch i {
+		case 0:
+			v.CountryCode = string(dt)
+		case 1:
+			v.CountryName = string(dt)
+		case 2:
+			v.Region = string(dt)
+		case 3:
+			v.City = string(dt)
+		case 4:
+			v.Latitude = as_f32(as_le_u32(dt))
+		case 5:
+			v.Longitude = as_f32(as_le_u32(dt))
+		case 6:
+			v.Zipcode = string(dt)
+		case 7:
+			v.Timezone = string(dt)
+		case 8:
+			v.ISP = string(dt)
+		case 9:
+			v.Domain = string(dt)
+		case 10:
+			v.NetSpeed = string(dt)
+		}
+	})
+	return
+}
+
+// DB15Record contains the fields of IP2Location DB15 databases.
+type DB15Record struct {
+	CountryCode string
+	CountryName string
+	Region      string
+	City        string
+	Latitude    float32
+	Longitude   float32
+	Zipcode     string
+	Timezone    string
+	IDDCode     string
+	AreaCode    string
+}
+
+var _DB15Record_fields = [...]DBField{CountryCode, CountryName, Region, City, Latitude, Longitude, Zipcode, Timezone, IDDCode, AreaCode}
+
+// ToDB15 gets the fields of a [DB15Record] from r, which must contain all of
+// them (i.e., be from a DB15 database or any other type containing its fields).
+func (r Record) ToDB15() (v DB15Record, err error) {
+	err = r.decode(_DB15Record_fields[:], func(i int, dt []byte) {
+		switch i {
+		case 0:
+			v.CountryCode = string(dt)
+		case 1:
+			v.CountryName = string(dt)
+		case 2:
+			v.Region = string(dt)
+		case 3:
+			v.City = string(dt)
+		case 4:
+			v.Latitude = as_f32(as_le_u32(dt))
+		case 5:
+			v.Longitude = as_f32(as_le_u32(dt))
+		case 6:
+			v.Zipcode = string(dt)
+		case 7:
+			v.Timezone = string(dt)
+		case 8:
+			v.IDDCode = string(dt)
+		case 9:
+			v.AreaCode = string(dt)
+		}
+	})
+	return
+}
+
+// DB16Record contains the fields of IP2Location DB16 databases.
+type DB16Record struct {
+	CountryCode string
+	CountryName string
+	Region      string
+	City        string
+	Latitude    float32
+	Longitude   float32
+	Zipcode     string
+	Timezone    string
+	ISP         string
+	Domain      string
+	NetSpeed    string
+	IDDCode     string
+	AreaCode    string
+}
+
+var _DB16Record_fields = [...]DBField{CountryCode, CountryName, Region, City, Latitude, Longitude, Zipcode, Timezone, ISP, Domain, NetSpeed, IDDCode, AreaCode}
+
+// ToDB16 gets the fields of a [DB16Record] from r, which must contain all of
+// them (i.e., be from a DB16 database or any other type containing its fields).
+func (r Record) ToDB16() (v DB16Record, err error) {
+	err = r.decode(_DB16Record_fields[:], func(i int, dt []byte) {
+		switch i {
+		case 0:
+			v.CountryCode = string(dt)
+		case 1:
+			v.CountryName = string(dt)
+		case 2:
+			v.Region = string(dt)
+		case 3:
+			v.City = string(dt)
+		case 4:
+			v.Latitude = as_f32(as_le_u32(dt))
+		case 5:
+			v.Longitude = as_f32(as_le_u32(dt))
+		case 6:
+			v.Zipcode = string(dt)
+		case 7:
+			v.Timezone = string(dt)
+		case 8:
+			v.ISP = string(dt)
+		case 9:
+			v.Domain = string(dt)
+		case 10:
+			v.NetSpeed = string(dt)
+		case 11:
+			v.IDDCode = string(dt)
+		case 12:
+			v.AreaCode = string(dt)
+		}
+	})
+	return
+}
+
+// DB17Record contains the fields of IP2Location DB17 databases.
+type DB17Record struct {
+	CountryCode        string
+	CountryName        string
+	Region             string
+	City               string
+	Latitude           float32
+	Longitude          float32
+	Timezone           string
+	NetSpeed           string
+	WeatherStationCode string
+	WeatherStationName string
+}
+
+var _DB17Record_fields = [...]DBField{CountryCode, CountryName, Region, City, Latitude, Longitude, Timezone, NetSpeed, WeatherStationCode, WeatherStationName}
+
+// ToDB17 gets the fields of a [DB17Record] from r, which must contain all of
+// them (i.e., be from a DB17 database or any other type containing its fields).
+func (r Record) ToDB17() (v DB17Record, err error) {
+	err = r.decode(_DB17Record_fields[:], func(i int, dt []byte) {
+		switch i {
+		case 0:
+			v.CountryCode = string(dt)
+		case 1:
+			v.CountryName = string(dt)
+		case 2:
+			v.Region = string(dt)
+		case 3:
+			v.City = string(dt)
+		case 4:
+			v.Latitude = as_f32(as_le_u32(dt))
+		case 5:
+			v.Longitude = as_f32(as_le_u32(dt))
+		case 6:
+			v.Timezone = string(dt)
+		case 7:
+			v.NetSpeed = string(dt)
+		case 8:
+			v.WeatherStationCode = string(dt)
+		case 9:
+			v.WeatherStationName = string(dt)
+		}
+	})
+	return
+}
+
+// DB18Record contains the fields of IP2Location DB18 databases.
+type DB18Record struct {
+	CountryCode        string
+	CountryName        string
+	Region             string
+	City               string
+	Latitude           float32
+	Longitude          float32
+	Zipcode            string
+	Timezone           string
+	ISP                string
+	Domain             string
+	NetSpeed           string
+	IDDCode            string
+	AreaCode           string
+	WeatherStationCode string
+	WeatherStationName string
+}
+
+var _DB18Record_fields = [...]DBField{CountryCode, CountryName, Region, City, Latitude, Longitude, Zipcode, Timezone, ISP, Domain, NetSpeed, IDDCode, AreaCode, WeatherStationCode, WeatherStationName}
+
+// ToDB18 gets the fields of a [DB18Record] from r, which must contain all of
+// them (i.e., be from a DB18 database or any other type containing its fields).
+func (r Record) ToDB18() (v DB18Record, err error) {
+	err = r.decode(_DB18Record_fields[:], func(i int, dt []byte) {
+		switch i {
+		case 0:
+			v.CountryCode = string(dt)
+		case 1:
+			v.CountryName = string(dt)
+		case 2:
+			v.Region = string(dt)
+		case 3:
+			v.City = string(dt)
+		case 4:
+			v.Latitude = as_f32(as_le_u32(dt))
+		case 5:
+			v.Longitude = as_f32(as_le_u32(dt))
+		case 6:
+			v.Zipcode = string(dt)
+		case 7:
+			v.Timezone = string(dt)
+		case 8:
+			v.ISP = string(dt)
+		case 9:
+			v.Domain = string(dt)
+		case 10:
+			v.NetSpeed = string(dt)
+		case 11:
+			v.IDDCode = string(dt)
+		case 12:
+			v.AreaCode = string(dt)
+		case 13:
+			v.WeatherStationCode = string(dt)
+		case 14:
+			v.WeatherStationName = string(dt)
+		}
+	})
+	return
+}
+
+// DB19Record contains the fields of IP2Location DB19 databases.
+type DB19Record struct {
+	CountryCode string
+	CountryName string
+	Region      string
+	City        string
+	Latitude    float32
+	Longitude   float32
+	ISP         string
+	Domain      string
+	MCC         string
+	MNC         string
+	MobileBrand string
+}
+
+var _DB19Record_fields = [...]DBField{CountryCode, CountryName, Region, City, Latitude, Longitude, ISP, Domain, MCC, MNC, MobileBrand}
+
+// ToDB19 gets the fields of a [DB19Record] from r, which must contain all of
+// them (i.e., be from a DB19 database or any other type containing its fields).
+func (r Record) ToDB19() (v DB19Record, err error) {
+	err = r.decode(_DB19Record_fields[:], func(i int, dt []byte) {
+		switch i {
+		case 0:
+			v.CountryCode = string(dt)
+		case 1:
+			v.CountryName = string(dt)
+		case 2:
+			v.Region = string(dt)
+		case 3:
+			v.City = string(dt)
+		case 4:
+			v.Latitude = as_f32(as_le_u32(dt))
+		case 5:
+			v.Longitude = as_f32(as_le_u32(dt))
+		case 6:
+			v.ISP = string(dt)
+		case 7:
+			v.Domain = string(dt)
+		case 8:
+			v.MCC = string(dt)
+		case 9:
+			v.MNC = string(dt)
+		case 10:
+			v.MobileBrand = string(dt)
+		}
+	})
+	return
+}
+
+// DB20Record contains the fields of IP2Location DB20 databases.
+type DB20Record struct {
+	CountryCode        string
+	CountryName        string
+	Region             string
+	City               string
+	Latitude           float32
+	Longitude          float32
+	Zipcode            string
+	Timezone           string
+	ISP                string
+	Domain             string
+	NetSpeed           string
+	IDDCode            string
+	AreaCode           string
+	WeatherStationCode string
+	WeatherStationName string
+	MCC                string
+	MNC                string
+	MobileBrand        string
+}
+
+var _DB20Record_fields = [...]DBField{CountryCode, CountryName, Region, City, Latitude, Longitude, Zipcode, Timezone, ISP, Domain, NetSpeed, IDDCode, AreaCode, WeatherStationCode, WeatherStationName, MCC, MNC, MobileBrand}
+
+// ToDB20 gets the fields of a [DB20Record] from r, which must contain all of
+// them (i.e., be from a DB20 database or any other type containing its fields).
+func (r Record) ToDB20() (v DB20Record, err error) {
+	err = r.decode(_DB20Record_fields[:], func(i int, dt []byte) {
+		switch i {
+		case 0:
+			v.CountryCode = string(dt)
+		case 1:
+			v.CountryName = string(dt)
+		case 2:
+			v.Region = string(dt)
+		case 3:
+			v.City = string(dt)
+		case 4:
+			v.Latitude = as_f32(as_le_u32(dt))
+		case 5:
+			v.Longitude = as_f32(as_le_u32(dt))
+		case 6:
+			v.Zipcode = string(dt)
+		case 7:
+			v.Timezone = string(dt)
+		case 8:
+			v.ISP = string(dt)
+		case 9:
+			v.Domain = string(dt)
+		case 10:
+			v.NetSpeed = string(dt)
+		case 11:
+			v.IDDCode = string(dt)
+		case 12:
+			v.AreaCode = string(dt)
+		case 13:
+			v.WeatherStationCode = string(dt)
+		case 14:
+			v.WeatherStationName = string(dt)
+		case 15:
+			v.MCC = string(dt)
+		case 16:
+			v.MNC = string(dt)
+		case 17:
+			v.MobileBrand = string(dt)
+		}
+	})
+	return
+}
+
+// DB21Record contains the fields of IP2Location DB21 databases.
+type DB21Record struct {
+	CountryCode string
+	CountryName string
+	Region      string
+	City        string
+	Latitude    float32
+	Longitude   float32
+	Zipcode     string
+	Timezone    string
+	IDDCode     string
+	AreaCode    string
+	Elevation   string
+}
+
+var _DB21Record_fields = [...]DBField{CountryCode, CountryName, Region, City, Latitude, Longitude, Zipcode, Timezone, IDDCode, AreaCode, Elevation}
+
+// ToDB21 gets the fields of a [DB21Record] from r, which must contain all of
+// them (i.e., be from a DB21 database or any other type containing its fields).
+func (r Record) ToDB21() (v DB21Record, err error) {
+	err = r.decode(_DB21Record_fields[:], func(i int, dt []byte) {
+		switch i {
+		case 0:
+			v.CountryCode = string(dt)
+		case 1:
+			v.CountryName = string(dt)
+		case 2:
+			v.Region = string(dt)
+		case 3:
+			v.City = string(dt)
+		case 4:
+			v.Latitude = as_f32(as_le_u32(dt))
+		case 5:
+			v.Longitude = as_f32(as_le_u32(dt))
+		case 6:
+			v.Zipcode = string(dt)
+		case 7:
+			v.Timezone = string(dt)
+		case 8:
+			v.IDDCode = string(dt)
+		case 9:
+			v.AreaCode = string(dt)
+		case 10:
+			v.Elevation = string(dt)
+		}
+	})
+	return
+}
+
+// DB22Record contains the fields of IP2Location DB22 databases.
+type DB22Record struct {
+	CountryCode        string
+	CountryName        string
+	Region             string
+	City               string
+	Latitude           float32
+	Longitude          float32
+	Zipcode            string
+	Timezone           string
+	ISP                string
+	Domain             string
+	NetSpeed           string
+	IDDCode            string
+	AreaCode           string
+	WeatherStationCode string
+	WeatherStationName string
+	MCC                string
+	MNC                string
+	MobileBrand        string
+	Elevation          string
+}
+
+var _DB22Record_fields = [...]DBField{CountryCode, CountryName, Region, City, Latitude, Longitude, Zipcode, Timezone, ISP, Domain, NetSpeed, IDDCode, AreaCode, WeatherStationCode, WeatherStationName, MCC, MNC, MobileBrand, Elevation}
+
+// ToDB22 gets the fields of a [DB22Record] from r, which must contain all of
+// them (i.e., be from a DB22 database or any other type containing its fields).
+func (r Record) ToDB22() (v DB22Record, err error) {
+	err = r.decode(_DB22Record_fields[:], func(i int, dt []byte) {
+		switch i {
+		case 0:
+			v.CountryCode = string(dt)
+		case 1:
+			v.CountryName = string(dt)
+		case 2:
+			v.Region = string(dt)
+		case 3:
+			v.City = string(dt)
+		case 4:
+			v.Latitude = as_f32(as_le_u32(dt))
+		case 5:
+			v.Longitude = as_f32(as_le_u32(dt))
+		case 6:
+			v.Zipcode = string(dt)
+		case 7:
+			v.Timezone = string(dt)
+		case 8:
+			v.ISP = string(dt)
+		case 9:
+			v.Domain = string(dt)
+		case 10:
+			v.NetSpeed = string(dt)
+		case 11:
+			v.IDDCode = string(dt)
+		case 12:
+			v.AreaCode = string(dt)
+		case 13:
+			v.WeatherStationCode = string(dt)
+		case 14:
+			v.WeatherStationName = string(dt)
+		case 15:
+			v.MCC = string(dt)
+		case 16:
+			v.MNC = string(dt)
+		case 17:
+			v.MobileBrand = string(dt)
+		case 18:
+			v.Elevation = string(dt)
+		}
+	})
+	return
+}
+
+// DB23Record contains the fields of IP2Location DB23 databases.
+type DB23Record struct {
+	CountryCode string
+	CountryName string
+	Region      string
+	City        string
+	Latitude    float32
+	Longitude   float32
+	ISP         string
+	Domain      string
+	MCC         string
+	MNC         string
+	MobileBrand string
+	UsageType   string
+}
+
+var _DB23Record_fields = [...]DBField{CountryCode, CountryName, Region, City, Latitude, Longitude, ISP, Domain, MCC, MNC, MobileBrand, UsageType}
+
+// ToDB23 gets the fields of a [DB23Record] from r, which must contain all of
+// them (i.e., be from a DB23 database or any other type containing its fields).
+func (r Record) ToDB23() (v DB23Record, err error) {
+	err = r.decode(_DB23Record_fields[:], func(i int, dt []byte) {
+		switch i {
+		case 0:
+			v.CountryCode = string(dt)
+		case 1:
+			v.CountryName = string(dt)
+		case 2:
+			v.Region = string(dt)
+		case 3:
+			v.City = string(dt)
+		case 4:
+			v.Latitude = as_f32(as_le_u32(dt))
+		case 5:
+			v.Longitude = as_f32(as_le_u32(dt))
+		case 6:
+			v.ISP = string(dt)
+		case 7:
+			v.Domain = string(dt)
+		case 8:
+			v.MCC = string(dt)
+		case 9:
+			v.MNC = string(dt)
+		case 10:
+			v.MobileBrand = string(dt)
+		case 11:
+			v.UsageType = string(dt)
+		}
+	})
+	return
+}
+
+// DB24Record contains the fields of IP2Location DB24 databases.
+type DB24Record struct {
+	CountryCode        string
+	CountryName        string
+	Region             string
+	City               string
+	Latitude           float32
+	Longitude          float32
+	Zipcode            string
+	Timezone           string
+	ISP                string
+	Domain             string
+	NetSpeed           string
+	IDDCode            string
+	AreaCode           string
+	WeatherStationCode string
+	WeatherStationName string
+	MCC                string
+	MNC                string
+	MobileBrand        string
+	Elevation          string
+	UsageType          string
+}
+
+var _DB24Record_fields = [...]DBField{CountryCode, CountryName, Region, City, Latitude, Longitude, Zipcode, Timezone, ISP, Domain, NetSpeed, IDDCode, AreaCode, WeatherStationCode, WeatherStationName, MCC, MNC, MobileBrand, Elevation, UsageType}
+
+// ToDB24 gets the fields of a [DB24Record] from r, which must contain all of
+// them (i.e., be from a DB24 database or any other type containing its fields).
+func (r Record) ToDB24() (v DB24Record, err error) {
+	err = r.decode(_DB24Record_fields[:], func(i int, dt []byte) {
+		switch i {
+		case 0:
+			v.CountryCode = string(dt)
+		case 1:
+			v.CountryName = string(dt)
+		case 2:
+			v.Region = string(dt)
+		case 3:
+			v.City = string(dt)
+		case 4:
+			v.Latitude = as_f32(as_le_u32(dt))
+		case 5:
+			v.Longitude = as_f32(as_le_u32(dt))
+		case 6:
+			v.Zipcode = string(dt)
+		case 7:
+			v.Timezone = string(dt)
+		case 8:
+			v.ISP = string(dt)
+		case 9:
+			v.Domain = string(dt)
+		case 10:
+			v.NetSpeed = string(dt)
+		case 11:
+			v.IDDCode = string(dt)
+		case 12:
+			v.AreaCode = string(dt)
+		case 13:
+			v.WeatherStationCode = string(dt)
+		case 14:
+			v.WeatherStationName = string(dt)
+		case 15:
+			v.MCC = string(dt)
+		case 16:
+			v.MNC = string(dt)
+		case 17:
+			v.MobileBrand = string(dt)
+		case 18:
+			v.Elevation = string(dt)
+		case 19:
+			v.UsageType = string(dt)
+		}
+	})
+	return
+}
+
+// DB25Record contains the fields of IP2Location DB25 databases.
+type DB25Record struct {
+	CountryCode        string
+	CountryName        string
+	Region             string
+	City               string
+	Latitude           float32
+	Longitude          float32
+	Zipcode            string
+	Timezone           string
+	ISP                string
+	Domain             string
+	NetSpeed           string
+	IDDCode            string
+	AreaCode           string
+	WeatherStationCode string
+	WeatherStationName string
+	MCC                string
+	MNC                string
+	MobileBrand        string
+	Elevation          string
+	UsageType          string
+	AddressType        string
+	Category           string
+}
+
+var _DB25Record_fields = [...]DBField{CountryCode, CountryName, Region, City, Latitude, Longitude, Zipcode, Timezone, ISP, Domain, NetSpeed, IDDCode, AreaCode, WeatherStationCode, WeatherStationName, MCC, MNC, MobileBrand, Elevation, UsageType, AddressType, Category}
+
+// ToDB25 gets the fields of a [DB25Record] from r, which must contain all of
+// them (i.e., be from a DB25 database or any other type containing its fields).
+func (r Record) ToDB25() (v DB25Record, err error) {
+	err = r.decode(_DB25Record_fields[:], func(i int, dt []byte) {
+		switch i {
+		case 0:
+			v.CountryCode = string(dt)
+		case 1:
+			v.CountryName = string(dt)
+		case 2:
+			v.Region = string(dt)
+		case 3:
+			v.City = string(dt)
+		case 4:
+			v.Latitude = as_f32(as_le_u32(dt))
+		case 5:
+			v.Longitude = as_f32(as_le_u32(dt))
+		case 6:
+			v.Zipcode = string(dt)
+		case 7:
+			v.Timezone = string(dt)
+		case 8:
+			v.ISP = string(dt)
+		case 9:
+			v.Domain = string(dt)
+		case 10:
+			v.NetSpeed = string(dt)
+		case 11:
+			v.IDDCode = string(dt)
+		case 12:
+			v.AreaCode = string(dt)
+		case 13:
+			v.WeatherStationCode = string(dt)
+		case 14:
+			v.WeatherStationName = string(dt)
+		case 15:
+			v.MCC = string(dt)
+		case 16:
+			v.MNC = string(dt)
+		case 17:
+			v.MobileBrand = string(dt)
+		case 18:
+			v.Elevation = string(dt)
+		case 19:
+			v.UsageType = string(dt)
+		case 20:
+			v.AddressType = string(dt)
+		case 21:
+			v.Category = string(dt)
+		}
+	})
+	return
+}
+
+// DB26Record contains the fields of IP2Location DB26 databases.
+type DB26Record struct {
+	CountryCode        string
+	CountryName        string
+	Region             string
+	City               string
+	Latitude           float32
+	Longitude          float32
+	Zipcode            string
+	Timezone           string
+	ISP                string
+	Domain             string
+	NetSpeed           string
+	IDDCode            string
+	AreaCode           string
+	WeatherStationCode string
+	WeatherStationName string
+	MCC                string
+	MNC                string
+	MobileBrand        string
+	Elevation          string
+	UsageType          string
+	AddressType        string
+	Category           string
+	District           string
+	ASN                string
+	AS                 string
+	ASDomain           string
+	ASUsageType        string
+	ASRange            string
+}
+
+var _DB26Record_fields = [...]DBField{CountryCode, CountryName, Region, City, Latitude, Longitude, Zipcode, Timezone, ISP, Domain, NetSpeed, IDDCode, AreaCode, WeatherStationCode, WeatherStationName, MCC, MNC, MobileBrand, Elevation, UsageType, AddressType, Category, District, ASN, AS, ASDomain, ASUsageType, ASRange}
+
+// ToDB26 gets the fields of a [DB26Record] from r, which must contain all of
+// them (i.e., be from a DB26 database or any other type containing its fields).
+func (r Record) ToDB26() (v DB26Record, err error) {
+	err = r.decode(_DB26Record_fields[:], func(i int, dt []byte) {
+		switch i {
+		case 0:
+			v.CountryCode = string(dt)
+		case 1:
+			v.CountryName = string(dt)
+		case 2:
+			v.Region = string(dt)
+		case 3:
+			v.City = string(dt)
+		case 4:
+			v.Latitude = as_f32(as_le_u32(dt))
+		case 5:
+			v.Longitude = as_f32(as_le_u32(dt))
+		case 6:
+			v.Zipcode = string(dt)
+		case 7:
+			v.Timezone = string(dt)
+		case 8:
+			v.ISP = string(dt)
+		case 9:
+			v.Domain = string(dt)
+		case 10:
+			v.NetSpeed = string(dt)
+		case 11:
+			v.IDDCode = string(dt)
+		case 12:
+			v.AreaCode = string(dt)
+		case 13:
+			v.WeatherStationCode = string(dt)
+		case 14:
+			v.WeatherStationName = string(dt)
+		case 15:
+			v.MCC = string(dt)
+		case 16:
+			v.MNC = string(dt)
+		case 17:
+			v.MobileBrand = string(dt)
+		case 18:
+			v.Elevation = string(dt)
+		case 19:
+			v.UsageType = string(dt)
+		case 20:
+			v.AddressType = string(dt)
+		case 21:
+			v.Category = string(dt)
+		case 22:
+			v.District = string(dt)
+		case 23:
+			v.ASN = string(dt)
+		case 24:
+			v.AS = string(dt)
+		case 25:
+			v.ASDomain = string(dt)
+		case 26:
+			v.ASUsageType = string(dt)
+		case 27:
+			v.ASRange = string(dt)
+		}
+	})
+	return
+}
+
+// PX1Record contains the fields of IP2Proxy PX1 databases.
+type PX1Record struct {
+	CountryCode string
+	CountryName string
+}
+
+var _PX1Record_fields = [...]DBField{CountryCode, CountryName}
+
+// ToPX1 gets the fields of a [PX1Record] from r, which must contain all of
+// them (i.e., be from a PX1 database or any other type containing its fields).
+func (r Record) ToPX1() (v PX1Record, err error) {
+	err = r.decode(_PX1Record_fields[:], func(i int, dt []byte) {
+		switch i {
+		case 0:
+			v.CountryCode = string(dt)
+		case 1:
+			v.CountryName = string(dt)
+		}
+	})
+	return
+}
+
+// PX2Record contains the fields of IP2Proxy PX2 databases.
+type PX2Record struct {
+	CountryCode string
+	CountryName string
+	ProxyType   string
+}
+
+var _PX2Record_fields = [...]DBField{CountryCode, CountryName, ProxyType}
+
+// ToPX2 gets the fields of a [PX2Record] from r, which must contain all of
+// them (i.e., be from a PX2 database or any other type containing its fields).
+func (r Record) ToPX2() (v PX2Record, err error) {
+	err = r.decode(_PX2Record_fields[:], func(i int, dt []byte) {
+		switch i {
+		case 0:
+			v.CountryCode = string(dt)
+		case 1:
+			v.CountryName = string(dt)
+		case 2:
+			v.ProxyType = string(dt)
+		}
+	})
+	return
+}
+
+// PX3Record contains the fields of IP2Proxy PX3 databases.
+type PX3Record struct {
+	CountryCode string
+	CountryName string
+	ProxyType   string
+	Region      string
+	City        string
+}
+
+var _PX3Record_fields = [...]DBField{CountryCode, CountryName, ProxyType, Region, City}
+
+// ToPX3 gets the fields of a [PX3Record] from r, which must contain all of
+// them (i.e., be from a PX3 database or any other type containing its fields).
+func (r Record) ToPX3() (v PX3Record, err error) {
+	err = r.decode(_PX3Record_fields[:], func(i int, dt []byte) {
+		switch i {
+		case 0:
+			v.CountryCode = string(dt)
+		case 1:
+			v.CountryName = string(dt)
+		case 2:
+			v.ProxyType = string(dt)
+		case 3:
+			v.Region = string(dt)
+		case 4:
+			v.City = string(dt)
+		}
+	})
+	return
+}
+
+// PX4Record contains the fields of IP2Proxy PX4 databases.
+type PX4Record struct {
+	CountryCode string
+	CountryName string
+	ProxyType   string
+	Region      string
+	City        string
+	ISP         string
+}
+
+var _PX4Record_fields = [...]DBField{CountryCode, CountryName, ProxyType, Region, City, ISP}
+
+// ToPX4 gets the fields of a [PX4Record] from r, which must contain all of
+// them (i.e., be from a PX4 database or any other type containing its fields).
+func (r Record) ToPX4() (v PX4Record, err error) {
+	err = r.decode(_PX4Record_fields[:], func(i int, dt []byte) {
+		switch i {
+		case 0:
+			v.CountryCode = string(dt)
+		case 1:
+			v.CountryName = string(dt)
+		case 2:
+			v.ProxyType = string(dt)
+		case 3:
+			v.Region = string(dt)
+		case 4:
+			v.City = string(dt)
+		case 5:
+			v.ISP = string(dt)
+		}
+	})
+	return
+}
+
+// PX5Record contains the fields of IP2Proxy PX5 databases.
+type PX5Record struct {
+	CountryCode string
+	CountryName string
+	ProxyType   string
+	Region      string
+	City        string
+	ISP         string
+	Domain      string
+}
+
+var _PX5Record_fields = [...]DBField{CountryCode, CountryName, ProxyType, Region, City, ISP, Domain}
+
+// ToPX5 gets the fields of a [PX5Record] from r, which must contain all of
+// them (i.e., be from a PX5 database or any other type containing its fields).
+func (r Record) ToPX5() (v PX5Record, err error) {
+	err = r.decode(_PX5Record_fields[:], func(i int, dt []byte) {
+		switch i {
+		case 0:
+			v.CountryCode = string(dt)
+		case 1:
+			v.CountryName = string(dt)
+		case 2:
+			v.ProxyType = string(dt)
+		case 3:
+			v.Region = string(dt)
+		case 4:
+			v.City = string(dt)
+		case 5:
+			v.ISP = string(dt)
+		case 6:
+			v.Domain = string(dt)
+		}
+	})
+	return
+}
+
+// PX6Record contains the fields of IP2Proxy PX6 databases.
+type PX6Record struct {
+	CountryCode string
+	CountryName string
+	ProxyType   string
+	Region      string
+	City        string
+	ISP         string
+	Domain      string
+	UsageType   string
+}
+
+var _PX6Record_fields = [...]DBField{CountryCode, CountryName, ProxyType, Region, City, ISP, Domain, UsageType}
+
+// ToPX6 gets the fields of a [PX6Record] from r, which must contain all of
+// them (i.e., be from a PX6 database or any other type containing its fields).
+func (r Record) ToPX6() (v PX6Record, err error) {
+	err = r.decode(_PX6Record_fields[:], func(i int, dt []byte) {
+		switch i {
+		case 0:
+			v.CountryCode = string(dt)
+		case 1:
+			v.CountryName = string(dt)
+		case 2:
+			v.ProxyType = string(dt)
+		case 3:
+			v.Region = string(dt)
+		case 4:
+			v.City = string(dt)
+		case 5:
+			v.ISP = string(dt)
+		case 6:
+			v.Domain = string(dt)
+		case 7:
+			v.UsageType = string(dt)
+		}
+	})
+	return
+}
+
+// PX7Record contains the fields of IP2Proxy PX7 databases.
+type PX7Record struct {
+	CountryCode string
+	CountryName string
+	ProxyType   string
+	Region      string
+	City        string
+	ISP         string
+	Domain      string
+	UsageType   string
+	ASN         string
+	AS          string
+}
+
+var _PX7Record_fields = [...]DBField{CountryCode, CountryName, ProxyType, Region, City, ISP, Domain, UsageType, ASN, AS}
+
+// ToPX7 gets the fields of a [PX7Record] from r, which must contain all of
+// them (i.e., be from a PX7 database or any other type containing its fields).
+func (r Record) ToPX7() (v PX7Record, err error) {
+	err = r.decode(_PX7Record_fields[:], func(i int, dt []byte) {
+		switch i {
+		case 0:
+			v.CountryCode = string(dt)
+		case 1:
+			v.CountryName = string(dt)
+		case 2:
+			v.ProxyType = string(dt)
+		case 3:
+			v.Region = string(dt)
+		case 4:
+			v.City = string(dt)
+		case 5:
+			v.ISP = string(dt)
+		case 6:
+			v.Domain = string(dt)
+		case 7:
+			v.UsageType = string(dt)
+		case 8:
+			v.ASN = string(dt)
+		case 9:
+			v.AS = string(dt)
+		}
+	})
+	return
+}
+
+// PX8Record contains the fields of IP2Proxy PX8 databases.
+type PX8Record struct {
+	CountryCode string
+	CountryName string
+	ProxyType   string
+	Region      string
+	City        string
+	ISP         string
+	Domain      string
+	UsageType   string
+	ASN         string
+	AS          string
+	LastSeen    string
+}
+
+var _PX8Record_fields = [...]DBField{CountryCode, CountryName, ProxyType, Region, City, ISP, Domain, UsageType, ASN, AS, LastSeen}
+
+// ToPX8 gets the fields of a [PX8Record] from r, which must contain all of
+// them (i.e., be from a PX8 database or any other type containing its fields).
+func (r Record) ToPX8() (v PX8Record, err error) {
+	err = r.decode(_PX8Record_fields[:], func(i int, dt []byte) {
+		switch i {
+		case 0:
+			v.CountryCode = string(dt)
+		case 1:
+			v.CountryName = string(dt)
+		case 2:
+			v.ProxyType = string(dt)
+		case 3:
+			v.Region = string(dt)
+		case 4:
+			v.City = string(dt)
+		case 5:
+			v.ISP = string(dt)
+		case 6:
+			v.Domain = string(dt)
+		case 7:
+			v.UsageType = string(dt)
+		case 8:
+			v.ASN = string(dt)
+		case 9:
+			v.AS = string(dt)
+		case 10:
+			v.LastSeen = string(dt)
+		}
+	})
+	return
+}
+
+// PX9Record contains the fields of IP2Proxy PX9 databases.
+type PX9Record struct {
+	CountryCode string
+	CountryName string
+	ProxyType   string
+	Region      string
+	City        string
+	ISP         string
+	Domain      string
+	UsageType   string
+	ASN         string
+	AS          string
+	LastSeen    string
+	Threat      string
+}
+
+var _PX9Record_fields = [...]DBField{CountryCode, CountryName, ProxyType, Region, City, ISP, Domain, UsageType, ASN, AS, LastSeen, Threat}
+
+// ToPX9 gets the fields of a [PX9Record] from r, which must contain all of
+// them (i.e., be from a PX9 database or any other type containing its fields).
+func (r Record) ToPX9() (v PX9Record, err error) {
+	err = r.decode(_PX9Record_fields[:], func(i int, dt []byte) {
+		switch i {
+		case 0:
+			v.CountryCode = string(dt)
+		case 1:
+			v.CountryName = string(dt)
+		case 2:
+			v.ProxyType = string(dt)
+		case 3:
+			v.Region = string(dt)
+		case 4:
+			v.City = string(dt)
+		case 5:
+			v.ISP = string(dt)
+		case 6:
+			v.Domain = string(dt)
+		case 7:
+			v.UsageType = string(dt)
+		case 8:
+			v.ASN = string(dt)
+		case 9:
+			v.AS = string(dt)
+		case 10:
+			v.LastSeen = string(dt)
+		case 11:
+			v.Threat = string(dt)
+		}
+	})
+	return
+}
+
+// PX10Record contains the fields of IP2Proxy PX10 databases.
+type PX10Record struct {
+	CountryCode string
+	CountryName string
+	ProxyType   string
+	Region      string
+	City        string
+	ISP         string
+	Domain      string
+	UsageType   string
+	ASN         string
+	AS          string
+	LastSeen    string
+	Threat      string
+}
+
+var _PX10Record_fields = [...]DBField{CountryCode, CountryName, ProxyType, Region, City, ISP, Domain, UsageType, ASN, AS, LastSeen, Threat}
+
+// ToPX10 gets the fields of a [PX10Record] from r, which must contain all of
+// them (i.e., be from a PX10 database or any other type containing its fields).
+func (r Record) ToPX10() (v PX10Record, err error) {
+	err = r.decode(_PX10Record_fields[:], func(i int, dt []byte) {
+		switch i {
+		case 0:
+			v.CountryCode = string(dt)
+		case 1:
+			v.CountryName = string(dt)
+		case 2:
+			v.ProxyType = string(dt)
+		case 3:
+			v.Region = string(dt)
+		case 4:
+			v.City = string(dt)
+		case 5:
+			v.ISP = string(dt)
+		case 6:
+			v.Domain = string(dt)
+		case 7:
+			v.UsageType = string(dt)
+		case 8:
+			v.ASN = string(dt)
+		case 9:
+			v.AS = string(dt)
+		case 10:
+			v.LastSeen = string(dt)
+		case 11:
+			v.Threat = string(dt)
+		}
+	})
+	return
+}
+
+// PX11Record contains the fields of IP2Proxy PX11 databases.
+type PX11Record struct {
+	CountryCode string
+	CountryName string
+	ProxyType   string
+	Region      string
+	City        string
+	ISP         string
+	Domain      string
+	UsageType   string
+	ASN         string
+	AS          string
+	LastSeen    string
+	Threat      string
+	Provider    string
+}
+
+var _PX11Record_fields = [...]DBField{CountryCode, CountryName, ProxyType, Region, City, ISP, Domain, UsageType, ASN, AS, LastSeen, Threat, Provider}
+
+// ToPX11 gets the fields of a [PX11Record] from r, which must contain all of
+// them (i.e., be from a PX11 database or any other type containing its fields).
+func (r Record) ToPX11() (v PX11Record, err error) {
+	err = r.decode(_PX11Record_fields[:], func(i int, dt []byte) {
+		switch i {
+		case 0:
+			v.CountryCode = string(dt)
+		case 1:
+			v.CountryName = string(dt)
+		case 2:
+			v.ProxyType = string(dt)
+		case 3:
+			v.Region = string(dt)
+		case 4:
+			v.City = string(dt)
+		case 5:
+			v.ISP = string(dt)
+		case 6:
+			v.Domain = string(dt)
+		case 7:
+			v.UsageType = string(dt)
+		case 8:
+			v.ASN = string(dt)
+		case 9:
+			v.AS = string(dt)
+		case 10:
+			v.LastSeen = string(dt)
+		case 11:
+			v.Threat = string(dt)
+		case 12:
+			v.Provider = string(dt)
+		}
+	})
+	return
+}
+
+// PX12Record contains the fields of IP2Proxy PX12 databases.
+type PX12Record struct {
+	CountryCode string
+	CountryName string
+	ProxyType   string
+	Region      string
+	City        string
+	ISP         string
+	Domain      string
+	UsageType   string
+	ASN         string
+	AS          string
+	LastSeen    string
+	Threat      string
+	Provider    string
+	FraudScore  string
+}
+
+var _PX12Record_fields = [...]DBField{CountryCode, CountryName, ProxyType, Region, City, ISP, Domain, UsageType, ASN, AS, LastSeen, Threat, Provider, FraudScore}
+
+// ToPX12 gets the fields of a [PX12Record] from r, which must contain all of
+// them (i.e., be from a PX12 database or any other type containing its fields).
+func (r Record) ToPX12() (v PX12Record, err error) {
+	err = r.decode(_PX12Record_fields[:], func(i int, dt []byte) {
+		switch i {
+		case 0:
+			v.CountryCode = string(dt)
+		case 1:
+			v.CountryName = string(dt)
+		case 2:
+			v.ProxyType = string(dt)
+		case 3:
+			v.Region = string(dt)
+		case 4:
+			v.City = string(dt)
+		case 5:
+			v.ISP = string(dt)
+		case 6:
+			v.Domain = string(dt)
+		case 7:
+			v.UsageType = string(dt)
+		case 8:
+			v.ASN = string(dt)
+		case 9:
+			v.AS = string(dt)
+		case 10:
+			v.LastSeen = string(dt)
+		case 11:
+			v.Threat = string(dt)
+		case 12:
+			v.Provider = string(dt)
+		case 13:
+			v.FraudScore = string(dt)
+		}
+	})
+	return
+}
+
 func (p DBProduct) GoString() string {
 	if o := int64(p)*2 - 2; o >= 0 && o < 3 {
-		return _stringer_kn2hcgdo[_stringer_DBProduct_GoString[o]:_stringer_DBProduct_GoString[o+1]]
+		return _stringer_s22rbchb[_stringer_DBProduct_GoString[o]:_stringer_DBProduct_GoString[o+1]]
 	}
 	return "DBProduct(" + strconv.FormatUint(uint64(p), 10) + ")"
 }
 
 func (p DBProduct) product() string {
 	if o := int64(p)*2 - 2; o >= 0 && o < 3 {
-		return _stringer_kn2hcgdo[_stringer_DBProduct_product[o]:_stringer_DBProduct_product[o+1]]
+		return _stringer_s22rbchb[_stringer_DBProduct_product[o]:_stringer_DBProduct_product[o+1]]
 	}
 	return ""
 }
 
 func (p DBProduct) prefix() string {
 	if o := int64(p)*2 - 2; o >= 0 && o < 3 {
-		return _stringer_kn2hcgdo[_stringer_DBProduct_prefix[o]:_stringer_DBProduct_prefix[o+1]]
+		return _stringer_s22rbchb[_stringer_DBProduct_prefix[o]:_stringer_DBProduct_prefix[o+1]]
 	}
 	return ""
 }
 
 func (f DBField) GoString() string {
 	if o := int64(f)*2 - 2; o >= 0 && o < 65 {
-		return _stringer_kn2hcgdo[_stringer_DBField_GoString[o]:_stringer_DBField_GoString[o+1]]
+		return _stringer_s22rbchb[_stringer_DBField_GoString[o]:_stringer_DBField_GoString[o+1]]
 	}
 	return "DBField(" + strconv.FormatUint(uint64(f), 10) + ")"
 }
 
 func (f DBField) column() string {
 	if o := int64(f)*2 - 2; o >= 0 && o < 65 {
-		return _stringer_kn2hcgdo[_stringer_DBField_column[o]:_stringer_DBField_column[o+1]]
+		return _stringer_s22rbchb[_stringer_DBField_column[o]:_stringer_DBField_column[o+1]]
+	}
+	return ""
+}
+
+func (f DBField) description() string {
+	if o := int64(f)*2 - 2; o >= 0 && o < 65 {
+		return _stringer_s22rbchb[_stringer_DBField_description[o]:_stringer_DBField_description[o+1]]
 	}
 	return ""
 }
 
-const _stringer_kn2hcgdo = "IP2LocationIP2ProxyDBPXAddressTypeAreaCodeASNCategoryCityCountryCodeCountryNameDomainDistrictElevationIDDCodeISPLastSeenLatitudeLongitudeMCCMNCMobileBrandNetSpeedProviderProxyTypeRegionThreatTimezoneUsageTypeWeatherStationCodeWeatherStationNameZipcodeFraudScoreASDomainASUsageTypeASRangeaddress_typearea_codeasncategorycitycountry_codecountry_namedomaindistrictelevationidd_codeisplast_seenlatitudelongitudemccmncmobile_brandnet_speedproviderproxy_typeregionthreattime_zoneusage_typeweather_station_codeweather_station_namezip_codefraud_scoreas_domainas_usage_typeas_cidr" // ratio 571 / 594 = 0.9
+const _stringer_s22rbchb = "IP2LocationIP2ProxyDBPXAddressTypeAreaCodeASNCategoryCity name.CountryCodeCountryNameDomain name of the AS registrant.District or county name.ElevationIDDCodeISPLastSeenLatitudeLongitudeMCCMNCMobileBrandNetSpeedProviderProxyTypeRegion or state name.ThreatTimezoneUsageTypeWeatherStationCodeWeatherStationNameZipcodeFraudScoreASDomainASUsageTypeASRangeaddress_typearea_codeasncategorycitycountry_codecountry_namedomaindistrictelevationidd_codeisplast_seenlatitudelongitudemccmncmobile_brandnet_speedproviderproxy_typeregionthreattime_zoneusage_typeweather_station_codeweather_station_namezip_codefraud_scoreas_domainas_usage_typeas_cidrIP address types as defined in Internet Protocol version 4 (IPv4) and\nInternet Protocol version 6 (IPv6).\n  - (A) Anycast - One to the closest\n  - (U) Unicast - One to one\n  - (M) Multicast - One to multiple\n  - (B) Broadcast - One to allA varying length number assigned to geographic areas for call between cities.\n\nSee https://www.ip2location.com/area-code-coverage.Autonomous system (AS) name.Autonomous system number (ASN).The domain category is based on IAB Tech Lab Content Taxonomy.\n\nThese categories are comprised of Tier-1 and Tier-2 (if available) level\ncategories widely used in services like advertising, Internet security and\nfiltering appliances.\n\nSee https://www.ip2location.com/free/iab-categories.Two-character country code based on ISO 3166.Country name based on ISO 3166.Internet domain name associated with IP address range.Average height of city above sea level in meters (m).The IDD prefix to call the city from another country.Internet Service Provider or company's name.Proxy last seen in days.City latitude. Defaults to capital city latitude if city is unknown.City longitude. Defaults to capital city longitude if city is unknown.Mobile Country Codes (MCC) as defined in ITU E.212 for use in identifying\nmobile stations in wireless telephone networks, particularly GSM and UMTS\nnetworks.Mobile Network Code (MNC) is used in combination with a Mobile Country Code\n(MCC) to uniquely identify a mobile phone operator or carrier.Commercial brand associated with the mobile carrier.\n\nSee https://www.ip2location.com/mobile-carrier-coverage.Internet Connection Type\n  - (DIAL) dial up\n  - (DSL) broadband/cable/fiber/mobile\n  - (COMP) company/T1Name of VPN provider if available.Type of proxy.\n  - (VPN) Anonymizing VPN services. These services offer users a publicly\n    accessible VPN for the purpose of hiding their IP address. Anonymity:\n    High.\n  - (TOR) Tor Exit Nodes. The Tor Project is an open network used by those\n    who wish to maintain anonymity. Anonymity: High.\n  - (DCH) Hosting Provider, Data Center or Content Delivery Network. Since\n    hosting providers and data centers can serve to provide anonymity, the\n    Anonymous IP database flags IP addresses associated with them. Anonymity:\n    Low.\n  - (PUB) Public Proxies. These are services which make connection requests\n    on a user's behalf. Proxy server software can be configured by the\n    administrator to listen on some specified port. These differ from VPNs in\n    that the proxies usually have limited functions compare to VPNs.\n    Anonymity: High.\n  - (WEB) Web Proxies. These are web services which make web requests on a\n    user's behalf. These differ from VPNs or Public Proxies in that they are\n    simple web-based proxies rather than operating at the IP address and\n    other ports level. Anonymity: High.\n  - (SES) Search Engine Robots. These are services which perform crawling or\n    scraping to a website, such as, the search engine spider or bots engine.\n    Anonymity: Low.\n  - (RES) Residential proxies. These services offer users proxy connections\n    through residential ISP with or without consents of peers to share their\n    idle resources. Only available with PX10 - PX12. Anonymity: Medium.\n  - (CPN) Consumer Privacy Networks. These services ensure encrypted traffic\n    from the user's browser by routing internet requests through relays,\n    concealing the IP address, location, and browsing activity. Only\n    available with PX11 & PX12. Anonymity: Low.\n  - (EPN) Enterprise Private Networks. Services like SASE or SD-WAN combine\n    network security functions with wide-area networking (WAN) capabilities\n    to meet the secure remote access needs of organizations. Only available\n    with PX11 & PX12. Anonymity: Low.Security threat reported.\n  - (SPAM) Email and forum spammers\n  - (SCANNER) Network security scanners\n  - (BOTNET) Malware infected devices\n  - (BOGON) Unassigned or illegitimate IP addresses announced via BGPUTC time zone (with DST supported).Usage type classification of ISP or company.\n  - (COM) Commercial\n  - (ORG) Organization\n  - (GOV) Government\n  - (MIL) Military\n  - (EDU) University/College/School\n  - (LIB) Library\n  - (CDN) Content Delivery Network\n  - (ISP) Fixed Line ISP\n  - (MOB) Mobile ISP\n  - (DCH) Data Center/Web Hosting/Transit\n  - (SES) Search Engine Spider\n  - (RSV) ReservedThe special code to identify the nearest weather observation station.The name of the nearest weather observation station.ZIP code or Postal code.\n\nSee https://www.ip2location.com/zip-code-coverage.Potential risk score (0 - 99) associated with IP address. A higher IP2Proxy\nFraud Score indicates a greater likelihood of fraudulent activity and a lower\nreputation.Usage type of the AS registrant.CIDR range for the whole AS." // ratio 5404 / 5451 = 0.9
 var _stringer_DBProduct_GoString = [...]int{0, 11, 11, 19}
 var _stringer_DBProduct_product = [...]int{0, 11, 11, 19}
 var _stringer_DBProduct_prefix = [...]int{19, 21, 21, 23}
-var _stringer_DBField_GoString = [...]int{23, 34, 34, 42, 42, 44, 42, 45, 45, 53, 53, 57, 57, 68, 68, 79, 79, 85, 85, 93, 93, 102, 102, 109, 109, 112, 112, 120, 120, 128, 128, 137, 137, 140, 140, 143, 143, 154, 154, 162, 162, 170, 170, 179, 179, 185, 185, 191, 191, 199, 199, 208, 208, 226, 226, 244, 244, 251, 251, 261, 261, 269, 269, 280, 280, 287}
-var _stringer_DBField_column = [...]int{287, 299, 299, 308, 308, 310, 308, 311, 311, 319, 319, 323, 323, 335, 335, 347, 347, 353, 353, 361, 361, 370, 370, 378, 378, 381, 381, 390, 390, 398, 398, 407, 407, 410, 410, 413, 413, 425, 425, 434, 434, 442, 442, 452, 452, 458, 458, 464, 464, 473, 473, 483, 483, 503, 503, 523, 523, 531, 531, 542, 542, 551, 551, 564, 564, 571}
+var _stringer_DBField_GoString = [...]int{23, 34, 34, 42, 42, 44, 42, 45, 45, 53, 53, 57, 63, 74, 74, 85, 85, 91, 118, 126, 142, 151, 151, 158, 158, 161, 161, 169, 169, 177, 177, 186, 186, 189, 189, 192, 192, 203, 203, 211, 211, 219, 219, 228, 228, 234, 249, 255, 255, 263, 263, 272, 272, 290, 290, 308, 308, 315, 315, 325, 325, 333, 333, 344, 344, 351}
+var _stringer_DBField_column = [...]int{351, 363, 363, 372, 372, 374, 372, 375, 375, 383, 383, 387, 387, 399, 399, 411, 411, 417, 417, 425, 425, 434, 434, 442, 442, 445, 445, 454, 454, 462, 462, 471, 471, 474, 474, 477, 477, 489, 489, 498, 498, 506, 506, 516, 516, 522, 522, 528, 528, 537, 537, 547, 547, 567, 567, 587, 587, 595, 595, 606, 606, 615, 615, 628, 628, 635}
+var _stringer_DBField_description = [...]int{635, 873, 873, 1003, 1003, 1031, 1031, 1062, 1062, 1349, 53, 63, 1349, 1394, 1394, 1425, 1425, 1479, 118, 142, 1479, 1532, 1532, 1585, 1585, 1629, 1629, 1653, 1653, 1721, 1721, 1791, 1791, 1948, 1948, 2086, 2086, 2196, 2196, 2300, 2300, 2334, 2334, 4383, 228, 249, 4383, 4592, 4592, 4627, 4627, 4982, 4982, 5051, 5051, 5103, 5103, 5179, 5179, 5344, 85, 118, 5344, 5376, 5376, 5404}
@@ -0,0 +1,340 @@
+package ip2x
+
+import (
+	"strconv"
+	"unicode/utf8"
+)
+
+// AppendCSVHeader appends a CSV header row for fields (or all fields in db if
+// none are specified) to dst. The line terminator is not included.
+func (db *DB) AppendCSVHeader(dst []byte, fields ...DBField) []byte {
+	return db.appendHeader(dst, ',', fields)
+}
+
+// AppendTSVHeader is like [DB.AppendCSVHeader], but for [Record.AppendTSV].
+func (db *DB) AppendTSVHeader(dst []byte, fields ...DBField) []byte {
+	return db.appendHeader(dst, '\t', fields)
+}
+
+func (db *DB) appendHeader(dst []byte, sep byte, fields []DBField) []byte {
+	n := 0
+	emit := func(f DBField) bool {
+		if n++; n > 1 {
+			dst = append(dst, sep)
+		}
+		dst = appendDelimitedValue(dst, sep, f.String())
+		return true
+	}
+	if len(fields) == 0 {
+		db.EachField(emit)
+	} else {
+		for _, f := range fields {
+			emit(f)
+		}
+	}
+	return dst
+}
+
+// AppendCSV appends the record as a RFC 4180 CSV row to dst, with columns
+// matching [DB.AppendCSVHeader]. Fields which are specified but not present
+// are left empty. The line terminator is not included. If an error occurs, dst
+// is returned unmodified.
+func (r Record) AppendCSV(dst []byte, fields ...DBField) ([]byte, error) {
+	return r.appendDelimited(dst, ',', fields)
+}
+
+// AppendTSV is like [Record.AppendCSV], but uses tabs as the separator. Since
+// TSV does not support quoting, tabs, newlines, and backslashes in values are
+// escaped with backslashes.
+func (r Record) AppendTSV(dst []byte, fields ...DBField) ([]byte, error) {
+	return r.appendDelimited(dst, '\t', fields)
+}
+
+func (r Record) appendDelimited(dst []byte, sep byte, fields []DBField) ([]byte, error) {
+	var rerr error
+	b, n := dst, 0
+	r.each(fields, true, func(f DBField, fd dbI, dt []byte, err error) bool {
+		if dt == nil && err != nil {
+			rerr = err
+			return false
+		}
+		if n++; n > 1 {
+			b = append(b, sep)
+		}
+		if dt != nil {
+			switch fd.Type() {
+			case dbtype_str:
+				b = appendDelimitedValue(b, sep, as_strref_unsafe(dt))
+			default:
+				b = appendNumber(b, fd.Type(), dt, -1)
+			}
+		}
+		return true
+	})
+	if rerr != nil {
+		return dst, rerr
+	}
+	return b, nil
+}
+
+// appendDelimitedValue appends s to b, quoting it if sep is a comma, or
+// escaping it if sep is a tab.
+func appendDelimitedValue(b []byte, sep byte, s string) []byte {
+	if sep == '\t' {
+		for i := 0; i < len(s); i++ {
+			switch c := s[i]; c {
+			case '\t':
+				b = append(b, '\\', 't')
+			case '\n':
+				b = append(b, '\\', 'n')
+			case '\r':
+				b = append(b, '\\', 'r')
+			case '\\':
+				b = append(b, '\\', '\\')
+			default:
+				b = append(b, c)
+			}
+		}
+		return b
+	}
+	var quote bool
+	if s != "" && (s[0] == ' ' || s[0] == '\t') {
+		quote = true
+	} else {
+		for i := 0; i < len(s); i++ {
+			if c := s[i]; c == sep || c == '"' || c == '\r' || c == '\n' {
+				quote = true
+				break
+			}
+		}
+	}
+	if !quote {
+		return append(b, s...)
+	}
+	b = append(b, '"')
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c == '"' {
+			b = append(b, '"', '"')
+		} else {
+			b = append(b, c)
+		}
+	}
+	return append(b, '"')
+}
+
+// AppendLogfmt appends the record to dst as space-separated logfmt key=value
+// pairs. If fields are specified, only those fields are included, in the
+// specified order. If an error occurs, dst is returned unmodified.
+func (r Record) AppendLogfmt(dst []byte, fields ...DBField) ([]byte, error) {
+	var rerr error
+	b, n := dst, 0
+	r.each(fields, false, func(f DBField, fd dbI, dt []byte, err error) bool {
+		if dt == nil {
+			rerr = err
+			return err == nil
+		}
+		if n++; n > 1 {
+			b = append(b, ' ')
+		}
+		b = append(b, f.String()...)
+		b = append(b, '=')
+		switch fd.Type() {
+		case dbtype_str:
+			if s := as_strref_unsafe(dt); logfmtNeedsQuote(s) {
+				b = strconv.AppendQuote(b, s)
+			} else {
+				b = append(b, s...)
+			}
+		default:
+			b = appendNumber(b, fd.Type(), dt, -1)
+		}
+		return true
+	})
+	if rerr != nil {
+		return dst, rerr
+	}
+	return b, nil
+}
+
+// logfmtNeedsQuote returns true if s must be quoted as a logfmt value.
+func logfmtNeedsQuote(s string) bool {
+	if s == "" {
+		return true
+	}
+	for _, c := range s {
+		if c <= ' ' || c == '=' || c == '"' || c == 0x7f || c == utf8.RuneError {
+			return true
+		}
+	}
+	return false
+}
+
+// appendJSONString appends s as a JSON string, escaped like encoding/json
+// without HTML escaping. Invalid UTF-8 is replaced with U+FFFD.
+func appendJSONString(b []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+	b = append(b, '"')
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			switch {
+			case c == '"' || c == '\\':
+				b = append(b, '\\', c)
+			case c == '\n':
+				b = append(b, '\\', 'n')
+			case c == '\r':
+				b = append(b, '\\', 'r')
+			case c == '\t':
+				b = append(b, '\\', 't')
+			case c < 0x20:
+				b = append(b, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xF])
+			default:
+				b = append(b, c)
+			}
+			i++
+			continue
+		}
+		c, n := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case c == utf8.RuneError && n == 1:
+			b = append(b, `\ufffd`...)
+		case c == '\u2028' || c == '\u2029':
+			b = append(b, '\\', 'u', '2', '0', '2', hex[c&0xF])
+		default:
+			b = append(b, s[i:i+n]...)
+		}
+		i += n
+	}
+	return append(b, '"')
+}
+
+// AppendCBOR appends the record to dst as a RFC 8949 CBOR map of field names to
+// text strings or numbers, or null if the record is not valid.
+// If fields are specified, only those fields are included, in the specified
+// order. If an error occurs, dst is returned unmodified.
+func (r Record) AppendCBOR(dst []byte, fields ...DBField) ([]byte, error) {
+	if !r.IsValid() {
+		return append(dst, 0xf6), nil
+	}
+	var rerr error
+	b := appendCBORHead(dst, 5, uint64(r.count(fields)))
+	r.each(fields, false, func(f DBField, fd dbI, dt []byte, err error) bool {
+		if dt == nil {
+			rerr = err
+			return err == nil
+		}
+		k := f.String()
+		b = appendCBORHead(b, 3, uint64(len(k)))
+		b = append(b, k...)
+		switch fd.Type() {
+		case dbtype_str:
+			b = appendCBORHead(b, 3, uint64(len(dt)))
+			b = append(b, dt...)
+		case dbtype_f32:
+			b = append(b, 0xfa, dt[3], dt[2], dt[1], dt[0])
+		case dbtype_u32:
+			b = appendCBORHead(b, 0, uint64(as_le_u32(dt)))
+		case dbtype_i32:
+			if v := int32(as_le_u32(dt)); v < 0 {
+				b = appendCBORHead(b, 1, uint64(-1-int64(v)))
+			} else {
+				b = appendCBORHead(b, 0, uint64(v))
+			}
+		case dbtype_f64:
+			b = append(b, 0xfb, dt[7], dt[6], dt[5], dt[4], dt[3], dt[2], dt[1], dt[0])
+		case dbtype_u128:
+			if v := as_le_u128(dt); v.hi == 0 {
+				b = appendCBORHead(b, 0, v.lo)
+			} else {
+				x := v.big().Bytes()
+				b = append(b, 0xc2) // tag 2 (unsigned bignum)
+				b = appendCBORHead(b, 2, uint64(len(x)))
+				b = append(b, x...)
+			}
+		}
+		return true
+	})
+	if rerr != nil {
+		return dst, rerr
+	}
+	return b, nil
+}
+
+// appendCBORHead appends a CBOR data item head with the specified major type
+// and argument.
+func appendCBORHead(b []byte, major byte, n uint64) []byte {
+	major <<= 5
+	switch {
+	case n < 24:
+		return append(b, major|byte(n))
+	case n <= 0xff:
+		return append(b, major|24, byte(n))
+	case n <= 0xffff:
+		return append(b, major|25, byte(n>>8), byte(n))
+	case n <= 0xffffffff:
+		return append(b, major|26, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	default:
+		return append(b, major|27, byte(n>>56), byte(n>>48), byte(n>>40), byte(n>>32), byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	}
+}
+
+// AppendMsgpack appends the record to dst as a MessagePack map of field names
+// to strings or numbers, or nil if the record is not valid. If fields are
+// specified, only those fields are included, in the specified order. If an
+// error occurs, dst is returned unmodified.
+func (r Record) AppendMsgpack(dst []byte, fields ...DBField) ([]byte, error) {
+	if !r.IsValid() {
+		return append(dst, 0xc0), nil
+	}
+	var rerr error
+	b := dst
+	if n := r.count(fields); n < 16 {
+		b = append(b, 0x80|byte(n))
+	} else {
+		b = append(b, 0xde, byte(n>>8), byte(n)) // there are less than 2^16 fields
+	}
+	r.each(fields, false, func(f DBField, fd dbI, dt []byte, err error) bool {
+		if dt == nil {
+			rerr = err
+			return err == nil
+		}
+		b = appendMsgpackStr(b, f.String())
+		switch fd.Type() {
+		case dbtype_str:
+			b = appendMsgpackStr(b, as_strref_unsafe(dt))
+		case dbtype_f32:
+			b = append(b, 0xca, dt[3], dt[2], dt[1], dt[0])
+		case dbtype_u32:
+			b = append(b, 0xce, dt[3], dt[2], dt[1], dt[0])
+		case dbtype_i32:
+			b = append(b, 0xd2, dt[3], dt[2], dt[1], dt[0])
+		case dbtype_f64:
+			b = append(b, 0xcb, dt[7], dt[6], dt[5], dt[4], dt[3], dt[2], dt[1], dt[0])
+		case dbtype_u128:
+			if dt[8]|dt[9]|dt[10]|dt[11]|dt[12]|dt[13]|dt[14]|dt[15] == 0 {
+				b = append(b, 0xcf, dt[7], dt[6], dt[5], dt[4], dt[3], dt[2], dt[1], dt[0])
+			} else {
+				b = appendMsgpackStr(b, as_le_u128(dt).big().String()) // msgpack has no bignums
+			}
+		}
+		return true
+	})
+	if rerr != nil {
+		return dst, rerr
+	}
+	return b, nil
+}
+
+// appendMsgpackStr appends s as a MessagePack str.
+func appendMsgpackStr(b []byte, s string) []byte {
+	switch n := len(s); {
+	case n < 32:
+		b = append(b, 0xa0|byte(n))
+	case n <= 0xff:
+		b = append(b, 0xd9, byte(n))
+	case n <= 0xffff:
+		b = append(b, 0xda, byte(n>>8), byte(n))
+	default:
+		b = append(b, 0xdb, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	}
+	return append(b, s...)
+}
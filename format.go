@@ -0,0 +1,168 @@
+package ip2x
+
+import "strconv"
+
+// ColorScheme contains the ANSI escape sequences used by a [Formatter].
+type ColorScheme struct {
+	Product string // product name
+	Type    string // database type
+	Field   string // field name
+	String  string // string value
+	Number  string // numeric value
+	Error   string // error message
+	Reset   string // reset after each of the above
+}
+
+var defaultColorScheme = ColorScheme{
+	Product: "\x1b[34m",
+	Field:   "\x1b[35m",
+	String:  "\x1b[33m",
+	Number:  "\x1b[32m",
+	Error:   "\x1b[31m",
+	Reset:   "\x1b[0m",
+}
+
+// DefaultColorScheme returns a new copy of the color scheme used by
+// [Record.Format].
+func DefaultColorScheme() *ColorScheme {
+	c := defaultColorScheme
+	return &c
+}
+
+// Formatter formats records as human-readable strings. The zero value formats
+// all fields on a single line without color. A Formatter must not be modified
+// while it is in use, but may be used concurrently.
+type Formatter struct {
+	// Color, if not nil, is used to colorize the output.
+	Color *ColorScheme
+
+	// Multiline puts each field on a separate line.
+	Multiline bool
+
+	// Indent is the prefix for each field if Multiline is set. If empty, two
+	// spaces are used.
+	Indent string
+
+	// Fields, if not empty, restricts the output to the specified fields in the
+	// specified order. Fields not present in the record are skipped.
+	Fields []DBField
+
+	// Filter, if not nil, is called to decide whether to include a field.
+	Filter func(DBField) bool
+
+	// Precision is the number of digits after the decimal point for floats. If
+	// zero or negative, the minimum number of digits necessary to represent the
+	// value exactly is used.
+	Precision int
+
+	// Round rounds floats to integers, overriding Precision.
+	Round bool
+
+	// NoHeader omits the database product and type.
+	NoHeader bool
+}
+
+// formatter returns a Formatter with the options used by [Record.Format].
+func formatter(color, multiline bool, fields []DBField) *Formatter {
+	f := &Formatter{
+		Multiline: multiline,
+		Fields:    fields,
+	}
+	if color {
+		f.Color = &defaultColorScheme
+	}
+	return f
+}
+
+// Format formats r as a string. If r is not valid, an empty string is returned.
+func (f *Formatter) Format(r Record) string {
+	if !r.IsValid() {
+		return ""
+	}
+	return as_strref_unsafe(f.Append(make([]byte, 0, 512), r))
+}
+
+// Append is like [Formatter.Format], but appends to dst instead.
+func (f *Formatter) Append(dst []byte, r Record) []byte {
+	if !r.IsValid() {
+		return dst
+	}
+	var c ColorScheme
+	if f.Color != nil {
+		c = *f.Color
+	}
+	indent := f.Indent
+	if indent == "" {
+		indent = "  "
+	}
+	prec := f.Precision
+	if f.Round {
+		prec = 0
+	} else if prec <= 0 {
+		prec = -1
+	}
+	s := dst
+	if !f.NoHeader {
+		_, p, t := r.s.Info()
+		s = append(s, c.Product...)
+		if n := p.name(); n != "" {
+			s = append(s, n...)
+		} else {
+			s = append(s, p.GoString()...)
+		}
+		s = append(s, c.Reset...)
+		s = append(s, '<')
+		s = append(s, c.Type...)
+		s = append(s, p.typePrefix()...)
+		s = strconv.AppendInt(s, int64(t), 10)
+		if c.Type != "" {
+			s = append(s, c.Reset...)
+		}
+		s = append(s, '>')
+		s = append(s, c.Reset...)
+	}
+	s = append(s, '{')
+	n := 0
+	r.each(f.Fields, false, func(fld DBField, fd dbI, dt []byte, err error) bool {
+		if f.Filter != nil && !f.Filter(fld) {
+			return true
+		}
+		if n++; f.Multiline {
+			s = append(s, '\n')
+			s = append(s, indent...)
+		} else if n > 1 {
+			s = append(s, ' ')
+		}
+		s = append(s, c.Field...)
+		s = append(s, fld.String()...)
+		s = append(s, c.Reset...)
+		if f.Multiline {
+			s = append(s, ' ')
+		} else {
+			s = append(s, '=')
+		}
+		if dt != nil {
+			switch fd.Type() {
+			case dbtype_str:
+				s = append(s, c.String...)
+				s = strconv.AppendQuote(s, as_strref_unsafe(dt))
+			default:
+				s = append(s, c.Number...)
+				s = appendNumber(s, fd.Type(), dt, prec)
+			}
+		} else if err != nil {
+			s = append(s, c.Error...)
+			s = append(s, "<error: "...)
+			s = append(s, err.Error()...)
+			s = append(s, '>')
+		}
+		s = append(s, c.Reset...)
+		return true
+	})
+	if f.Multiline {
+		s = append(s, '\n')
+	}
+	s = append(s, '}')
+	s = append(s, c.Reset...)
+	return s
+}
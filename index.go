@@ -0,0 +1,292 @@
+package ip2x
+
+import (
+	"errors"
+	"math/bits"
+	"strconv"
+)
+
+// memIndexChunk is the number of bytes to read at a time while building an
+// in-memory index.
+const memIndexChunk = 1 << 16
+
+// memIndex is an in-memory first-level index for the IPv4 or IPv6 table.
+type memIndex struct {
+	shift uint     // number of low bits (of the IPv4 or upper 64 bits of the IPv6) to discard
+	lower []uint32 // [prefix]row containing the first address of the prefix
+	upper []uint32 // [prefix]row containing the last address of the prefix (optional)
+	last  uint32   // last row (excluding the final one)
+}
+
+// bounds returns the rows which may contain ip, which is a native IPv4 or
+// IPv6.
+func (x *memIndex) bounds(ip uint128, v6 bool) (lower, upper uint32) {
+	var k uint64
+	if v6 {
+		k = ip.hi >> x.shift
+	} else {
+		k = (ip.lo & 0xffffffff) >> x.shift
+	}
+	lower, upper = x.lower[k], x.last
+	if x.upper != nil {
+		upper = x.upper[k]
+	} else if k+1 < uint64(len(x.lower)) {
+		upper = x.lower[k+1] // the next prefix may start in the middle of a row
+	}
+	return
+}
+
+// loadIndex loads or builds the in-memory indexes and search trees if enabled
+// by o.
+func (db *DB) loadIndex(o *options) (err error) {
+	for _, v6 := range []bool{false, true} {
+		var (
+			x    *memIndex
+			bits = o.index4
+		)
+		if v6 {
+			bits = o.index6
+		}
+		if o.eytzinger {
+			// takes precedence, so an index wouldn't be used
+		} else if bits != 0 {
+			x, err = db.buildIndex(v6, bits)
+		} else if o.preload {
+			x, err = db.loadBINIndex(v6)
+		}
+		if err != nil {
+			return err
+		}
+		var e *eytzinger
+		if o.eytzinger {
+			if e, err = db.buildEytzinger(v6); err != nil {
+				return err
+			}
+		}
+		if v6 {
+			db.idx6, db.eyt6 = x, e
+		} else {
+			db.idx4, db.eyt4 = x, e
+		}
+	}
+	return nil
+}
+
+// loadBINIndex loads an index table from the database.
+func (db *DB) loadBINIndex(v6 bool) (*memIndex, error) {
+	off, count := db.ip4idx, db.ip4count
+	if v6 {
+		off, count = db.ip6idx, db.ip6count
+	}
+	if off == 0 || count < 2 {
+		return nil, nil
+	}
+	b := make([]byte, binIndexSize)
+	if n, err := db.r.ReadAt(b, int64(off)-1); n != len(b) {
+		return nil, errors.New("read index: " + err.Error())
+	}
+	x := &memIndex{
+		lower: make([]uint32, 1<<16),
+		upper: make([]uint32, 1<<16),
+		last:  count - 2,
+	}
+	if v6 {
+		x.shift = 64 - 16
+	} else {
+		x.shift = 32 - 16
+	}
+	for k := range x.lower {
+		x.lower[k] = as_le_u32(b[k*8:])
+		x.upper[k] = as_le_u32(b[k*8+4:])
+	}
+	return x, nil
+}
+
+// buildIndex builds an index for the top bits of the IPv4 or IPv6 addresses by
+// reading the start address of every row.
+func (db *DB) buildIndex(v6 bool, bits int) (*memIndex, error) {
+	var (
+		count      = db.ip4count
+		width uint = 32
+	)
+	if v6 {
+		count, width = db.ip6count, 64
+	}
+	if bits < 16 || bits > 24 {
+		return nil, errors.New("index bits must be between 16 and 24, got " + strconv.Itoa(bits))
+	}
+	if count < 2 {
+		return nil, nil
+	}
+	x := &memIndex{
+		shift: width - uint(bits),
+		lower: make([]uint32, 1<<bits),
+		last:  count - 2,
+	}
+
+	var (
+		rows = newRowScanner(db, v6)
+		i    uint32
+	)
+	for k := range x.lower {
+		start := uint128{lo: uint64(k) << x.shift}
+		if v6 {
+			start = uint128{hi: uint64(k) << x.shift}
+		}
+		for i < x.last {
+			v, err := rows.from(i + 1)
+			if err != nil {
+				return nil, err
+			}
+			if start.Less(v) {
+				break
+			}
+			i++
+		}
+		x.lower[k] = i
+	}
+	return x, nil
+}
+
+// rowScanner reads the start address of rows in the IPv4 or IPv6 table. Rows
+// are read in chunks since reading them one at a time would be slow if the
+// database isn't in memory, so it is most efficient to read them in order.
+type rowScanner struct {
+	db      *DB
+	v6      bool
+	base    int64
+	rowsize int64
+	last    int64 // last row (excluding the final one)
+	chunk   []byte
+	first   int64 // first row in chunk
+	nrows   int64
+}
+
+// newRowScanner creates a new rowScanner. The table must have at least one row
+// other than the final one.
+func newRowScanner(db *DB, v6 bool) *rowScanner {
+	s := &rowScanner{
+		db:      db,
+		v6:      v6,
+		base:    int64(db.ip4base),
+		rowsize: 4 + int64(db.dbcolumn-1)*4,
+		last:    int64(db.ip4count) - 2,
+		first:   -1,
+	}
+	if v6 {
+		s.base = int64(db.ip6base)
+		s.rowsize = 16 + int64(db.dbcolumn-1)*4
+		s.last = int64(db.ip6count) - 2
+	}
+	s.chunk = make([]byte, (memIndexChunk/s.rowsize+1)*s.rowsize)
+	return s
+}
+
+// from returns the start address of row i.
+func (s *rowScanner) from(i uint32) (uint128, error) {
+	if s.first < 0 || int64(i) < s.first || int64(i) >= s.first+s.nrows {
+		s.first, s.nrows = int64(i), int64(len(s.chunk))/s.rowsize
+		if n := s.last + 1 - s.first; s.nrows > n {
+			s.nrows = n
+		}
+		if n, err := s.db.r.ReadAt(s.chunk[:s.nrows*s.rowsize], s.base-1+s.first*s.rowsize); n != int(s.nrows*s.rowsize) {
+			return uint128{}, errors.New("read row " + strconv.FormatUint(uint64(i), 10) + ": " + err.Error())
+		}
+	}
+	b := s.chunk[(int64(i)-s.first)*s.rowsize:]
+	if s.v6 {
+		return as_le_u128(b), nil
+	}
+	return as_u32_u128(as_le_u32(b)), nil
+}
+
+// eytzinger is an in-memory binary search tree over the start addresses of the
+// rows in the IPv4 or IPv6 table, stored in Eytzinger (breadth-first) order,
+// which is much more cache-friendly than a binary search over the sorted rows
+// since the top levels of the tree are close together.
+type eytzinger struct {
+	k4  []uint32  // [node]start address (IPv4), 1-based
+	k6  []uint128 // [node]start address (IPv6), 1-based
+	row []uint32  // [node]row index, 1-based
+}
+
+// buildEytzinger builds an eytzinger tree for the IPv4 or IPv6 table by reading
+// the start address of every row.
+func (db *DB) buildEytzinger(v6 bool) (*eytzinger, error) {
+	count := db.ip4count
+	if v6 {
+		count = db.ip6count
+	}
+	if count < 2 {
+		return nil, nil
+	}
+	var (
+		n    = int(count - 1)
+		e    = &eytzinger{row: make([]uint32, n+1)}
+		rows = newRowScanner(db, v6)
+		i    uint32
+		err  error
+	)
+	if v6 {
+		e.k6 = make([]uint128, n+1)
+	} else {
+		e.k4 = make([]uint32, n+1)
+	}
+
+	// an in-order traversal of the tree visits the rows in order
+	var fill func(k int)
+	fill = func(k int) {
+		if k > n || err != nil {
+			return
+		}
+		fill(2 * k)
+		var v uint128
+		if v, err = rows.from(i); err != nil {
+			return
+		}
+		if v6 {
+			e.k6[k] = v
+		} else {
+			e.k4[k] = uint32(v.lo)
+		}
+		e.row[k] = i
+		i++
+		fill(2*k + 1)
+	}
+	if fill(1); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// search returns the row which may contain ip, which is a native IPv4 or IPv6,
+// or false if ip is before the first row.
+func (e *eytzinger) search(ip uint128, v6 bool) (uint32, bool) {
+	k, n := uint(1), uint(len(e.row)-1)
+	if v6 {
+		for k <= n {
+			k = 2*k + uint(bool2int(!ip.Less(e.k6[k])))
+		}
+	} else {
+		ip4 := uint32(ip.lo)
+		for k <= n {
+			k = 2*k + uint(bool2int(e.k4[k] <= ip4))
+		}
+	}
+	// remove the right turns after the last left turn to get the first node
+	// greater than ip
+	if k >>= bits.TrailingZeros(^k) + 1; k == 0 {
+		return uint32(n - 1), true // ip is after the start of the last row
+	}
+	if r := e.row[k]; r != 0 {
+		return r - 1, true
+	}
+	return 0, false
+}
+
+func bool2int(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,76 @@
+package ip2x
+
+import "sync"
+
+// strCacheOverhead is the approximate memory used by each entry in a strCache
+// other than the string data.
+const strCacheOverhead = 48
+
+// strCache caches strings from the string heap by offset.
+type strCache struct {
+	mu   sync.RWMutex
+	m    map[uint32]string
+	size int
+	max  int
+}
+
+func newStrCache(max int) *strCache {
+	return &strCache{
+		m:   map[uint32]string{},
+		max: max,
+	}
+}
+
+// get gets the string at ptr if cached. The returned slice must not be
+// modified.
+func (c *strCache) get(ptr uint32) ([]byte, bool) {
+	c.mu.RLock()
+	s, ok := c.m[ptr]
+	c.mu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	if len(s) == 0 {
+		return []byte{}, true // non-nil
+	}
+	return as_bytesref_unsafe(s), true
+}
+
+// put caches a copy of the string b at ptr if there is space, returning the
+// cached string, or b if there isn't.
+func (c *strCache) put(ptr uint32, b []byte) []byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if s, ok := c.m[ptr]; ok {
+		if len(s) == 0 {
+			return b
+		}
+		return as_bytesref_unsafe(s)
+	}
+	if c.size+len(b)+strCacheOverhead > c.max {
+		return b
+	}
+	s := string(b)
+	c.m[ptr] = s
+	c.size += len(s) + strCacheOverhead
+	if len(s) == 0 {
+		return b
+	}
+	return as_bytesref_unsafe(s)
+}
+
+// stats returns the number of cached strings and the approximate memory used.
+func (c *strCache) stats() (n, size int) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.m), c.size
+}
+
+// InternStats returns the number of strings cached by [InternStrings] and the
+// approximate memory used.
+func (db *DB) InternStats() (n, size int) {
+	if db.c == nil {
+		return 0, 0
+	}
+	return db.c.stats()
+}
@@ -4,6 +4,7 @@ package codegen
 import (
 	"bufio"
 	"bytes"
+	"flag"
 	"fmt"
 	"go/ast"
 	"go/format"
@@ -14,6 +15,7 @@ import (
 	"reflect"
 	"regexp"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"unicode"
@@ -27,9 +29,11 @@ import (
 // type (i.e., variant).
 //
 // The following lines define the columns in the file. First, it should specify
-// the field type (currently str/f32, plus an optional @N suffix for pointers
-// where N is the number of bytes to add to the uint32 offset in the database
-// before reading it). This should be followed by the column name (which must
+// the field type (str, f32, u32, i32, f64, or u128, plus an optional @N suffix
+// for pointers where N is the number of bytes to add to the uint32 offset in
+// the database before reading it). Strings are length-prefixed, and numbers are
+// little-endian. Since f64 and u128 do not fit in a column, they must be
+// pointers. This should be followed by the column name (which must
 // have a corresponding [Column] defined), then the database column number for
 // each database type (starting at 2 since column 1 is always ip_from, and . if
 // it is not present in the database type).
@@ -127,8 +131,51 @@ type Field string
 //	func main() {
 //		codegen.Main()
 //	}
+//
+// The following flags are supported, and will be preserved in the generated
+// go:generate comment:
+//
+//	-structs          generate a struct for each product type (e.g.,
+//	                  DB11Record) and a Record method to convert to it (e.g.,
+//	                  Record.ToDB11)
+//	-jsonschema dir   write a JSON Schema matching Record.MarshalJSON for each
+//	                  product type to dir
+//	-openapi file     write an OpenAPI document with a schema component for each
+//	                  product type to file
+//	-proto file       write a protobuf message with every field, numbered by its
+//	                  DBField value, to file
+//
+// The following flags switch to a different mode which checks the spec against
+// real files instead of generating code:
+//
+//	-samples dir      check the column count and field placement for each
+//	                  sample BIN file in dir against the spec
+//	-golden dir       also write lookup test vectors for each sample BIN file
+//	                  to dir/NAME.golden.json
+//
+// For example, to check the spec and update the golden test vectors after
+// downloading sample databases to the test directory, run:
+//
+//	go run dbdata.go -samples test -golden test/testdata
+//
+// Paths are relative to the directory containing the main file.
 func Main() {
 	var spec spec
+	fs := flag.NewFlagSet("codegen", flag.ExitOnError)
+	fs.BoolVar(&spec.structs, "structs", false, "generate per-type record structs")
+	fs.StringVar(&spec.jsonschema, "jsonschema", "", "write per-type JSON Schemas to this directory")
+	fs.StringVar(&spec.openapi, "openapi", "", "write an OpenAPI document to this file")
+	fs.StringVar(&spec.proto, "proto", "", "write a protobuf message to this file")
+	fs.StringVar(&spec.samples, "samples", "", "check the spec against the BIN files in this directory")
+	fs.StringVar(&spec.golden, "golden", "", "write golden test vectors for the sample BIN files to this directory")
+	fs.Parse(os.Args[1:])
+	fs.Visit(func(f *flag.Flag) {
+		if v := f.Value.String(); v == "true" {
+			spec.args = append(spec.args, "-"+f.Name)
+		} else {
+			spec.args = append(spec.args, "-"+f.Name, v)
+		}
+	})
 	if pc, file, _, ok := runtime.Caller(1); !ok {
 		panic("codegen: failed to get caller info")
 	} else if ext := filepath.Ext(file); ext != ".go" {
@@ -141,6 +188,14 @@ func Main() {
 	} else if err := spec.Parse(file); err != nil {
 		fmt.Fprintf(os.Stderr, "codegen: fatal: parse: %v\n", err)
 		os.Exit(1)
+	} else if spec.golden != "" && spec.samples == "" {
+		fmt.Fprintf(os.Stderr, "codegen: fatal: -golden requires -samples\n")
+		os.Exit(1)
+	} else if spec.samples != "" {
+		if err := spec.checkSamples(filepath.Dir(file)); err != nil {
+			fmt.Fprintf(os.Stderr, "codegen: fatal: samples: %v\n", err)
+			os.Exit(1)
+		}
 	} else if err := spec.Generate(file, strings.TrimSuffix(file, ext)+".ip2x"+ext); err != nil {
 		fmt.Fprintf(os.Stderr, "codegen: fatal: generate: %v\n", err)
 		os.Exit(1)
@@ -148,9 +203,16 @@ func Main() {
 }
 
 type spec struct {
-	product  []*specProduct
-	field    []*specField
-	fieldNum uint
+	product    []*specProduct
+	field      []*specField
+	fieldNum   uint
+	structs    bool
+	jsonschema string
+	openapi    string
+	proto      string
+	samples    string
+	golden     string
+	args       []string
 }
 
 type specProduct struct {
@@ -214,6 +276,24 @@ func (spec *spec) Parse(name string) error {
 	return nil
 }
 
+// columnTypes contains information about the supported column types.
+var columnTypes = map[string]struct {
+	GoType    string // value type
+	Getter    string // Record method to get the value
+	Decode    string // expression converting the raw data (dt) to the value
+	JSONType  string // JSON Schema type of the value in Record.MarshalJSON
+	ProtoType string // protobuf scalar type
+	Import    string // package required by GoType
+	Pointer   bool   // whether the value is too large to be stored inline
+}{
+	"str":  {"string", "GetString", "string(dt)", "string", "string", "", false},
+	"f32":  {"float32", "GetFloat32", "as_f32(as_le_u32(dt))", "number", "float", "", false},
+	"u32":  {"uint32", "GetUint32", "as_le_u32(dt)", "integer", "uint32", "", false},
+	"i32":  {"int32", "GetInt32", "int32(as_le_u32(dt))", "integer", "sint32", "", false},
+	"f64":  {"float64", "GetFloat64", "as_f64(as_le_u64(dt))", "number", "double", "", true},
+	"u128": {"*big.Int", "GetBigInt", "as_le_u128(dt).big()", "integer", "bytes", "math/big", true},
+}
+
 var (
 	productPrefixRe     = regexp.MustCompile(`^[A-Z]+$`)
 	productColumnTypeRe = regexp.MustCompile(`^([a-z0-9]+)(?:@([0-9]+))?$`)
@@ -315,6 +395,10 @@ func (spec *spec) parseProduct(goname string, godoc []string, val string) (*spec
 				return nil, fmt.Errorf("line %d: expected column type, got end of line", line)
 			} else if m := productColumnTypeRe.FindStringSubmatch(words[0]); m == nil {
 				return nil, fmt.Errorf("line %d: invalid column type %q (must match %#q)", line, words[0], productColumnTypeRe)
+			} else if ct, ok := columnTypes[m[1]]; !ok {
+				return nil, fmt.Errorf("line %d: unsupported column type %q", line, m[1])
+			} else if ct.Pointer && m[2] == "" {
+				return nil, fmt.Errorf("line %d: column type %q must be a pointer", line, m[1])
 			} else {
 				col.Type = m[1]
 				if m[2] == "" {
@@ -471,9 +555,36 @@ func (spec *spec) Generate(src, dst string) error {
 	buf.WriteString("// Code generated by codegen; DO NOT EDIT.\n\n")
 
 	buf.WriteString("package ip2x\n")
-	buf.WriteString("\nimport \"strconv\"\n")
+	imports := []string{"strconv"}
+	for _, prod := range spec.product {
+		for _, col := range prod.ProductColumn {
+			if imp := columnTypes[col.Type].Import; imp != "" {
+				imports = append(imports, imp)
+			}
+		}
+	}
+	sort.Strings(imports)
+	for i := 1; i < len(imports); i++ {
+		if imports[i] == imports[i-1] {
+			imports = append(imports[:i], imports[i+1:]...)
+			i--
+		}
+	}
+	if len(imports) == 1 {
+		fmt.Fprintf(&buf, "\nimport %q\n", imports[0])
+	} else {
+		buf.WriteString("\nimport (\n")
+		for _, imp := range imports {
+			fmt.Fprintf(&buf, "\t%q\n", imp)
+		}
+		buf.WriteString(")\n")
+	}
 
-	fmt.Fprintf(&buf, "\n//go:generate go run %s\n", pathquote(filepath.Base(src)))
+	fmt.Fprintf(&buf, "\n//go:generate go run %s", pathquote(filepath.Base(src)))
+	for _, arg := range spec.args {
+		fmt.Fprintf(&buf, " %s", pathquote(arg))
+	}
+	buf.WriteString("\n")
 
 	for _, prod := range spec.product {
 		for _, line := range prod.GoDoc {
@@ -514,15 +625,17 @@ func (spec *spec) Generate(src, dst string) error {
 	for _, prod := range spec.product {
 		fmt.Fprintf(&buf, "\t%s: {\n", prod.GoName)
 		for t := uint8(1); t <= prod.DatabaseTypeMax; t++ {
-			fmt.Fprintf(&buf, "\t\t%d: {", t)
-			var n int
+			fmt.Fprintf(&buf, "\t\t%d: {f: dbF{", t)
+			var n uint8 // number of columns, including ip_from
 			for _, col := range prod.ProductColumn {
-				if col.DatabaseColumn[t] != 0 {
-					fmt.Fprintf(&buf, "%s: {%d, %d, dbtype_%s}, ", col.Field.GoName, col.DatabaseColumn[t], col.Pointer, col.Type)
-					n++
+				if c := col.DatabaseColumn[t]; c != 0 {
+					fmt.Fprintf(&buf, "%s: {%d, %d, dbtype_%s}, ", col.Field.GoName, c, col.Pointer, col.Type)
+					if c > n {
+						n = c
+					}
 				}
 			}
-			fmt.Fprintf(&buf, "dbField_extra: {%d, uint8(%s), %d}},\n", n, prod.GoName, t)
+			fmt.Fprintf(&buf, "dbField_extra: {%d, uint8(%s), %d}}},\n", n, prod.GoName, t)
 		}
 		fmt.Fprintf(&buf, "\t},\n")
 	}
@@ -551,7 +664,11 @@ func (spec *spec) Generate(src, dst string) error {
 		ptr uint8
 		typ uint8
 	}
-	type dbS [dbFieldMax + 2]dbI
+	type dbF [dbFieldMax + 2]dbI
+	type dbS struct {
+		f dbF
+		x []dbI // registered fields after dbField_extra
+	}
 	type dbs [dbProductMax + 1][dbTypeMax + 1]dbS
 
 	func dbinfo(p DBProduct, t DBType) (r *dbS) {
@@ -562,15 +679,26 @@ func (spec *spec) Generate(src, dst string) error {
 	}
 
 	func (i *dbS) Field(f DBField) (r dbI) {
-		if i != nil && f <= dbFieldMax {
-			r = i[f]
+		if i != nil {
+			if f <= dbFieldMax {
+				r = i.f[f]
+			} else if f > dbField_extra && f-dbField_extra <= DBField(len(i.x)) {
+				r = i.x[f-dbField_extra-1]
+			}
 		}
 		return
 	}
 
+	func (i *dbS) FieldMax() DBField {
+		if i != nil && len(i.x) != 0 {
+			return dbField_extra + DBField(len(i.x))
+		}
+		return dbFieldMax
+	}
+
 	func (i *dbS) Info() (c uint8, p DBProduct, t DBType) {
 		if i != nil {
-			x := i[dbField_extra]
+			x := i.f[dbField_extra]
 			c, p, t = x.col, DBProduct(x.ptr), DBType(x.typ)
 		}
 		return
@@ -583,6 +711,114 @@ func (spec *spec) Generate(src, dst string) error {
 	func (c dbI) Type() uint8      { return c.typ }
 	`, "\n\t", "\n"))
 
+	names := map[string]string{} // [lowercase name]GoName
+	buf.WriteString("\nfunc dbProductByName(s string) DBProduct {\n\tswitch s {\n")
+	for _, prod := range spec.product {
+		var cs []string
+		for _, n := range []string{prod.GoName, prod.ProductName, prod.ProductPrefix} {
+			if n = strings.ToLower(n); names[n] == "" {
+				names[n] = prod.GoName
+				cs = append(cs, strconv.Quote(n))
+			} else if names[n] != prod.GoName {
+				return fmt.Errorf("product %q: name %q conflicts with %q", prod.GoName, n, names[n])
+			}
+		}
+		fmt.Fprintf(&buf, "\tcase %s:\n\t\treturn %s\n", strings.Join(cs, ", "), prod.GoName)
+	}
+	buf.WriteString("\t}\n\treturn 0\n}\n")
+
+	names = map[string]string{}
+	buf.WriteString("\nfunc dbFieldByName(s string) DBField {\n\tswitch s {\n")
+	for _, fld := range spec.field {
+		var cs []string
+		for _, n := range []string{fld.ColumnName, fld.GoName} {
+			if n = strings.ToLower(n); names[n] == "" {
+				names[n] = fld.GoName
+				cs = append(cs, strconv.Quote(n))
+			} else if names[n] != fld.GoName {
+				return fmt.Errorf("field %q: name %q conflicts with %q", fld.GoName, n, names[n])
+			}
+		}
+		fmt.Fprintf(&buf, "\tcase %s:\n\t\treturn %s\n", strings.Join(cs, ", "), fld.GoName)
+	}
+	buf.WriteString("\t}\n\treturn 0\n}\n")
+
+	var types []string
+	fieldTypes := map[string][]string{} // [Type]GoName
+	fieldType := map[*specField]string{}
+	for _, fld := range spec.field {
+		var typ string
+		for _, prod := range spec.product {
+			for _, col := range prod.ProductColumn {
+				if col.Field == fld {
+					if typ == "" {
+						typ = col.Type
+					} else if typ != col.Type {
+						return fmt.Errorf("field %q: inconsistent column type: %s in %s, but %s elsewhere", fld.GoName, col.Type, prod.GoName, typ)
+					}
+				}
+			}
+		}
+		if typ != "" {
+			if fieldTypes[typ] == nil {
+				types = append(types, typ)
+			}
+			fieldTypes[typ] = append(fieldTypes[typ], fld.GoName)
+			fieldType[fld] = typ
+		}
+	}
+	buf.WriteString("\nfunc (f DBField) typ() (t uint8, ok bool) {\n\tswitch f {\n")
+	for _, typ := range types {
+		fmt.Fprintf(&buf, "\tcase %s:\n\t\treturn dbtype_%s, true\n", strings.Join(fieldTypes[typ], ", "), typ)
+	}
+	buf.WriteString("\t}\n\treturn\n}\n")
+
+	for _, fld := range spec.field {
+		if typ, ok := fieldType[fld]; ok {
+			ct := columnTypes[typ]
+			fmt.Fprintf(&buf, "\n// %s gets the [%s] field.\n", fld.GoName, fld.GoName)
+			fmt.Fprintf(&buf, "func (r Record) %s() (%s, bool) {\n\treturn r.%s(%s)\n}\n", fld.GoName, ct.GoType, ct.Getter, fld.GoName)
+		}
+	}
+
+	if spec.structs {
+		for _, prod := range spec.product {
+			for t := uint8(1); t <= prod.DatabaseTypeMax; t++ {
+				var cols []*specProductColumn
+				for _, col := range prod.ProductColumn {
+					if col.DatabaseColumn[t] != 0 {
+						cols = append(cols, col)
+					}
+				}
+				name := fmt.Sprintf("%s%d", prod.ProductPrefix, t)
+
+				fmt.Fprintf(&buf, "\n// %sRecord contains the fields of %s %s databases.\n", name, prod.ProductName, name)
+				fmt.Fprintf(&buf, "type %sRecord struct {\n", name)
+				for _, col := range cols {
+					fmt.Fprintf(&buf, "\t%s %s\n", col.Field.GoName, columnTypes[col.Type].GoType)
+				}
+				buf.WriteString("}\n")
+
+				fmt.Fprintf(&buf, "\nvar _%sRecord_fields = [...]DBField{", name)
+				for i, col := range cols {
+					if i != 0 {
+						buf.WriteString(", ")
+					}
+					buf.WriteString(col.Field.GoName)
+				}
+				buf.WriteString("}\n")
+
+				fmt.Fprintf(&buf, "\n// To%s gets the fields of a [%sRecord] from r, which must contain all of\n// them (i.e., be from a %s database or any other type containing its fields).\n", name, name, name)
+				fmt.Fprintf(&buf, "func (r Record) To%s() (v %sRecord, err error) {\n", name, name)
+				fmt.Fprintf(&buf, "\terr = r.decode(_%sRecord_fields[:], func(i int, dt []byte) {\n\t\tswitch i {\n", name)
+				for i, col := range cols {
+					fmt.Fprintf(&buf, "\t\tcase %d:\n\t\t\tv.%s = %s\n", i, col.Field.GoName, columnTypes[col.Type].Decode)
+				}
+				buf.WriteString("\t\t}\n\t})\n\treturn\n}\n")
+			}
+		}
+	}
+
 	var ss stringerSet
 	var (
 		ssProductGo     = ss.Add("GoString", "DBProduct", "p", false).Default(true, true)
@@ -590,6 +826,7 @@ func (spec *spec) Generate(src, dst string) error {
 		ssProductPrefix = ss.Add("prefix", "DBProduct", "p", false)
 		ssFieldGo       = ss.Add("GoString", "DBField", "f", false).Default(true, true)
 		ssFieldColumn   = ss.Add("column", "DBField", "f", false)
+		ssFieldDesc     = ss.Add("description", "DBField", "f", false)
 	)
 	for _, prod := range spec.product {
 		ssProductGo.Set(int(prod.ProductCode), prod.GoName)
@@ -599,6 +836,7 @@ func (spec *spec) Generate(src, dst string) error {
 	for _, fld := range spec.field {
 		ssFieldGo.Set(int(fld.FieldNum), fld.GoName)
 		ssFieldColumn.Set(int(fld.FieldNum), fld.ColumnName)
+		ssFieldDesc.Set(int(fld.FieldNum), strings.Join(fld.GoDoc, "\n"))
 	}
 	buf.Write(ss.Bytes())
 
@@ -609,6 +847,22 @@ func (spec *spec) Generate(src, dst string) error {
 	} else if err := os.WriteFile(dst, b, 0666); err != nil {
 		return err
 	}
+
+	if spec.jsonschema != "" {
+		if err := spec.generateJSONSchema(filepath.Join(filepath.Dir(src), spec.jsonschema)); err != nil {
+			return fmt.Errorf("json schema: %w", err)
+		}
+	}
+	if spec.openapi != "" {
+		if err := spec.generateOpenAPI(filepath.Join(filepath.Dir(src), spec.openapi)); err != nil {
+			return fmt.Errorf("openapi: %w", err)
+		}
+	}
+	if spec.proto != "" {
+		if err := spec.generateProto(filepath.Join(filepath.Dir(src), spec.proto), fieldType); err != nil {
+			return fmt.Errorf("proto: %w", err)
+		}
+	}
 	return nil
 }
 
@@ -0,0 +1,345 @@
+package codegen
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// sampleRows is the maximum number of rows to check in each table of a sample
+// database. A golden test vector is generated for each one.
+const sampleRows = 64
+
+// sampleErrorMax is the maximum number of problems to report for each sample
+// database.
+const sampleErrorMax = 10
+
+// sample is an IP2Location binary database used to check the spec.
+type sample struct {
+	Name    string
+	Product *specProduct
+	Type    uint8
+	Date    string
+	Vectors []sampleVector
+
+	r      io.ReaderAt
+	size   int64
+	column uint8
+	errs   []string
+}
+
+type sampleVector struct {
+	IP     netip.Addr
+	Fields jsonObject // column name -> value as returned by Record.GetString
+}
+
+// checkSamples checks the column count and field placement of each BIN file in
+// the -samples directory against the spec, then writes golden test vectors to
+// the -golden directory if set. Paths are relative to base.
+func (spec *spec) checkSamples(base string) error {
+	dir := resolvePath(base, spec.samples)
+	names, err := filepath.Glob(filepath.Join(dir, "*"))
+	if err != nil {
+		return err
+	}
+	var (
+		samples []*sample
+		errs    []string
+	)
+	for _, name := range names {
+		if !strings.EqualFold(filepath.Ext(name), ".bin") {
+			continue
+		}
+		s, err := spec.checkSample(name)
+		if err != nil {
+			return fmt.Errorf("%s: %w", filepath.Base(name), err)
+		}
+		for _, e := range s.errs {
+			errs = append(errs, s.Name+": "+e)
+		}
+		samples = append(samples, s)
+	}
+	if len(samples) == 0 {
+		return fmt.Errorf("no BIN files found in %q", dir)
+	}
+	if len(errs) != 0 {
+		return fmt.Errorf("spec does not match sample files:\n\t%s", strings.Join(errs, "\n\t"))
+	}
+	if spec.golden != "" {
+		golden := resolvePath(base, spec.golden)
+		if err := os.MkdirAll(golden, 0777); err != nil {
+			return err
+		}
+		for _, s := range samples {
+			if err := s.writeGolden(filepath.Join(golden, strings.TrimSuffix(s.Name, filepath.Ext(s.Name))+".golden.json")); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkSample reads and checks a sample database. An error is returned if it
+// could not be read, but problems with the spec are added to sample.errs.
+func (spec *spec) checkSample(name string) (*sample, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	var h [64]byte
+	if _, err := f.ReadAt(h[:], 0); err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+	if h[0] == 'P' && h[1] == 'K' {
+		return nil, errors.New("database is zipped")
+	}
+	if h[29] == 0 {
+		return nil, errors.New("database does not have a product code (it is probably from before 2021)")
+	}
+
+	s := &sample{
+		Name:   filepath.Base(name),
+		Type:   h[0],
+		Date:   fmt.Sprintf("20%02d-%02d-%02d", h[2], h[3], h[4]),
+		r:      f,
+		size:   fi.Size(),
+		column: h[1],
+	}
+	for _, prod := range spec.product {
+		if prod.ProductCode == h[29] {
+			s.Product = prod
+		}
+	}
+	if s.Product == nil {
+		s.errorf("product code %d is not in the spec", h[29])
+		return s, nil
+	}
+	if s.Type == 0 || s.Type > s.Product.DatabaseTypeMax {
+		s.errorf("%s type %d is not in the spec", s.Product.ProductName, s.Type)
+		return s, nil
+	}
+
+	var column uint8 = 1
+	for _, col := range s.Product.ProductColumn {
+		if c := col.DatabaseColumn[s.Type]; c > column {
+			column = c
+		}
+	}
+	if column != s.column {
+		s.errorf("%s%d has %d columns in the spec, but %d in the file", s.Product.ProductPrefix, s.Type, column, s.column)
+		return s, nil
+	}
+
+	for _, v6 := range []bool{false, true} {
+		var count, base uint32
+		if v6 {
+			count, base = le32(h[13:]), le32(h[17:])
+		} else {
+			count, base = le32(h[5:]), le32(h[9:])
+		}
+		if err := s.checkRows(v6, count, base); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// checkRows checks up to sampleRows evenly-spaced rows of the IPv4 or IPv6
+// table, adding a test vector for each one.
+func (s *sample) checkRows(v6 bool, count, base uint32) error {
+	if count < 2 {
+		return nil // no rows other than the final one
+	}
+	iplen, ver := 4, 4
+	if v6 {
+		iplen, ver = 16, 6
+	}
+	var (
+		rows    = int(count) - 1
+		rowsize = iplen + int(s.column-1)*4
+		row     = make([]byte, rowsize+iplen)
+		prev    netip.Addr
+	)
+	for i := 0; i < sampleRows && i < rows; i++ {
+		idx := i
+		if rows > sampleRows {
+			idx = int(int64(i) * int64(rows-1) / (sampleRows - 1))
+		}
+		if _, err := s.r.ReadAt(row, int64(base)-1+int64(idx)*int64(rowsize)); err != nil {
+			return fmt.Errorf("read row %d: %w", idx, err)
+		}
+
+		var from, to netip.Addr
+		if v6 {
+			from, to = le128addr(row[:16]), le128addr(row[rowsize:])
+		} else {
+			from, to = le32addr(row[:4]), le32addr(row[rowsize:])
+		}
+		if !from.Less(to) || (prev.IsValid() && !prev.Less(from)) {
+			s.errorf("ipv%d row %d: %s-%s is not sorted", ver, idx, from, to)
+			continue
+		}
+		prev = from
+
+		vec := sampleVector{IP: from}
+		for _, col := range s.Product.ProductColumn {
+			c := col.DatabaseColumn[s.Type]
+			if c == 0 {
+				continue
+			}
+			val, err := s.value(col, row[iplen+int(c-2)*4:])
+			if err != nil {
+				s.errorf("ipv%d row %d: column %d (%s %s): %v", ver, idx, c, col.Type, col.Field.ColumnName, err)
+				continue
+			}
+			vec.Fields = append(vec.Fields, jsonMember{col.Field.ColumnName, val})
+		}
+		if !v6 || !unmapsToIPv4(from) {
+			s.Vectors = append(s.Vectors, vec)
+		}
+	}
+	return nil
+}
+
+// value reads and formats the value of col from the column data b.
+func (s *sample) value(col *specProductColumn, b []byte) (string, error) {
+	v := le32(b)
+	if col.Pointer == 0xFF {
+		switch col.Type {
+		case "f32":
+			f := math.Float32frombits(v)
+			if math.IsNaN(float64(f)) || math.IsInf(float64(f), 0) {
+				return "", fmt.Errorf("invalid float %v", f)
+			}
+			if f != 0 && math.Abs(float64(f)) < 1e-30 {
+				return "", fmt.Errorf("float %v looks like a pointer", f)
+			}
+			return strconv.FormatFloat(float64(f), 'f', -1, 32), nil
+		case "u32":
+			return strconv.FormatUint(uint64(v), 10), nil
+		case "i32":
+			return strconv.FormatInt(int64(int32(v)), 10), nil
+		}
+		return "", fmt.Errorf("unhandled inline type")
+	}
+
+	if v < 64 {
+		return "", fmt.Errorf("pointer %d is inside the header", v)
+	}
+	off := int64(v) + int64(col.Pointer)
+	if col.Type == "str" {
+		var n [1]byte
+		if off >= s.size {
+			return "", fmt.Errorf("string offset %d is outside the file", off)
+		}
+		if _, err := s.r.ReadAt(n[:], off); err != nil {
+			return "", err
+		}
+		if off+1+int64(n[0]) > s.size {
+			return "", fmt.Errorf("string at offset %d with length %d is outside the file", off, n[0])
+		}
+		buf := make([]byte, n[0])
+		if _, err := s.r.ReadAt(buf, off+1); err != nil {
+			return "", err
+		}
+		return string(buf), nil
+	}
+
+	var size int64
+	switch col.Type {
+	case "f64":
+		size = 8
+	case "u128":
+		size = 16
+	default:
+		return "", fmt.Errorf("unhandled pointer type")
+	}
+	if off+size > s.size {
+		return "", fmt.Errorf("%s at offset %d is outside the file", col.Type, off)
+	}
+	buf := make([]byte, size)
+	if _, err := s.r.ReadAt(buf, off); err != nil {
+		return "", err
+	}
+	if col.Type == "f64" {
+		return strconv.FormatFloat(math.Float64frombits(uint64(le32(buf))|uint64(le32(buf[4:]))<<32), 'f', -1, 64), nil
+	}
+	n := new(big.Int)
+	for i := len(buf) - 1; i >= 0; i-- {
+		n.Lsh(n, 8)
+		n.Or(n, big.NewInt(int64(buf[i])))
+	}
+	return n.String(), nil
+}
+
+func (s *sample) errorf(format string, a ...any) {
+	if len(s.errs) < sampleErrorMax {
+		s.errs = append(s.errs, fmt.Sprintf(format, a...))
+	} else if len(s.errs) == sampleErrorMax {
+		s.errs = append(s.errs, "...")
+	}
+}
+
+// writeGolden writes the test vectors for s to name.
+func (s *sample) writeGolden(name string) error {
+	vectors := make([]jsonObject, len(s.Vectors))
+	for i, vec := range s.Vectors {
+		vectors[i] = jsonObject{
+			{"ip", vec.IP.String()},
+			{"fields", vec.Fields},
+		}
+	}
+	return writeJSON(name, jsonObject{
+		{"file", s.Name},
+		{"product", s.Product.ProductCode},
+		{"type", s.Type},
+		{"date", s.Date},
+		{"vectors", vectors},
+	})
+}
+
+// unmapsToIPv4 returns true if ip2x looks up a in the IPv4 table (i.e., it is
+// IPv4-mapped, 6to4, or Teredo).
+func unmapsToIPv4(a netip.Addr) bool {
+	b := a.As16()
+	return a.Is4In6() || (b[0] == 0x20 && b[1] == 0x02) || (b[0] == 0x20 && b[1] == 0x01 && b[2] == 0 && b[3] == 0)
+}
+
+// resolvePath resolves name relative to base unless it is absolute.
+func resolvePath(base, name string) string {
+	if filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(base, name)
+}
+
+func le32(b []byte) uint32 {
+	return uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16 | uint32(b[3])<<24
+}
+
+func le32addr(b []byte) netip.Addr {
+	v := le32(b)
+	return netip.AddrFrom4([4]byte{byte(v >> 24), byte(v >> 16), byte(v >> 8), byte(v)})
+}
+
+func le128addr(b []byte) netip.Addr {
+	var a [16]byte
+	for i := range a {
+		a[i] = b[15-i]
+	}
+	return netip.AddrFrom16(a)
+}
@@ -0,0 +1,174 @@
+package codegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// jsonObject is a JSON object which preserves key order.
+type jsonObject []jsonMember
+
+type jsonMember struct {
+	Key   string
+	Value any
+}
+
+func (o jsonObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, m := range o {
+		if i != 0 {
+			buf.WriteByte(',')
+		}
+		if b, err := json.Marshal(m.Key); err != nil {
+			return nil, err
+		} else {
+			buf.Write(b)
+		}
+		buf.WriteByte(':')
+		if b, err := json.Marshal(m.Value); err != nil {
+			return nil, err
+		} else {
+			buf.Write(b)
+		}
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// layoutColumns contains the columns which ip2x leaves out for older files of
+// a product type with a different layout (see New in db.go), so they can't be
+// required.
+var layoutColumns = map[string]map[uint8][]string{
+	"IP2Location": {26: {"as_domain", "as_usage_type", "as_cidr"}},
+}
+
+// recordSchema returns the JSON Schema for the output of Record.MarshalJSON for
+// a product type.
+func (spec *spec) recordSchema(prod *specProduct, t uint8) jsonObject {
+	var (
+		props    jsonObject
+		required []string
+	)
+	for _, col := range prod.ProductColumn {
+		if col.DatabaseColumn[t] != 0 {
+			prop := jsonObject{{"type", columnTypes[col.Type].JSONType}}
+			if len(col.Field.GoDoc) != 0 {
+				prop = append(prop, jsonMember{"description", strings.Join(col.Field.GoDoc, "\n")})
+			}
+			props = append(props, jsonMember{col.Field.ColumnName, prop})
+			if !contains(layoutColumns[prod.ProductName][t], col.Field.ColumnName) {
+				required = append(required, col.Field.ColumnName)
+			}
+		}
+	}
+	if required == nil {
+		required = []string{}
+		props = jsonObject{}
+	}
+	return jsonObject{
+		{"title", fmt.Sprintf("%s %s%d", prod.ProductName, prod.ProductPrefix, t)},
+		{"description", strings.Join(prod.GoDoc, "\n")},
+		{"type", "object"},
+		{"properties", props},
+		{"required", required},
+		{"additionalProperties", false},
+	}
+}
+
+// generateJSONSchema writes a JSON Schema for each product type to dir.
+func (spec *spec) generateJSONSchema(dir string) error {
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return err
+	}
+	for _, prod := range spec.product {
+		for t := uint8(1); t <= prod.DatabaseTypeMax; t++ {
+			name := fmt.Sprintf("%s%d.schema.json", prod.ProductPrefix, t)
+			schema := append(jsonObject{
+				{"$schema", "https://json-schema.org/draft/2020-12/schema"},
+				{"$id", name},
+			}, spec.recordSchema(prod, t)...)
+			if err := writeJSON(filepath.Join(dir, name), schema); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// generateOpenAPI writes an OpenAPI document containing a schema component
+// for each product type to name.
+func (spec *spec) generateOpenAPI(name string) error {
+	var schemas jsonObject
+	for _, prod := range spec.product {
+		for t := uint8(1); t <= prod.DatabaseTypeMax; t++ {
+			schemas = append(schemas, jsonMember{fmt.Sprintf("%s%dRecord", prod.ProductPrefix, t), spec.recordSchema(prod, t)})
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0777); err != nil {
+		return err
+	}
+	return writeJSON(name, jsonObject{
+		{"openapi", "3.1.0"},
+		{"info", jsonObject{
+			{"title", "ip2x records"},
+			{"version", "1.0.0"},
+		}},
+		{"paths", jsonObject{}},
+		{"components", jsonObject{
+			{"schemas", schemas},
+		}},
+	})
+}
+
+// generateProto writes a protobuf message containing every field, numbered by
+// the DBField value, to name.
+func (spec *spec) generateProto(name string, fieldType map[*specField]string) error {
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by codegen; DO NOT EDIT.\n\n")
+	buf.WriteString("syntax = \"proto3\";\n\n")
+	buf.WriteString("package ip2x;\n\n")
+	buf.WriteString("// Record contains the fields of an IP2Location database record. Only the\n")
+	buf.WriteString("// fields present in the database type are set.\n")
+	buf.WriteString("message Record {\n")
+	for i, fld := range spec.field {
+		typ, ok := fieldType[fld]
+		if !ok {
+			continue
+		}
+		if i != 0 {
+			buf.WriteString("\n")
+		}
+		for _, line := range fld.GoDoc {
+			buf.WriteString(strings.TrimRight("  // "+line, " "))
+			buf.WriteString("\n")
+		}
+		fmt.Fprintf(&buf, "  optional %s %s = %d;\n", columnTypes[typ].ProtoType, fld.ColumnName, fld.FieldNum)
+	}
+	buf.WriteString("}\n")
+	if err := os.MkdirAll(filepath.Dir(name), 0777); err != nil {
+		return err
+	}
+	return os.WriteFile(name, buf.Bytes(), 0666)
+}
+
+func writeJSON(name string, v any) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(name, append(b, '\n'), 0666)
+}
+
+func contains(s []string, v string) bool {
+	for _, x := range s {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
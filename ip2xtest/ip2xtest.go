@@ -0,0 +1,274 @@
+// Package ip2xtest provides in-memory databases and assertions for testing code
+// which uses ip2x.
+//
+// The databases are real IP2Location binary databases built with
+// [ip2x.BINWriter], so lookups go through the same code as they would with a
+// database file.
+package ip2xtest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pg9182/ip2x"
+)
+
+// Builder builds a database. The first error encountered is returned by
+// [Builder.Bytes], or causes [Builder.Build] to panic.
+type Builder struct {
+	w   *ip2x.BINWriter
+	err error
+}
+
+// New creates a new builder for the specified database product and type.
+func New(p ip2x.DBProduct, t ip2x.DBType) *Builder {
+	w, err := ip2x.NewBINWriter(p, t)
+	return &Builder{w: w, err: err}
+}
+
+// Date sets the database date, which defaults to the current one.
+func (b *Builder) Date(d time.Time) *Builder {
+	if b.err == nil {
+		b.w.SetDate(d)
+	}
+	return b
+}
+
+// Index sets whether to write index tables, which is true by default.
+func (b *Builder) Index(index bool) *Builder {
+	if b.err == nil {
+		b.w.SetIndex(index)
+	}
+	return b
+}
+
+// Add adds a row with the specified values for the addresses in s, which is an
+// IPv4 or IPv6 CIDR prefix (e.g., 1.2.3.0/24), single address (e.g., 1.2.3.4),
+// or inclusive range (e.g., 1.2.3.4-1.2.3.10). See [ip2x.BINWriter.Add] for
+// the supported value types. Addresses not covered by a row will have empty
+// values. Rows must not overlap.
+func (b *Builder) Add(s string, values map[ip2x.DBField]any) *Builder {
+	if b.err == nil {
+		if r, err := parseRange(s); err != nil {
+			b.err = err
+		} else if err := b.w.Add(r, values); err != nil {
+			b.err = fmt.Errorf("add %s: %w", s, err)
+		}
+	}
+	return b
+}
+
+// Bytes returns the database file.
+func (b *Builder) Bytes() ([]byte, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	return b.w.Bytes()
+}
+
+// Build opens the database, panicking if an error occurred while building it.
+func (b *Builder) Build() *ip2x.DB {
+	buf, err := b.Bytes()
+	if err != nil {
+		panic("ip2xtest: " + err.Error())
+	}
+	db, err := ip2x.New(bytes.NewReader(buf))
+	if err != nil {
+		panic("ip2xtest: " + err.Error())
+	}
+	return db
+}
+
+// parseRange parses a prefix, address, or inclusive range.
+func parseRange(s string) (ip2x.Range, error) {
+	if strings.Contains(s, "/") {
+		p, err := netip.ParsePrefix(s)
+		if err != nil {
+			return ip2x.Range{}, err
+		}
+		if p.Masked() != p {
+			return ip2x.Range{}, fmt.Errorf("prefix %s has host bits set", s)
+		}
+		return inclusiveRange(s, p.Addr(), lastAddr(p))
+	}
+	from, to, ok := strings.Cut(s, "-")
+	if !ok {
+		to = from
+	}
+	a, err := netip.ParseAddr(strings.TrimSpace(from))
+	if err != nil {
+		return ip2x.Range{}, err
+	}
+	z, err := netip.ParseAddr(strings.TrimSpace(to))
+	if err != nil {
+		return ip2x.Range{}, err
+	}
+	return inclusiveRange(s, a, z)
+}
+
+// lastAddr returns the last address in p.
+func lastAddr(p netip.Prefix) netip.Addr {
+	b := p.Addr().AsSlice()
+	for i := p.Bits(); i < len(b)*8; i++ {
+		b[i/8] |= 0x80 >> (i % 8)
+	}
+	a, _ := netip.AddrFromSlice(b)
+	return a
+}
+
+// inclusiveRange converts an inclusive range into an [ip2x.Range].
+func inclusiveRange(s string, from, to netip.Addr) (ip2x.Range, error) {
+	from, to = from.Unmap(), to.Unmap()
+	if from.Is4() != to.Is4() || to.Less(from) {
+		return ip2x.Range{}, fmt.Errorf("invalid range %s", s)
+	}
+	if to = to.Next(); !to.IsValid() {
+		return ip2x.Range{}, fmt.Errorf("range %s includes the last address, which cannot be stored in the database", s)
+	}
+	return ip2x.Range{From: from, To: to}, nil
+}
+
+// AssertRecord fails the test if the record for ip is not found or does not
+// have the specified field values. A nil value matches an empty one. Other
+// fields are not checked.
+func AssertRecord(tb testing.TB, db ip2x.Lookuper, ip string, want map[ip2x.DBField]any) {
+	tb.Helper()
+	r, ok := lookup(tb, db, ip)
+	if !ok {
+		return
+	}
+	if !r.IsValid() {
+		tb.Errorf("lookup %s: record not found", ip)
+		return
+	}
+	for f, v := range want {
+		if err := Match(r, f, v); err != nil {
+			tb.Errorf("lookup %s: %v", ip, err)
+		}
+	}
+}
+
+// AssertNotFound fails the test if a record is found for ip.
+func AssertNotFound(tb testing.TB, db ip2x.Lookuper, ip string) {
+	tb.Helper()
+	if r, ok := lookup(tb, db, ip); ok && r.IsValid() {
+		tb.Errorf("lookup %s: expected no record, got %s", ip, r)
+	}
+}
+
+func lookup(tb testing.TB, db ip2x.Lookuper, ip string) (ip2x.Record, bool) {
+	tb.Helper()
+	a, err := netip.ParseAddr(ip)
+	if err != nil {
+		tb.Errorf("lookup %s: %v", ip, err)
+		return ip2x.Record{}, false
+	}
+	r, err := db.Lookup(a)
+	if err != nil {
+		tb.Errorf("lookup %s: %v", ip, err)
+		return ip2x.Record{}, false
+	}
+	return r, true
+}
+
+// Match checks whether the value of f in r is equal to want, which may be any
+// value accepted by [Builder.Add].
+func Match(r ip2x.Record, f ip2x.DBField, want any) error {
+	got := r.Get(f)
+	if got == nil {
+		return fmt.Errorf("%s: field not in database", f)
+	}
+	exp, err := convert(f.Kind(), want)
+	if err != nil {
+		return fmt.Errorf("%s: %w", f, err)
+	}
+	if x, ok := exp.(*big.Int); ok {
+		if x.Cmp(got.(*big.Int)) != 0 {
+			return fmt.Errorf("%s: expected %s, got %s", f, x, got)
+		}
+		return nil
+	}
+	if exp != got {
+		return fmt.Errorf("%s: expected %#v, got %#v", f, exp, got)
+	}
+	return nil
+}
+
+// convert converts v into the type returned by [ip2x.Record.Get] for k.
+func convert(k ip2x.Kind, v any) (any, error) {
+	if s, ok := v.([]byte); ok {
+		v = string(s)
+	}
+	if k == ip2x.KindString {
+		switch v := v.(type) {
+		case nil:
+			return "", nil
+		case string:
+			return v, nil
+		}
+		return nil, errors.New("expected string")
+	}
+	if v == nil {
+		v = 0
+	}
+	var n *big.Float
+	switch x := v.(type) {
+	case *big.Int:
+		n = new(big.Float).SetInt(x)
+	default:
+		switch rv := reflect.ValueOf(v); rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n = new(big.Float).SetInt64(rv.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			n = new(big.Float).SetUint64(rv.Uint())
+		case reflect.Float32, reflect.Float64:
+			f := rv.Float()
+			switch k {
+			case ip2x.KindFloat32:
+				return float32(f), nil
+			case ip2x.KindFloat64:
+				return f, nil
+			}
+			if f != f {
+				return nil, errors.New("expected integer")
+			}
+			n = big.NewFloat(f)
+		default:
+			return nil, errors.New("expected number")
+		}
+	}
+	switch k {
+	case ip2x.KindFloat32:
+		f, _ := n.Float32()
+		return f, nil
+	case ip2x.KindFloat64:
+		f, _ := n.Float64()
+		return f, nil
+	}
+	i, acc := n.Int(nil)
+	if acc != big.Exact {
+		return nil, errors.New("expected integer")
+	}
+	switch k {
+	case ip2x.KindUint32:
+		if i.Sign() < 0 || !i.IsUint64() || i.Uint64() > 1<<32-1 {
+			return nil, errors.New("value out of range for uint32")
+		}
+		return uint32(i.Uint64()), nil
+	case ip2x.KindInt32:
+		if !i.IsInt64() || i.Int64() < -1<<31 || i.Int64() > 1<<31-1 {
+			return nil, errors.New("value out of range for int32")
+		}
+		return int32(i.Int64()), nil
+	case ip2x.KindUint128:
+		return i, nil
+	}
+	return nil, errors.New("unsupported field type " + k.String())
+}
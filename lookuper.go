@@ -0,0 +1,281 @@
+package ip2x
+
+import (
+	"bytes"
+	"container/list"
+	"errors"
+	"net/netip"
+	"sort"
+	"sync"
+)
+
+// Lookuper looks up records for IP addresses. It is implemented by [*DB] and
+// [*MMDB], and by decorators like [Cache], [RangeCache], [Fallback], and
+// [Override], which can be combined.
+type Lookuper interface {
+	// Lookup looks up a. If a is not found, an empty record and nil error is
+	// returned.
+	Lookup(a netip.Addr) (Record, error)
+
+	// Info returns the database product and type of the records.
+	Info() (DBProduct, DBType)
+
+	// Has returns true if records may contain f.
+	Has(f DBField) bool
+
+	// HasIPv4 returns true if IPv4 addresses may be found.
+	HasIPv4() bool
+
+	// HasIPv6 returns true if IPv6 addresses may be found.
+	HasIPv6() bool
+}
+
+var (
+	_ Lookuper = (*DB)(nil)
+	_ Lookuper = (*MMDB)(nil)
+	_ Lookuper = (*Cache)(nil)
+	_ Lookuper = (*RangeCache)(nil)
+	_ Lookuper = Fallback(nil)
+	_ Lookuper = (*Override)(nil)
+)
+
+// Cache is a [Lookuper] which caches the records for the most recently used
+// addresses. It is safe for concurrent use. Errors are not cached.
+type Cache struct {
+	l    Lookuper
+	size int
+	mu   sync.Mutex
+	ll   *list.List // of *cacheEntry, most recently used first
+	m    map[netip.Addr]*list.Element
+}
+
+type cacheEntry struct {
+	a netip.Addr
+	r Record
+}
+
+// NewCache caches up to size records from l.
+func NewCache(l Lookuper, size int) *Cache {
+	if size < 1 {
+		size = 1
+	}
+	return &Cache{
+		l:    l,
+		size: size,
+		ll:   list.New(),
+		m:    make(map[netip.Addr]*list.Element, size),
+	}
+}
+
+// Lookup looks up a, returning a cached record if possible.
+func (c *Cache) Lookup(a netip.Addr) (Record, error) {
+	c.mu.Lock()
+	if e, ok := c.m[a]; ok {
+		c.ll.MoveToFront(e)
+		r := e.Value.(*cacheEntry).r
+		c.mu.Unlock()
+		return r, nil
+	}
+	c.mu.Unlock()
+
+	r, err := c.l.Lookup(a)
+	if err != nil {
+		return r, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.m[a]; ok {
+		c.ll.MoveToFront(e) // added concurrently
+		return r, nil
+	}
+	if c.ll.Len() >= c.size {
+		e := c.ll.Back()
+		c.ll.Remove(e)
+		delete(c.m, e.Value.(*cacheEntry).a)
+	}
+	c.m[a] = c.ll.PushFront(&cacheEntry{a, r})
+	return r, nil
+}
+
+// Purge removes all cached records.
+func (c *Cache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ll.Init()
+	c.m = make(map[netip.Addr]*list.Element, c.size)
+}
+
+// Len returns the number of cached records.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+func (c *Cache) Info() (DBProduct, DBType) { return c.l.Info() }
+func (c *Cache) Has(f DBField) bool        { return c.l.Has(f) }
+func (c *Cache) HasIPv4() bool             { return c.l.HasIPv4() }
+func (c *Cache) HasIPv6() bool             { return c.l.HasIPv6() }
+
+// Fallback is a [Lookuper] which tries each [Lookuper] in order (e.g., DB26,
+// then DB1) until a record is found. The product and type are the ones of the
+// first one.
+type Fallback []Lookuper
+
+// Lookup looks up a. If an error occurs, the next one is tried, and the first
+// error is returned if the record is not found in any of them.
+func (fb Fallback) Lookup(a netip.Addr) (Record, error) {
+	var first error
+	for _, l := range fb {
+		r, err := l.Lookup(a)
+		if err != nil {
+			if first == nil {
+				first = err
+			}
+			continue
+		}
+		if r.IsValid() {
+			return r, nil
+		}
+	}
+	return Record{}, first
+}
+
+func (fb Fallback) Info() (DBProduct, DBType) {
+	if len(fb) == 0 {
+		return 0, 0
+	}
+	return fb[0].Info()
+}
+
+func (fb Fallback) Has(f DBField) bool {
+	for _, l := range fb {
+		if l.Has(f) {
+			return true
+		}
+	}
+	return false
+}
+
+func (fb Fallback) HasIPv4() bool {
+	for _, l := range fb {
+		if l.HasIPv4() {
+			return true
+		}
+	}
+	return false
+}
+
+func (fb Fallback) HasIPv6() bool {
+	for _, l := range fb {
+		if l.HasIPv6() {
+			return true
+		}
+	}
+	return false
+}
+
+// Override is a [Lookuper] which returns static values for some prefixes
+// (e.g., internal networks), and looks up other addresses in another
+// [Lookuper]. IPv4-mapped, 6to4, and Teredo addresses are unmapped like
+// [DB.Lookup].
+type Override struct {
+	l  Lookuper
+	db *DB
+	rs []overrideRange // sorted by family then address
+}
+
+type overrideRange struct {
+	v6       bool
+	from, to uint128
+}
+
+// NewOverride creates an [Override] returning records with the specified
+// values for addresses in the prefixes, which must not overlap. The records
+// have the same product and type as l.
+func NewOverride(l Lookuper, values map[netip.Prefix]map[DBField]any) (*Override, error) {
+	w, err := NewBINWriter(l.Info())
+	if err != nil {
+		return nil, err
+	}
+	o := &Override{l: l}
+	for p, v := range values {
+		if p.Addr().Is4In6() {
+			p = netip.PrefixFrom(p.Addr().Unmap(), p.Bits()-96) // invalid if shorter than /96
+		} else if overrideUnmapped(p) {
+			return nil, errors.New("override " + p.String() + ": 6to4 and teredo addresses are looked up as ipv4")
+		}
+		r, ok := prefixRange(p)
+		if !ok {
+			return nil, errors.New("invalid prefix " + p.String())
+		}
+		if err := w.Add(r, v); err != nil {
+			return nil, errors.New("override " + p.String() + ": " + err.Error())
+		}
+		x := overrideRange{
+			v6:   !r.From.Is4(),
+			from: as_ip6_uint128(r.From),
+			to:   as_ip6_uint128(r.To),
+		}
+		if !x.v6 {
+			x.from.lo &= 0xffffffff
+			x.to.lo &= 0xffffffff
+		}
+		o.rs = append(o.rs, x)
+	}
+	sort.Slice(o.rs, func(i, j int) bool {
+		if a, b := o.rs[i], o.rs[j]; a.v6 != b.v6 {
+			return b.v6
+		} else {
+			return a.from.Less(b.from)
+		}
+	})
+	buf, err := w.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	if o.db, err = New(bytes.NewReader(buf)); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// overrideUnmapped returns true if p is inside the 6to4 or Teredo prefixes,
+// which are unmapped before lookups.
+func overrideUnmapped(p netip.Prefix) bool {
+	for _, x := range [...]netip.Prefix{
+		netip.PrefixFrom(netip.AddrFrom16([16]byte{0x20, 0x02}), 16),
+		netip.PrefixFrom(netip.AddrFrom16([16]byte{0x20, 0x01}), 32),
+	} {
+		if p.Bits() >= x.Bits() && x.Contains(p.Addr()) {
+			return true
+		}
+	}
+	return false
+}
+
+// Lookup looks up a, returning the override record if a is in one of the
+// prefixes.
+func (o *Override) Lookup(a netip.Addr) (Record, error) {
+	if a.IsValid() {
+		ip, iplen := unmap(as_ip6_uint128(a))
+		v6 := iplen != 4
+		i := sort.Search(len(o.rs), func(i int) bool {
+			if x := o.rs[i]; x.v6 != v6 {
+				return x.v6
+			} else {
+				return ip.Less(x.to)
+			}
+		})
+		if i < len(o.rs) && o.rs[i].v6 == v6 && !ip.Less(o.rs[i].from) {
+			return o.db.Lookup(a)
+		}
+	}
+	return o.l.Lookup(a)
+}
+
+func (o *Override) Info() (DBProduct, DBType) { return o.l.Info() }
+func (o *Override) Has(f DBField) bool        { return o.l.Has(f) }
+func (o *Override) HasIPv4() bool             { return o.l.HasIPv4() || o.db.HasIPv4() }
+func (o *Override) HasIPv6() bool             { return o.l.HasIPv6() || o.db.HasIPv6() }
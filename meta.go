@@ -0,0 +1,126 @@
+package ip2x
+
+import "sort"
+
+// Kind is the type of a field's value.
+type Kind uint8
+
+// Field kinds.
+const (
+	KindInvalid Kind = iota
+	KindString       // string
+	KindFloat32      // float32
+	KindUint32       // uint32
+	KindInt32        // int32
+	KindFloat64      // float64
+	KindUint128      // *big.Int
+)
+
+// String returns the name of the kind.
+func (k Kind) String() string {
+	switch k {
+	case KindString:
+		return "string"
+	case KindFloat32:
+		return "float32"
+	case KindUint32:
+		return "uint32"
+	case KindInt32:
+		return "int32"
+	case KindFloat64:
+		return "float64"
+	case KindUint128:
+		return "uint128"
+	}
+	return "invalid"
+}
+
+// kind returns the Kind for a dbtype.
+func kind(t uint8) Kind {
+	switch t {
+	case dbtype_str:
+		return KindString
+	case dbtype_f32:
+		return KindFloat32
+	case dbtype_u32:
+		return KindUint32
+	case dbtype_i32:
+		return KindInt32
+	case dbtype_f64:
+		return KindFloat64
+	case dbtype_u128:
+		return KindUint128
+	}
+	return KindInvalid
+}
+
+// Kind returns the type of the value returned by [Record.Get] for f, or
+// [KindInvalid] if f is unknown.
+func (f DBField) Kind() Kind {
+	if t, ok := f.fieldType(); ok {
+		return kind(t)
+	}
+	return KindInvalid
+}
+
+// Description returns the documentation for f as plain text.
+func (f DBField) Description() string {
+	return f.description()
+}
+
+// Products returns the database types containing f for each product,
+// including ones added by [Register].
+func (f DBField) Products() map[DBProduct][]DBType {
+	m := map[DBProduct][]DBType{}
+	for p := DBProduct(1); p <= dbProductMax; p++ {
+		for t := DBType(1); t <= dbTypeMax; t++ {
+			if dbinfo(p, t).Field(f).IsValid() {
+				m[p] = append(m[p], t)
+			}
+		}
+	}
+next:
+	for _, s := range registeredSchemas(0) {
+		if s.Field(f).IsValid() {
+			_, p, t := s.Info()
+			for _, x := range m[p] {
+				if x == t {
+					continue next
+				}
+			}
+			m[p] = append(m[p], t)
+			sort.Slice(m[p], func(i, j int) bool { return m[p][i] < m[p][j] })
+		}
+	}
+	return m
+}
+
+// MinimalType returns the database type of p containing all of fields with the
+// fewest columns, preferring lower types if there are multiple. Types added by
+// [Register] are also considered. If no type contains all fields, false is
+// returned.
+func MinimalType(p DBProduct, fields ...DBField) (DBType, bool) {
+	var (
+		mt DBType
+		mc uint8
+	)
+	ss := make([]*dbS, 0, dbTypeMax)
+	for t := DBType(1); t <= dbTypeMax; t++ {
+		ss = append(ss, dbinfo(p, t))
+	}
+	ss = append(ss, registeredSchemas(p)...)
+next:
+	for _, s := range ss {
+		c, _, t := s.Info()
+		if c == 0 || (mt != 0 && (c > mc || (c == mc && t >= mt))) {
+			continue
+		}
+		for _, f := range fields {
+			if !s.Field(f).IsValid() {
+				continue next
+			}
+		}
+		mt, mc = t, c
+	}
+	return mt, mt != 0
+}
@@ -0,0 +1,694 @@
+package ip2x
+
+import (
+	"errors"
+	"io"
+	"math"
+	"math/bits"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// mmdbMetadataMarker is the start of the metadata at the end of an MMDB file.
+const mmdbMetadataMarker = "\xAB\xCD\xEFMaxMind.com"
+
+// MMDB data types.
+const (
+	mmdbExtended = iota
+	mmdbPointer
+	mmdbString
+	mmdbDouble
+	mmdbBytes
+	mmdbUint16
+	mmdbUint32
+	mmdbMap
+	mmdbInt32
+	mmdbUint64
+	mmdbUint128
+	mmdbArray
+	mmdbContainer
+	mmdbEndMarker
+	mmdbBool
+	mmdbFloat
+)
+
+// DefaultMMDBMapping returns the default MMDB keys for fields. Where the values
+// are compatible, these are the same as the GeoIP2 and GeoLite2 databases
+// (e.g., country.iso_code and location.latitude), and the column name
+// otherwise.
+func DefaultMMDBMapping(fields ...DBField) map[DBField]string {
+	m := make(map[DBField]string, len(fields))
+	for _, f := range fields {
+		switch f {
+		case CountryCode:
+			m[f] = "country.iso_code"
+		case CountryName:
+			m[f] = "country.names.en"
+		case Region:
+			m[f] = "subdivisions.0.names.en"
+		case City:
+			m[f] = "city.names.en"
+		case Latitude:
+			m[f] = "location.latitude"
+		case Longitude:
+			m[f] = "location.longitude"
+		case Zipcode:
+			m[f] = "postal.code"
+		case AS:
+			m[f] = "autonomous_system_organization"
+		default:
+			m[f] = f.String()
+		}
+	}
+	return m
+}
+
+// MMDBWriter converts a database into a MaxMind DB (MMDB) file.
+//
+// The MMDB search tree is IPv6, with IPv4 addresses in ::/96 like MaxMind's
+// databases. IPv4-mapped (::ffff:0:0/96) and 6to4 (2002::/16) addresses are
+// aliased to the IPv4 addresses they would be looked up as by [DB.Lookup].
+// Teredo (2001::/32) addresses are not found, since the IPv4 address
+// [DB.Lookup] uses is in the last 32 bits, which can't be aliased in the
+// search tree (and MaxMind's databases alias the Teredo server address
+// instead).
+//
+// Strings are converted to UTF-8 strings, 32-bit floats are converted to
+// doubles, and other numbers are converted to the corresponding MMDB type.
+// Empty strings, and strings containing only "-" (which IP2Location uses for
+// missing values) are omitted. Addresses without any non-empty strings (e.g.,
+// reserved ranges, which have a "-" country and a zero location) are not found.
+// If no string fields are mapped, addresses where all numbers are zero are not
+// found instead.
+type MMDBWriter struct {
+	// Fields maps fields to MMDB keys. Nested maps are separated by dots, and
+	// integer components are array indexes (e.g., subdivisions.0.names.en),
+	// which must start from 0 without gaps. Array elements without a value are
+	// written as empty strings, maps, or arrays so the indexes are kept. If
+	// nil, all fields in the database are written using [DefaultMMDBMapping].
+	Fields map[DBField]string
+
+	// DatabaseType is the MMDB database type. If empty, it is the product and
+	// type (e.g., IP2Location-DB11).
+	DatabaseType string
+
+	// Description is the English MMDB description. If empty, it is the
+	// database's string representation.
+	Description string
+}
+
+// Write converts db to dst. The build time is the database date.
+func (w *MMDBWriter) Write(dst io.Writer, db *DB) error {
+	if db.s == nil {
+		return errors.New("database does not have a layout")
+	}
+	m := w.Fields
+	if m == nil {
+		var fs []DBField
+		db.EachField(func(f DBField) bool {
+			fs = append(fs, f)
+			return true
+		})
+		m = DefaultMMDBMapping(fs...)
+	}
+	root, fields, err := parseMMDBMapping(m)
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		if !db.Has(f) {
+			return errors.New("database does not have field " + f.String())
+		}
+	}
+
+	var (
+		t    = &mmdbTree{nodes: make([][2]mmdbRecord, 1)}
+		e    = &mmdbEncoder{strs: map[string]uint32{}}
+		recs = map[string]uint32{} // [values]offset
+		v4   []mmdbRange
+		vals = make([]mmdbValue, len(fields))
+		buf  [getbufSize]byte
+		key  []byte
+	)
+	var strs bool // whether found depends on strings
+	for _, f := range fields {
+		strs = strs || db.s.Field(f).Type() == dbtype_str
+	}
+	emit := func(rg Range, r Record) error {
+		var found bool
+		key = key[:0]
+		for i, f := range fields {
+			dt, fd, err := r.getb(f, buf[:])
+			if dt == nil && err != nil {
+				return errors.New("read " + f.String() + ": " + err.Error())
+			}
+			vals[i].typ = fd.Type()
+			vals[i].off = len(key) + 1
+			key = append(key, byte(len(dt)))
+			key = append(key, dt...)
+			if fd.Type() == dbtype_str {
+				vals[i].ok = len(dt) != 0 && string(dt) != "-"
+				found = found || vals[i].ok
+			} else {
+				vals[i].ok = true
+				found = found || (!strs && strings.Trim(string(dt), "\x00") != "")
+			}
+		}
+		if !found {
+			return nil
+		}
+		for i := range vals {
+			vals[i].b = key[vals[i].off : vals[i].off+int(key[vals[i].off-1])]
+		}
+		off, ok := recs[string(key)]
+		if !ok {
+			off = uint32(len(e.b))
+			e.appendValue(root, vals)
+			recs[string(key)] = off
+		}
+		x := mmdbRange{nativeAddr(rg.From), nativeAddr(rg.To), mmdbRecord{mmdbRecordData, off}}
+		if rg.From.Is4() {
+			v4 = append(v4, x)
+		} else {
+			eachRangePrefix(x.from, x.to, 128, func(ip uint128, n int) {
+				t.insert(ip, n, x.rec)
+			})
+		}
+		return nil
+	}
+
+	c := newCoalescer(db, fields)
+	for _, v6 := range []bool{false, true} {
+		var werr error
+		add := func(rg Range, r Record, ok bool) {
+			if ok && werr == nil {
+				werr = emit(rg, r)
+			}
+		}
+		if _, err := db.each(v6, func(rg Range, r Record) bool {
+			add(c.add(rg, r))
+			return werr == nil && c.err == nil
+		}); err != nil {
+			return err
+		}
+		if add(c.flush()); c.err != nil {
+			return c.err
+		}
+		if werr != nil {
+			return werr
+		}
+	}
+
+	// IPv4 is in ::/96, replacing anything from the IPv6 table
+	t.insert(uint128{}, 96, mmdbRecord{})
+	for _, x := range v4 {
+		eachRangePrefix(x.from, x.to, 32, func(ip uint128, n int) {
+			t.insert(ip, 96+n, x.rec)
+		})
+	}
+
+	// alias the addresses unmapped to IPv4 by DB.Lookup
+	ip4 := t.get(uint128{}, 96)
+	t.insert(uint128{lo: 0xffff << 32}, 96, ip4)              // ::ffff:0:0/96
+	t.insert(uint128{hi: 0x2002 << 48}, 16, ip4)              // 2002::/16 (followed by the IPv4 address)
+	t.insert(uint128{hi: 0x20010000 << 32}, 32, mmdbRecord{}) // 2001::/32 (Teredo)
+
+	return w.write(dst, db, t, e.b)
+}
+
+// write writes the MMDB file.
+func (w *MMDBWriter) write(dst io.Writer, db *DB, t *mmdbTree, data []byte) error {
+	typ := w.DatabaseType
+	if typ == "" {
+		typ = db.prcode.String() + "-" + db.prcode.typePrefix() + db.dbtype.String()
+	}
+	desc := w.Description
+	if desc == "" {
+		desc = db.String()
+	}
+
+	order, index := t.order()
+	n := uint64(len(order))
+	size := n + 16 + uint64(len(data))
+
+	var rs int
+	switch {
+	case size < 1<<24:
+		rs = 24
+	case size < 1<<28:
+		rs = 28
+	case size < 1<<32:
+		rs = 32
+	default:
+		return errors.New("database is too large")
+	}
+
+	value := func(r mmdbRecord) uint32 {
+		switch r.kind {
+		case mmdbRecordNode:
+			return index[r.v]
+		case mmdbRecordData:
+			return uint32(n) + 16 + r.v
+		}
+		return uint32(n)
+	}
+	b := make([]byte, 0, int(n)*rs/4+16+len(data)+256)
+	for _, x := range order {
+		l, r := value(t.nodes[x][0]), value(t.nodes[x][1])
+		switch rs {
+		case 24:
+			b = append(b, byte(l>>16), byte(l>>8), byte(l), byte(r>>16), byte(r>>8), byte(r))
+		case 28:
+			b = append(b, byte(l>>16), byte(l>>8), byte(l), byte(l>>24<<4)|byte(r>>24&0xF), byte(r>>16), byte(r>>8), byte(r))
+		case 32:
+			b = append(b, byte(l>>24), byte(l>>16), byte(l>>8), byte(l), byte(r>>24), byte(r>>16), byte(r>>8), byte(r))
+		}
+	}
+	b = append(b, make([]byte, 16)...)
+	b = append(b, data...)
+	b = append(b, mmdbMetadataMarker...)
+
+	m := &mmdbEncoder{b: b}
+	m.appendCtrl(mmdbMap, 9)
+	m.appendString([]byte("binary_format_major_version"))
+	m.appendUint(mmdbUint16, uint128{lo: 2})
+	m.appendString([]byte("binary_format_minor_version"))
+	m.appendUint(mmdbUint16, uint128{})
+	m.appendString([]byte("build_epoch"))
+	m.appendUint(mmdbUint64, uint128{lo: uint64(time.Date(2000+int(db.dbyear), time.Month(db.dbmonth), int(db.dbday), 0, 0, 0, 0, time.UTC).Unix())})
+	m.appendString([]byte("database_type"))
+	m.appendString([]byte(typ))
+	m.appendString([]byte("description"))
+	m.appendCtrl(mmdbMap, 1)
+	m.appendString([]byte("en"))
+	m.appendString([]byte(desc))
+	m.appendString([]byte("ip_version"))
+	m.appendUint(mmdbUint16, uint128{lo: 6})
+	m.appendString([]byte("languages"))
+	m.appendCtrl(mmdbArray, 1)
+	m.appendString([]byte("en"))
+	m.appendString([]byte("node_count"))
+	m.appendUint(mmdbUint32, uint128{lo: n})
+	m.appendString([]byte("record_size"))
+	m.appendUint(mmdbUint16, uint128{lo: uint64(rs)})
+
+	_, err := dst.Write(m.b)
+	return err
+}
+
+// mmdbKey is a node in the tree of MMDB keys for a field mapping.
+type mmdbKey struct {
+	name  string     // map key
+	index int        // array index
+	field int        // index of the field for values (or -1)
+	sub   []*mmdbKey // sorted by name or index
+	array bool       // whether sub are array elements
+}
+
+// parseMMDBMapping parses a field mapping, returning the root map and the
+// fields in the order they are indexed by the keys.
+func parseMMDBMapping(m map[DBField]string) (*mmdbKey, []DBField, error) {
+	fields := make([]DBField, 0, len(m))
+	for f := range m {
+		fields = append(fields, f)
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i] < fields[j]
+	})
+	root := &mmdbKey{field: -1}
+	for i, f := range fields {
+		k := root
+		for _, s := range strings.Split(m[f], ".") {
+			if s == "" {
+				return nil, nil, errors.New("invalid mmdb key " + strconv.Quote(m[f]) + " for " + f.String())
+			}
+			idx, err := strconv.Atoi(s)
+			array := err == nil && idx >= 0
+			if k.field != -1 || (len(k.sub) != 0 && k.array != array) || (k == root && array) {
+				return nil, nil, errors.New("conflicting mmdb key " + strconv.Quote(m[f]) + " for " + f.String())
+			}
+			k.array = array
+			var c *mmdbKey
+			for _, x := range k.sub {
+				if (array && x.index == idx) || (!array && x.name == s) {
+					c = x
+					break
+				}
+			}
+			if c == nil {
+				c = &mmdbKey{name: s, index: idx, field: -1}
+				k.sub = append(k.sub, c)
+			}
+			k = c
+		}
+		if k.field != -1 || len(k.sub) != 0 {
+			return nil, nil, errors.New("conflicting mmdb key " + strconv.Quote(m[f]) + " for " + f.String())
+		}
+		k.field = i
+	}
+	var sortKeys func(k *mmdbKey, path string) error
+	sortKeys = func(k *mmdbKey, path string) error {
+		sort.Slice(k.sub, func(i, j int) bool {
+			if k.array {
+				return k.sub[i].index < k.sub[j].index
+			}
+			return k.sub[i].name < k.sub[j].name
+		})
+		for i, x := range k.sub {
+			if k.array && x.index != i {
+				return errors.New("mmdb array " + strconv.Quote(path) + " is missing index " + strconv.Itoa(i))
+			}
+			p := x.name
+			if path != "" {
+				p = path + "." + p
+			}
+			if err := sortKeys(x, p); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := sortKeys(root, ""); err != nil {
+		return nil, nil, err
+	}
+	return root, fields, nil
+}
+
+// mmdbValue is the raw value of a field.
+type mmdbValue struct {
+	typ uint8
+	ok  bool // not empty
+	off int
+	b   []byte
+}
+
+// has returns whether k contains any values.
+func (k *mmdbKey) has(vals []mmdbValue) bool {
+	if k.field != -1 {
+		return vals[k.field].ok
+	}
+	for _, x := range k.sub {
+		if x.has(vals) {
+			return true
+		}
+	}
+	return false
+}
+
+// mmdbEncoder appends values to an MMDB data section.
+type mmdbEncoder struct {
+	b    []byte
+	strs map[string]uint32 // [string]offset (optional)
+}
+
+// appendValue appends the map or array for k.
+func (e *mmdbEncoder) appendValue(k *mmdbKey, vals []mmdbValue) {
+	if k.field != -1 {
+		v := vals[k.field]
+		switch v.typ {
+		case dbtype_str:
+			e.appendString(v.b)
+		case dbtype_f32:
+			// use the shortest representation of the float32 rather than the
+			// exact one (e.g., 1.1 rather than 1.100000023841858)
+			f, _ := strconv.ParseFloat(strconv.FormatFloat(float64(as_f32(as_le_u32(v.b))), 'g', -1, 32), 64)
+			e.appendDouble(f)
+		case dbtype_u32:
+			e.appendUint(mmdbUint32, as_u32_u128(as_le_u32(v.b)))
+		case dbtype_i32:
+			x := as_le_u32(v.b)
+			e.appendCtrl(mmdbInt32, 4)
+			e.b = append(e.b, byte(x>>24), byte(x>>16), byte(x>>8), byte(x))
+		case dbtype_f64:
+			e.appendDouble(as_f64(as_le_u64(v.b)))
+		case dbtype_u128:
+			e.appendUint(mmdbUint128, as_le_u128(v.b))
+		default:
+			panic("unhandled dbft")
+		}
+		return
+	}
+	if k.array {
+		// keep the indexes of later elements by padding earlier ones
+		var n int
+		for i, x := range k.sub {
+			if x.has(vals) {
+				n = i + 1
+			}
+		}
+		e.appendCtrl(mmdbArray, n)
+		for _, x := range k.sub[:n] {
+			if x.has(vals) {
+				e.appendValue(x, vals)
+			} else {
+				e.appendEmpty(x)
+			}
+		}
+		return
+	}
+	var n int
+	for _, x := range k.sub {
+		if x.has(vals) {
+			n++
+		}
+	}
+	e.appendCtrl(mmdbMap, n)
+	for _, x := range k.sub {
+		if x.has(vals) {
+			e.appendString([]byte(x.name))
+			e.appendValue(x, vals)
+		}
+	}
+}
+
+// appendEmpty appends an empty value for k, which is an empty string for
+// fields, or an empty map or array.
+func (e *mmdbEncoder) appendEmpty(k *mmdbKey) {
+	switch {
+	case k.field != -1:
+		e.appendString(nil)
+	case k.array:
+		e.appendCtrl(mmdbArray, 0)
+	default:
+		e.appendCtrl(mmdbMap, 0)
+	}
+}
+
+// appendCtrl appends a control byte for a value of type typ and size.
+func (e *mmdbEncoder) appendCtrl(typ, size int) {
+	var c [5]byte
+	n := 1
+	if typ > mmdbMap {
+		c[0], c[1], n = mmdbExtended<<5, byte(typ-mmdbMap), 2
+	} else {
+		c[0] = byte(typ << 5)
+	}
+	switch {
+	case size < 29:
+		c[0] |= byte(size)
+	case size < 29+256:
+		c[0] |= 29
+		c[n] = byte(size - 29)
+		n++
+	case size < 285+65536:
+		c[0] |= 30
+		size -= 285
+		c[n], c[n+1] = byte(size>>8), byte(size)
+		n += 2
+	default:
+		c[0] |= 31
+		size -= 65821
+		c[n], c[n+1], c[n+2] = byte(size>>16), byte(size>>8), byte(size)
+		n += 3
+	}
+	e.b = append(e.b, c[:n]...)
+}
+
+// appendString appends a string, or a pointer to it if it was already
+// appended and the pointer is shorter.
+func (e *mmdbEncoder) appendString(s []byte) {
+	if e.strs != nil {
+		if off, ok := e.strs[string(s)]; ok {
+			if p := mmdbPointerSize(off); p < len(s)+1 {
+				e.appendPointer(off)
+				return
+			}
+		} else {
+			e.strs[string(s)] = uint32(len(e.b))
+		}
+	}
+	e.appendCtrl(mmdbString, len(s))
+	e.b = append(e.b, s...)
+}
+
+// mmdbPointerSize returns the encoded size of a pointer to off.
+func mmdbPointerSize(off uint32) int {
+	switch {
+	case off < 1<<11:
+		return 2
+	case off < 1<<11+1<<19:
+		return 3
+	case off < 1<<11+1<<19+1<<27:
+		return 4
+	}
+	return 5
+}
+
+// appendPointer appends a pointer to off in the data section.
+func (e *mmdbEncoder) appendPointer(off uint32) {
+	switch mmdbPointerSize(off) {
+	case 2:
+		e.b = append(e.b, mmdbPointer<<5|0<<3|byte(off>>8), byte(off))
+	case 3:
+		off -= 1 << 11
+		e.b = append(e.b, mmdbPointer<<5|1<<3|byte(off>>16), byte(off>>8), byte(off))
+	case 4:
+		off -= 1<<11 + 1<<19
+		e.b = append(e.b, mmdbPointer<<5|2<<3|byte(off>>24), byte(off>>16), byte(off>>8), byte(off))
+	default:
+		e.b = append(e.b, mmdbPointer<<5|3<<3, byte(off>>24), byte(off>>16), byte(off>>8), byte(off))
+	}
+}
+
+// appendUint appends an unsigned integer of type typ using as few bytes as
+// possible.
+func (e *mmdbEncoder) appendUint(typ int, v uint128) {
+	b := to_be_u128(v)
+	n := 16
+	for n > 0 && b[16-n] == 0 {
+		n--
+	}
+	e.appendCtrl(typ, n)
+	e.b = append(e.b, b[16-n:]...)
+}
+
+// appendDouble appends a double.
+func (e *mmdbEncoder) appendDouble(f float64) {
+	x := math.Float64bits(f)
+	e.appendCtrl(mmdbDouble, 8)
+	e.b = append(e.b, byte(x>>56), byte(x>>48), byte(x>>40), byte(x>>32), byte(x>>24), byte(x>>16), byte(x>>8), byte(x))
+}
+
+// mmdbRecord is a record in an mmdbTree.
+type mmdbRecord struct {
+	kind uint8
+	v    uint32 // node index or data offset
+}
+
+const (
+	mmdbRecordEmpty = iota
+	mmdbRecordNode
+	mmdbRecordData
+)
+
+// mmdbRange is a range of native IPv4 or IPv6 addresses.
+type mmdbRange struct {
+	from, to uint128
+	rec      mmdbRecord
+}
+
+// mmdbTree is an MMDB search tree being built. Node 0 is the root. Nodes may
+// be shared by multiple parents (i.e., for aliases), so nothing may be inserted
+// under a shared node.
+type mmdbTree struct {
+	nodes [][2]mmdbRecord
+}
+
+// mmdbBit returns bit i of ip, starting from the most significant one.
+func mmdbBit(ip uint128, i int) int {
+	if i < 64 {
+		return int(ip.hi >> (63 - i) & 1)
+	}
+	return int(ip.lo >> (127 - i) & 1)
+}
+
+// insert sets the record for the prefix of length n starting at ip.
+func (t *mmdbTree) insert(ip uint128, n int, r mmdbRecord) {
+	if n == 0 {
+		t.nodes[0] = [2]mmdbRecord{r, r}
+		return
+	}
+	var x uint32
+	for i := 0; i < n-1; i++ {
+		b := mmdbBit(ip, i)
+		c := t.nodes[x][b]
+		if c.kind != mmdbRecordNode {
+			t.nodes = append(t.nodes, [2]mmdbRecord{c, c})
+			c = mmdbRecord{mmdbRecordNode, uint32(len(t.nodes) - 1)}
+			t.nodes[x][b] = c
+		}
+		x = c.v
+	}
+	t.nodes[x][mmdbBit(ip, n-1)] = r
+}
+
+// get gets the record for the prefix of length n starting at ip, which may be
+// a record for a shorter prefix containing it.
+func (t *mmdbTree) get(ip uint128, n int) mmdbRecord {
+	if n == 0 {
+		return mmdbRecord{mmdbRecordNode, 0}
+	}
+	var x uint32
+	for i := 0; ; i++ {
+		c := t.nodes[x][mmdbBit(ip, i)]
+		if i == n-1 || c.kind != mmdbRecordNode {
+			return c
+		}
+		x = c.v
+	}
+}
+
+// order returns the nodes reachable from the root in depth-first order, and
+// the new index of each node.
+func (t *mmdbTree) order() (order []uint32, index []uint32) {
+	index = make([]uint32, len(t.nodes))
+	seen := make([]bool, len(t.nodes))
+	stack := []uint32{0}
+	seen[0] = true
+	for len(stack) != 0 {
+		x := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		index[x] = uint32(len(order))
+		order = append(order, x)
+		for i := 1; i >= 0; i-- {
+			if c := t.nodes[x][i]; c.kind == mmdbRecordNode && !seen[c.v] {
+				seen[c.v] = true
+				stack = append(stack, c.v)
+			}
+		}
+	}
+	return order, index
+}
+
+// eachRangePrefix calls fn with each prefix in the range [from, to) of
+// width-bit addresses.
+func eachRangePrefix(from, to uint128, width int, fn func(ip uint128, n int)) {
+	for from.Less(to) {
+		// the largest aligned block starting at from which fits
+		z := bits.TrailingZeros64(from.lo)
+		if from.lo == 0 {
+			z = 64 + bits.TrailingZeros64(from.hi)
+		}
+		if z > width {
+			z = width
+		}
+		d := to.sub(from)
+		l := 63 - bits.LeadingZeros64(d.lo)
+		if d.hi != 0 {
+			l = 127 - bits.LeadingZeros64(d.hi)
+		}
+		if z > l {
+			z = l
+		}
+		fn(from, width-z)
+		if z < 64 {
+			from = from.add(uint128{lo: 1 << z})
+		} else if z < 128 {
+			from = from.add(uint128{hi: 1 << (z - 64)})
+		} else {
+			break
+		}
+	}
+}
@@ -0,0 +1,751 @@
+package ip2x
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"math/big"
+	"net/netip"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// mmdbMetadataMaxSize is the maximum size of the metadata at the end of an
+// MMDB file.
+const mmdbMetadataMaxSize = 128 << 10
+
+// mmdbMaxDepth is the maximum nesting depth of MMDB data values.
+const mmdbMaxDepth = 32
+
+// MMDB reads a MaxMind DB (MMDB) file, such as a GeoIP2 or GeoLite2 database
+// or one written by [MMDBWriter], returning records which can be used like the
+// ones from a [DB]. It is safe for concurrent use.
+//
+// Values are mapped onto fields by their MMDB keys and converted to the type
+// of the field, so numbers are formatted in decimal for string fields (e.g.,
+// the GeoLite2 ASN). Values which are missing or can't be converted are empty.
+// Records only contain the mapped fields, but have the smallest IP2Location or
+// IP2Proxy type containing them, if any.
+type MMDB struct {
+	r       io.ReaderAt
+	s       *dbS
+	fields  []DBField // [column-2]
+	paths   mmdbPath  // tree of key paths
+	vals    [][]int   // [column-2] indexes of the key path values to try in order
+	nvals   int
+	nodes   uint32
+	recsize int
+	ipv6    bool
+	ip4     uint32 // node for ::/96
+	data    int64  // data section offset
+	end     int64  // metadata marker offset
+	dbtype  string
+	desc    string
+	epoch   time.Time
+}
+
+// NewMMDB opens the MMDB file of the specified size in r.
+//
+// Fields maps fields to MMDB keys like [MMDBWriter.Fields]. If nil, the GeoIP2
+// and GeoLite2 keys for the country code and name, region, city, latitude,
+// longitude, zip code, time zone, ASN, and AS are used. If a key is not found,
+// the column name of the field is also tried, so files written by
+// [MMDBWriter] with the default mapping can be read back.
+func NewMMDB(r io.ReaderAt, size int64, fields map[DBField]string) (*MMDB, error) {
+	if fields == nil {
+		fields = DefaultMMDBMapping(CountryCode, CountryName, Region, City, Latitude, Longitude, Zipcode, AS)
+		fields[Timezone] = "location.time_zone"
+		fields[ASN] = "autonomous_system_number"
+	}
+	if len(fields) == 0 {
+		return nil, errors.New("no fields to map")
+	}
+	if len(fields) > 0xFE {
+		return nil, errors.New("too many fields")
+	}
+	m := &MMDB{
+		r:      r,
+		s:      new(dbS),
+		fields: make([]DBField, 0, len(fields)),
+	}
+	for f := range fields {
+		m.fields = append(m.fields, f)
+	}
+	sort.Slice(m.fields, func(i, j int) bool {
+		return m.fields[i] < m.fields[j]
+	})
+	for i, f := range m.fields {
+		t, ok := f.fieldType()
+		if !ok {
+			return nil, errors.New("unknown field " + f.String())
+		}
+		var vals []int
+		for _, k := range []string{fields[f], f.String()} {
+			if len(vals) != 0 && k == fields[f] {
+				continue
+			}
+			key := &m.paths
+			for _, c := range strings.Split(k, ".") {
+				if c == "" {
+					return nil, errors.New("invalid mmdb key " + strconv.Quote(k) + " for " + f.String())
+				}
+				key = key.child(c)
+			}
+			key.vals = append(key.vals, m.nvals)
+			vals = append(vals, m.nvals)
+			m.nvals++
+		}
+		m.vals = append(m.vals, vals)
+
+		fd := dbI{uint8(i + 2), 0, t}
+		switch t {
+		case dbtype_f32, dbtype_u32, dbtype_i32:
+			fd.ptr = 0xFF // inline
+		}
+		if f <= dbFieldMax {
+			m.s.f[f] = fd
+		} else {
+			for DBField(len(m.s.x)) < f-dbField_extra {
+				m.s.x = append(m.s.x, dbI{})
+			}
+			m.s.x[f-dbField_extra-1] = fd
+		}
+	}
+	m.s.f[dbField_extra] = dbI{uint8(len(m.fields) + 1), 0, 0}
+	for _, p := range []DBProduct{IP2Location, IP2Proxy} {
+		if t, ok := MinimalType(p, m.fields...); ok {
+			m.s.f[dbField_extra] = dbI{uint8(len(m.fields) + 1), uint8(p), uint8(t)}
+			break
+		}
+	}
+	if err := m.readMetadata(size); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// readMetadata finds and parses the metadata, then checks the search tree.
+func (m *MMDB) readMetadata(size int64) error {
+	n := int64(mmdbMetadataMaxSize)
+	if size < n {
+		n = size
+	}
+	buf := make([]byte, n)
+	if k, err := m.r.ReadAt(buf, size-n); int64(k) != n {
+		return errors.New("read metadata: " + err.Error())
+	}
+	i := bytes.LastIndex(buf, []byte(mmdbMetadataMarker))
+	if i < 0 {
+		return errors.New("not an mmdb file (metadata not found)")
+	}
+	m.end = size - n + int64(i)
+
+	d := mmdbDecoder{r: m.r, base: m.end + int64(len(mmdbMetadataMarker)), end: size}
+	v, _, err := d.decode(d.base, 0)
+	if err != nil {
+		return errors.New("read metadata: " + err.Error())
+	}
+	md, ok := v.(map[string]any)
+	if !ok {
+		return errors.New("read metadata: not a map")
+	}
+	num := func(k string) uint64 {
+		x, _ := md[k].(uint64)
+		return x
+	}
+	if v := num("binary_format_major_version"); v != 2 {
+		return errors.New("unsupported mmdb format version " + strconv.FormatUint(v, 10))
+	}
+	switch v := num("ip_version"); v {
+	case 4, 6:
+		m.ipv6 = v == 6
+	default:
+		return errors.New("unsupported mmdb ip version " + strconv.FormatUint(v, 10))
+	}
+	switch v := num("record_size"); v {
+	case 24, 28, 32:
+		m.recsize = int(v)
+	default:
+		return errors.New("unsupported mmdb record size " + strconv.FormatUint(v, 10))
+	}
+	if v := num("node_count"); v < math.MaxUint32 {
+		m.nodes = uint32(v)
+	} else {
+		return errors.New("invalid mmdb node count " + strconv.FormatUint(v, 10))
+	}
+	if m.data = int64(m.nodes)*int64(m.recsize/4) + 16; m.data > m.end {
+		return errors.New("mmdb is corrupt: search tree is larger than the file")
+	}
+	m.dbtype, _ = md["database_type"].(string)
+	if x, ok := md["description"].(map[string]any); ok {
+		m.desc, _ = x["en"].(string)
+	}
+	if v := num("build_epoch"); v != 0 && v < math.MaxInt64 {
+		m.epoch = time.Unix(int64(v), 0).UTC()
+	}
+	if m.ipv6 {
+		var buf [8]byte
+		for i := 0; i < 96 && m.ip4 < m.nodes; i++ {
+			if m.ip4, err = m.record(&buf, m.ip4, 0); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// record reads the left (0) or right (1) record of node into b.
+func (m *MMDB) record(b *[8]byte, node uint32, bit int) (uint32, error) {
+	n := m.recsize / 4
+	if k, err := m.r.ReadAt(b[:n], int64(node)*int64(n)); k != n {
+		return 0, errors.New("read search tree: " + err.Error())
+	}
+	switch m.recsize {
+	case 24:
+		b := b[bit*3:]
+		return uint32(b[0])<<16 | uint32(b[1])<<8 | uint32(b[2]), nil
+	case 28:
+		if bit == 0 {
+			return uint32(b[3]>>4)<<24 | uint32(b[0])<<16 | uint32(b[1])<<8 | uint32(b[2]), nil
+		}
+		return uint32(b[3]&0xF)<<24 | uint32(b[4])<<16 | uint32(b[5])<<8 | uint32(b[6]), nil
+	default:
+		b := b[bit*4:]
+		return uint32(b[0])<<24 | uint32(b[1])<<16 | uint32(b[2])<<8 | uint32(b[3]), nil
+	}
+}
+
+// Lookup looks up a. If a is not found, an empty record and nil error is
+// returned. IPv4-mapped, 6to4, and Teredo addresses are looked up as IPv4.
+func (m *MMDB) Lookup(a netip.Addr) (r Record, err error) {
+	if !a.IsValid() {
+		return
+	}
+	ip, iplen := unmap(as_ip6_uint128(a))
+	var (
+		node uint32
+		buf  [8]byte
+	)
+	i := 0
+	if iplen == 4 {
+		if i = 96; m.ipv6 {
+			node = m.ip4
+		}
+	} else if !m.ipv6 {
+		return
+	}
+	for ; i < 128 && node < m.nodes; i++ {
+		if node, err = m.record(&buf, node, mmdbBit(ip, i)); err != nil {
+			return
+		}
+	}
+	if node <= m.nodes {
+		if node != m.nodes {
+			err = errors.New("mmdb is corrupt: search tree is too deep")
+		}
+		return
+	}
+	d := mmdbDecoder{r: m.r, base: m.data, end: m.end}
+	if r, err = m.row(&d, m.data+int64(node-m.nodes)-16); err != nil {
+		return Record{}, errors.New("read data: " + err.Error())
+	}
+	return r, nil
+}
+
+// LookupString parses and looks up a.
+func (m *MMDB) LookupString(a string) (Record, error) {
+	ip, err := netip.ParseAddr(a)
+	if err != nil {
+		return Record{}, err
+	}
+	return m.Lookup(ip)
+}
+
+// row builds a record from the data at off, only decoding the mapped values.
+func (m *MMDB) row(dec *mmdbDecoder, off int64) (Record, error) {
+	var (
+		d    = make([]byte, len(m.fields)*4)
+		vals = make([]any, m.nvals)
+		heap []byte
+	)
+	if err := dec.get(off, &m.paths, vals, 0); err != nil {
+		return Record{}, err
+	}
+	for i, f := range m.fields {
+		var x any
+		for _, v := range m.vals[i] {
+			if x = vals[v]; x != nil {
+				break
+			}
+		}
+		fd := m.s.Field(f)
+		b, err := binValue(fd.Type(), mmdbConvert(fd.Type(), x))
+		if err != nil {
+			b, _ = binValue(fd.Type(), nil)
+		}
+		if ^fd.PtrOffset() == 0 {
+			copy(d[i*4:], b)
+		} else {
+			le_put_u32(d[i*4:], uint32(len(heap)))
+			heap = append(heap, b...)
+		}
+	}
+	return Record{r: bytes.NewReader(heap), s: m.s, d: d}, nil
+}
+
+// mmdbConvert converts the decoded value v for use as a value of type t, or
+// nil if it isn't compatible.
+func mmdbConvert(t uint8, v any) any {
+	if t == dbtype_str {
+		var s string
+		switch v := v.(type) {
+		case string:
+			s = v
+		case bool:
+			s = strconv.FormatBool(v)
+		case uint64:
+			s = strconv.FormatUint(v, 10)
+		case int32:
+			s = strconv.FormatInt(int64(v), 10)
+		case float32:
+			s = strconv.FormatFloat(float64(v), 'g', -1, 32)
+		case float64:
+			s = strconv.FormatFloat(v, 'g', -1, 64)
+		case *big.Int:
+			s = v.String()
+		default:
+			return nil
+		}
+		if len(s) > 0xFF {
+			s = s[:0xFF]
+		}
+		return s
+	}
+	switch v.(type) {
+	case uint64, int32, float32, float64, *big.Int:
+		return v
+	}
+	return nil
+}
+
+// Info returns the product and type of the records, which are zero if no
+// IP2Location or IP2Proxy type contains all mapped fields.
+func (m *MMDB) Info() (DBProduct, DBType) {
+	_, p, t := m.s.Info()
+	return p, t
+}
+
+// Has returns true if f is mapped.
+func (m *MMDB) Has(f DBField) bool {
+	return m.s.Field(f).IsValid()
+}
+
+// HasIPv4 returns true.
+func (m *MMDB) HasIPv4() bool {
+	return true
+}
+
+// HasIPv6 returns true if the search tree is IPv6.
+func (m *MMDB) HasIPv6() bool {
+	return m.ipv6
+}
+
+// DatabaseType returns the MMDB database type (e.g., GeoLite2-City).
+func (m *MMDB) DatabaseType() string {
+	return m.dbtype
+}
+
+// Description returns the English MMDB description, if any.
+func (m *MMDB) Description() string {
+	return m.desc
+}
+
+// BuildTime returns the time the database was built, or the zero time if it
+// isn't set.
+func (m *MMDB) BuildTime() time.Time {
+	return m.epoch
+}
+
+// String returns a human-readable string describing the database.
+func (m *MMDB) String() string {
+	s := make([]byte, 0, 256)
+	s = append(s, "MMDB "...)
+	s = append(s, m.dbtype...)
+	if !m.epoch.IsZero() {
+		s = append(s, ' ')
+		s = m.epoch.AppendFormat(s, "2006-01-02")
+	}
+	s = append(s, ' ', '[')
+	for i, f := range m.fields {
+		if i != 0 {
+			s = append(s, ',')
+		}
+		s = append(s, f.String()...)
+	}
+	s = append(s, ']', ' ', '(')
+	if m.ipv6 {
+		s = append(s, "IPv4+IPv6"...)
+	} else {
+		s = append(s, "IPv4"...)
+	}
+	s = append(s, ')')
+	return as_strref_unsafe(s)
+}
+
+// mmdbDecoder decodes MMDB data values.
+type mmdbDecoder struct {
+	r    io.ReaderAt
+	base int64 // start of the section pointers are relative to
+	end  int64 // end of the section
+	buf  [256]byte
+	win  []byte // read-ahead window in buf
+	woff int64  // offset of win
+}
+
+// read reads n bytes at off. If n is small, the returned slice is only valid
+// until the next read.
+func (d *mmdbDecoder) read(off int64, n int) ([]byte, error) {
+	if off < d.base || int64(n) > d.end-off {
+		return nil, errors.New("offset " + strconv.FormatInt(off, 10) + " is out of range")
+	}
+	if off >= d.woff && off-d.woff+int64(n) <= int64(len(d.win)) {
+		return d.win[off-d.woff:][:n], nil
+	}
+	if n > len(d.buf) {
+		b := make([]byte, n)
+		if k, err := d.r.ReadAt(b, off); k != n {
+			return nil, err
+		}
+		return b, nil
+	}
+	w := d.buf[:]
+	if r := d.end - off; r < int64(len(w)) {
+		w = w[:r]
+	}
+	k, err := d.r.ReadAt(w, off)
+	if k < n {
+		d.win = nil
+		return nil, err
+	}
+	d.win, d.woff = w[:k], off
+	return d.win[:n], nil
+}
+
+// head reads the control bytes of the value at off, returning its type, size,
+// and the offset of its data. For pointers, the size is the offset of the value
+// pointed to, and the data offset is the one after the pointer.
+func (d *mmdbDecoder) head(off int64) (int, int64, int64, error) {
+	b, err := d.read(off, 1)
+	if err != nil {
+		return 0, 0, off, err
+	}
+	ctrl := b[0]
+	off++
+
+	typ := int(ctrl >> 5)
+	if typ == mmdbPointer {
+		n := int(ctrl>>3&3) + 1
+		if b, err = d.read(off, n); err != nil {
+			return 0, 0, off, err
+		}
+		var p int64
+		if n != 4 {
+			p = int64(ctrl & 7)
+		}
+		for _, c := range b {
+			p = p<<8 | int64(c)
+		}
+		switch n {
+		case 2:
+			p += 2048
+		case 3:
+			p += 526336
+		}
+		return typ, d.base + p, off + int64(n), nil
+	}
+	if typ == mmdbExtended {
+		if b, err = d.read(off, 1); err != nil {
+			return 0, 0, off, err
+		}
+		if typ = 7 + int(b[0]); typ <= mmdbMap {
+			return 0, 0, off, errors.New("invalid extended type " + strconv.Itoa(typ))
+		}
+		off++
+	}
+	size := int64(ctrl & 0x1F)
+	if size >= 29 {
+		n := int(size) - 28
+		if b, err = d.read(off, n); err != nil {
+			return 0, 0, off, err
+		}
+		size = 0
+		for _, c := range b {
+			size = size<<8 | int64(c)
+		}
+		switch n {
+		case 1:
+			size += 29
+		case 2:
+			size += 285
+		case 3:
+			size += 65821
+		}
+		off += int64(n)
+	}
+	return typ, size, off, nil
+}
+
+// skip returns the offset after the value at off without decoding it.
+func (d *mmdbDecoder) skip(off int64, depth int) (int64, error) {
+	if depth > mmdbMaxDepth {
+		return off, errors.New("data is nested too deeply")
+	}
+	typ, size, off, err := d.head(off)
+	if err != nil {
+		return off, err
+	}
+	switch typ {
+	case mmdbPointer, mmdbBool:
+		return off, nil
+	case mmdbMap, mmdbArray:
+		if size > d.end-off {
+			return off, errors.New("container size " + strconv.FormatInt(size, 10) + " is too large")
+		}
+		if typ == mmdbMap {
+			size *= 2
+		}
+		for i := int64(0); i < size; i++ {
+			if off, err = d.skip(off, depth+1); err != nil {
+				return off, err
+			}
+		}
+		return off, nil
+	}
+	if size > d.end-off {
+		return off, errors.New("size " + strconv.FormatInt(size, 10) + " is too large")
+	}
+	return off + size, nil
+}
+
+// key reads the map key at off, returning it and the offset after it. The key
+// is only valid until the next read.
+func (d *mmdbDecoder) key(off int64, depth int) ([]byte, int64, error) {
+	if depth > mmdbMaxDepth {
+		return nil, off, errors.New("data is nested too deeply")
+	}
+	typ, size, off, err := d.head(off)
+	if err != nil {
+		return nil, off, err
+	}
+	if typ == mmdbPointer {
+		k, _, err := d.key(size, depth+1)
+		return k, off, err
+	}
+	if typ != mmdbString {
+		return nil, off, errors.New("map key is not a string")
+	}
+	if size > d.end-off {
+		return nil, off, errors.New("size " + strconv.FormatInt(size, 10) + " is too large")
+	}
+	k, err := d.read(off, int(size))
+	return k, off + size, err
+}
+
+// mmdbPath is a node in a tree of MMDB key paths to read.
+type mmdbPath struct {
+	name  string
+	index int   // name as an array index, or -1
+	vals  []int // values to set to the value at this path
+	next  []*mmdbPath
+}
+
+// child gets or adds the child named c.
+func (k *mmdbPath) child(c string) *mmdbPath {
+	for _, n := range k.next {
+		if n.name == c {
+			return n
+		}
+	}
+	n := &mmdbPath{name: c, index: -1}
+	if i, err := strconv.Atoi(c); err == nil && i >= 0 {
+		n.index = i
+	}
+	k.next = append(k.next, n)
+	return n
+}
+
+// get decodes the values at the paths in key into vals, leaving ones which
+// don't exist or are maps or arrays as nil. Other values are skipped without
+// being decoded.
+func (d *mmdbDecoder) get(off int64, key *mmdbPath, vals []any, depth int) error {
+	if depth > mmdbMaxDepth {
+		return errors.New("data is nested too deeply")
+	}
+	typ, size, doff, err := d.head(off)
+	if err != nil {
+		return err
+	}
+	switch typ {
+	case mmdbPointer:
+		return d.get(size, key, vals, depth+1)
+	case mmdbMap, mmdbArray:
+		off = doff
+		if size > d.end-off {
+			return errors.New("container size " + strconv.FormatInt(size, 10) + " is too large")
+		}
+		for i, n := int64(0), 0; i < size && n < len(key.next); i++ {
+			var next *mmdbPath
+			if typ == mmdbMap {
+				k, koff, err := d.key(off, depth+1)
+				if err != nil {
+					return err
+				}
+				for _, c := range key.next {
+					if c.name == string(k) {
+						next = c
+						break
+					}
+				}
+				off = koff
+			} else {
+				for _, c := range key.next {
+					if int64(c.index) == i {
+						next = c
+						break
+					}
+				}
+			}
+			if next != nil {
+				if err := d.get(off, next, vals, depth+1); err != nil {
+					return err
+				}
+				n++
+			}
+			if off, err = d.skip(off, depth+1); err != nil {
+				return err
+			}
+		}
+	default:
+		if len(key.vals) != 0 {
+			v, _, err := d.decode(off, depth)
+			if err != nil {
+				return err
+			}
+			for _, i := range key.vals {
+				vals[i] = v
+			}
+		}
+	}
+	return nil
+}
+
+// decode decodes the value at off, returning it and the offset after it.
+// Strings are decoded as string, bytes as []byte, unsigned integers as uint64
+// or *big.Int, int32 as int32, floats as float32 or float64, maps as
+// map[string]any, and arrays as []any.
+func (d *mmdbDecoder) decode(off int64, depth int) (any, int64, error) {
+	if depth > mmdbMaxDepth {
+		return nil, off, errors.New("data is nested too deeply")
+	}
+	typ, size64, off, err := d.head(off)
+	if err != nil {
+		return nil, off, err
+	}
+	if typ == mmdbPointer {
+		v, _, err := d.decode(size64, depth+1)
+		return v, off, err
+	}
+	if size64 > d.end-off {
+		return nil, off, errors.New("size " + strconv.FormatInt(size64, 10) + " is too large")
+	}
+	size := int(size64)
+
+	switch typ {
+	case mmdbMap, mmdbArray:
+		if int64(size) > d.end-off {
+			return nil, off, errors.New("container size " + strconv.Itoa(size) + " is too large")
+		}
+		if typ == mmdbArray {
+			a := make([]any, size)
+			for i := range a {
+				if a[i], off, err = d.decode(off, depth+1); err != nil {
+					return nil, off, err
+				}
+			}
+			return a, off, nil
+		}
+		m := make(map[string]any, size)
+		for i := 0; i < size; i++ {
+			var k, v any
+			if k, off, err = d.decode(off, depth+1); err != nil {
+				return nil, off, err
+			}
+			ks, ok := k.(string)
+			if !ok {
+				return nil, off, errors.New("map key is not a string")
+			}
+			if v, off, err = d.decode(off, depth+1); err != nil {
+				return nil, off, err
+			}
+			m[ks] = v
+		}
+		return m, off, nil
+	case mmdbBool:
+		if size > 1 {
+			return nil, off, errors.New("invalid bool size " + strconv.Itoa(size))
+		}
+		return size == 1, off, nil
+	}
+
+	var maxSize int
+	switch typ {
+	case mmdbString, mmdbBytes:
+		maxSize = size
+	case mmdbDouble:
+		maxSize = 8
+	case mmdbFloat, mmdbUint32, mmdbInt32:
+		maxSize = 4
+	case mmdbUint16:
+		maxSize = 2
+	case mmdbUint64:
+		maxSize = 8
+	case mmdbUint128:
+		maxSize = 16
+	default:
+		return nil, off, errors.New("unsupported data type " + strconv.Itoa(typ))
+	}
+	if size > maxSize || ((typ == mmdbDouble || typ == mmdbFloat) && size != maxSize) {
+		return nil, off, errors.New("invalid size " + strconv.Itoa(size) + " for data type " + strconv.Itoa(typ))
+	}
+	b, err := d.read(off, size)
+	if err != nil {
+		return nil, off, err
+	}
+	off += int64(size)
+
+	switch typ {
+	case mmdbString:
+		return string(b), off, nil
+	case mmdbBytes:
+		return append([]byte(nil), b...), off, nil
+	case mmdbUint128:
+		return new(big.Int).SetBytes(b), off, nil
+	}
+	var x uint64
+	for _, c := range b {
+		x = x<<8 | uint64(c)
+	}
+	switch typ {
+	case mmdbDouble:
+		return math.Float64frombits(x), off, nil
+	case mmdbFloat:
+		return math.Float32frombits(uint32(x)), off, nil
+	case mmdbInt32:
+		return int32(uint32(x)), off, nil
+	default:
+		return x, off, nil
+	}
+}
@@ -0,0 +1,537 @@
+package ip2x
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"net/netip"
+	"sort"
+	"strconv"
+)
+
+// The native format is a compact alternative to the BIN format, which is
+// written by [WriteNative] and opened by [New] like a BIN file. It consists of
+// the magic bytes, a version byte, then a sequence of sections, each of which
+// has a type byte, a 32-bit little-endian payload length, the payload, and the
+// CRC-32 (IEEE) of the type and payload.
+//
+// The schema section contains the product code, type, product type, date
+// (year since 2000, month, day), column count, then the name, column, pointer
+// offset, and type of each field.
+//
+// The heap section contains the deduplicated data for pointer columns, which
+// is referenced by offset.
+//
+// The IPv4 and IPv6 table sections contain the number of rows, a dictionary of
+// distinct values for each column (sorted by frequency), then the uvarint
+// delta from the previous range start and the uvarint dictionary index for
+// each column of each row, then the delta to the end of the last range. IPv6
+// deltas are encoded as the upper and lower 64 bits.
+const (
+	nativeMagic   = "IP2X"
+	nativeVersion = 1
+)
+
+const (
+	nativeSectionSchema = 'S'
+	nativeSectionHeap   = 'H'
+	nativeSectionIPv4   = '4'
+	nativeSectionIPv6   = '6'
+)
+
+// nativeTable is a decoded IPv4 or IPv6 table.
+type nativeTable struct {
+	from   []uint128  // [row]start address, plus the end of the last row
+	dict   [][]uint32 // [column-2][index]value
+	width  []int      // [column-2]bytes per index
+	offset []int      // [column-2]offset of index in row
+	stride int        // bytes per row
+	rows   []byte     // [row*stride+offset]index
+}
+
+// WriteNative converts db into the native format, writing it to dst. The
+// native format is smaller than a BIN file, and can be opened much faster, but
+// must be read into memory. All columns are preserved, including ones not in
+// the database layout, but values in columns without any known fields are
+// assumed not to be pointers.
+func WriteNative(dst io.Writer, db *DB) error {
+	if db.s == nil {
+		return errors.New("database does not have a layout")
+	}
+	var (
+		cols  = make([][]DBField, db.dbcolumn-1) // [column-2] sorted by pointer offset
+		heap  []byte
+		blobs = map[string]uint32{} // [data]heap offset
+		ptrs  = map[uint64]uint32{} // [column<<32|pointer]heap offset
+		buf   [getbufSize]byte
+	)
+	for f, m := DBField(1), db.s.FieldMax(); f <= m; f++ {
+		if fd := db.s.Field(f); fd.IsValid() && int(fd.Column()-2) < len(cols) {
+			cols[fd.Column()-2] = append(cols[fd.Column()-2], f)
+		}
+	}
+	for _, fs := range cols {
+		sort.Slice(fs, func(i, j int) bool {
+			return db.s.Field(fs[i]).PtrOffset() < db.s.Field(fs[j]).PtrOffset()
+		})
+	}
+
+	// schema
+	b := append([]byte(nativeMagic), nativeVersion)
+	b = appendNativeSection(b, nativeSectionSchema, func(b []byte) []byte {
+		b = append(b, byte(db.prcode), byte(db.dbtype), db.prtype, db.dbyear, db.dbmonth, db.dbday, db.dbcolumn)
+		var fields []DBField
+		for _, fs := range cols {
+			fields = append(fields, fs...)
+		}
+		b = append(b, byte(len(fields)))
+		for _, f := range fields {
+			fd := db.s.Field(f)
+			b = append(b, byte(len(f.String())))
+			b = append(b, f.String()...)
+			b = append(b, fd.Column(), fd.PtrOffset(), fd.Type())
+		}
+		return b
+	})
+
+	// tables
+	var tables [2][]byte
+	for i, v6 := range []bool{false, true} {
+		var (
+			from []uint128
+			data []uint32 // [row*(columns-1)+column-2]
+			rerr error
+		)
+		if _, err := db.each(v6, func(rg Range, r Record) bool {
+			if len(from) == 0 {
+				from = append(from, nativeAddr(rg.From))
+			} else if from[len(from)-1] != nativeAddr(rg.From) {
+				rerr = errors.New("database is corrupt: rows are not contiguous")
+				return false
+			}
+			from = append(from, nativeAddr(rg.To)) // the next start
+			for c, fs := range cols {
+				v := as_le_u32(r.d[c*4:])
+				if len(fs) != 0 && ^db.s.Field(fs[0]).PtrOffset() != 0 {
+					key := uint64(c)<<32 | uint64(v)
+					off, ok := ptrs[key]
+					if !ok {
+						blob, err := nativeBlob(r, fs, buf[:])
+						if err != nil {
+							rerr = err
+							return false
+						}
+						if off, ok = blobs[string(blob)]; !ok {
+							off = uint32(len(heap))
+							heap = append(heap, blob...)
+							blobs[string(blob)] = off
+						}
+						ptrs[key] = off
+					}
+					v = off
+				}
+				data = append(data, v)
+			}
+			return true
+		}); err != nil {
+			return err
+		}
+		if rerr != nil {
+			return rerr
+		}
+		if len(from) == 0 {
+			continue
+		}
+		for j := 1; j < len(from); j++ {
+			if !from[j-1].Less(from[j]) {
+				return errors.New("database is corrupt: rows are not sorted")
+			}
+		}
+		kind := byte(nativeSectionIPv4)
+		if v6 {
+			kind = nativeSectionIPv6
+		}
+		tables[i] = appendNativeSection(nil, kind, func(b []byte) []byte {
+			return appendNativeTable(b, v6, from, data, len(cols))
+		})
+	}
+	if uint64(len(heap)) > 0xFFFFFFFF {
+		return errors.New("database is too large")
+	}
+
+	b = appendNativeSection(b, nativeSectionHeap, func(b []byte) []byte {
+		return append(b, heap...)
+	})
+	b = append(b, tables[0]...)
+	b = append(b, tables[1]...)
+	_, err := dst.Write(b)
+	return err
+}
+
+// nativeAddr returns a as a native IPv4 or IPv6 uint128.
+func nativeAddr(a netip.Addr) uint128 {
+	v := as_ip6_uint128(a)
+	if a.Is4() {
+		v.hi, v.lo = 0, v.lo&0xffffffff
+	}
+	return v
+}
+
+// nativeBlob returns the data for the pointer fields fs, which are in the same
+// column and sorted by offset.
+func nativeBlob(r Record, fs []DBField, buf []byte) ([]byte, error) {
+	var blob []byte
+	for i, f := range fs {
+		dt, fd, err := r.getb(f, buf)
+		if dt == nil && err != nil {
+			return nil, errors.New("read " + f.String() + ": " + err.Error())
+		}
+		if len(blob) > int(fd.PtrOffset()) {
+			return nil, errors.New("database is corrupt: " + fs[i-1].String() + " overlaps " + f.String())
+		}
+		for len(blob) < int(fd.PtrOffset()) {
+			blob = append(blob, 0)
+		}
+		if fd.Type() == dbtype_str {
+			blob = append(blob, byte(len(dt)))
+		}
+		blob = append(blob, dt...)
+	}
+	return blob, nil
+}
+
+// appendNativeSection appends a section with the payload appended by fn.
+func appendNativeSection(b []byte, kind byte, fn func([]byte) []byte) []byte {
+	start := len(b)
+	b = append(b, kind, 0, 0, 0, 0)
+	b = fn(b)
+	le_put_u32(b[start+1:], uint32(len(b)-start-5))
+	b = append(b, 0, 0, 0, 0)
+	le_put_u32(b[len(b)-4:], crc32.ChecksumIEEE(b[start:len(b)-4]))
+	return b
+}
+
+// appendNativeTable appends a table with the specified row start addresses
+// (plus the end of the last one) and column values.
+func appendNativeTable(b []byte, v6 bool, from []uint128, data []uint32, ncol int) []byte {
+	n := len(from) - 1
+	b = append_uvarint(b, uint64(n))
+
+	index := make([]map[uint32]uint64, ncol)
+	for c := range index {
+		count := map[uint32]int{}
+		for i := 0; i < n; i++ {
+			count[data[i*ncol+c]]++
+		}
+		dict := make([]uint32, 0, len(count))
+		for v := range count {
+			dict = append(dict, v)
+		}
+		sort.Slice(dict, func(i, j int) bool {
+			if a, b := count[dict[i]], count[dict[j]]; a != b {
+				return a > b
+			}
+			return dict[i] < dict[j]
+		})
+		index[c] = make(map[uint32]uint64, len(dict))
+		b = append_uvarint(b, uint64(len(dict)))
+		for i, v := range dict {
+			index[c][v] = uint64(i)
+			b = append(b, 0, 0, 0, 0)
+			le_put_u32(b[len(b)-4:], v)
+		}
+	}
+
+	var prev uint128
+	for i, v := range from {
+		d := v.sub(prev)
+		if v6 {
+			b = append_uvarint(b, d.hi)
+		}
+		b = append_uvarint(b, d.lo)
+		if i < n {
+			for c := 0; c < ncol; c++ {
+				b = append_uvarint(b, index[c][data[i*ncol+c]])
+			}
+		}
+		prev = v
+	}
+	return b
+}
+
+// openNative opens a native database.
+func (db *DB) openNative(r io.ReaderAt) error {
+	var hdr [len(nativeMagic) + 1]byte
+	if _, err := r.ReadAt(hdr[:], 0); err != nil {
+		return err
+	}
+	if hdr[len(nativeMagic)] != nativeVersion {
+		return errors.New("unsupported native database version " + strconv.Itoa(int(hdr[len(nativeMagic)])))
+	}
+	var (
+		off      = int64(len(hdr))
+		heap     []byte
+		t4, t6   []byte
+		schema   bool
+		sections = map[byte]bool{}
+	)
+	for {
+		var sh [5]byte
+		if n, err := r.ReadAt(sh[:], off); n == 0 && err == io.EOF {
+			break
+		} else if n != len(sh) {
+			return errors.New("read section: " + err.Error())
+		}
+		// read incrementally since the length hasn't been checked yet
+		n := 5 + int64(as_le_u32(sh[1:])) + 4
+		b, err := io.ReadAll(io.NewSectionReader(r, off, n))
+		if err == nil && int64(len(b)) != n {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return errors.New("read section " + strconv.QuoteRune(rune(sh[0])) + ": " + err.Error())
+		}
+		if crc32.ChecksumIEEE(b[:len(b)-4]) != as_le_u32(b[len(b)-4:]) {
+			return errors.New("database is corrupt: checksum mismatch in section " + strconv.QuoteRune(rune(sh[0])))
+		}
+		if sections[sh[0]] {
+			return errors.New("database is corrupt: duplicate section " + strconv.QuoteRune(rune(sh[0])))
+		}
+		sections[sh[0]] = true
+		off += int64(len(b))
+
+		p := b[5 : len(b)-4]
+		switch sh[0] {
+		case nativeSectionSchema:
+			if err := db.parseNativeSchema(p); err != nil {
+				return err
+			}
+			schema = true
+		case nativeSectionHeap:
+			heap = p
+		case nativeSectionIPv4:
+			t4 = p
+		case nativeSectionIPv6:
+			t6 = p
+		default:
+			return errors.New("database is corrupt: unknown section " + strconv.QuoteRune(rune(sh[0])))
+		}
+	}
+	if !schema {
+		return errors.New("database is corrupt: missing schema")
+	}
+	if off > 0xFFFFFFFF {
+		return errors.New("database is too large")
+	}
+	db.filesize = uint32(off)
+	db.r = bytes.NewReader(heap)
+
+	var err error
+	if t4 != nil {
+		if db.n4, err = parseNativeTable(t4, false, int(db.dbcolumn-1), len(heap)); err != nil {
+			return errors.New("ipv4 table: " + err.Error())
+		}
+		db.ip4count = uint32(len(db.n4.from))
+	}
+	if t6 != nil {
+		if db.n6, err = parseNativeTable(t6, true, int(db.dbcolumn-1), len(heap)); err != nil {
+			return errors.New("ipv6 table: " + err.Error())
+		}
+		db.ip6count = uint32(len(db.n6.from))
+	}
+	return nil
+}
+
+// parseNativeSchema parses the schema section into db.
+func (db *DB) parseNativeSchema(b []byte) error {
+	if len(b) < 8 {
+		return errors.New("database is corrupt: schema is too short")
+	}
+	db.prcode, db.dbtype, db.prtype = DBProduct(b[0]), DBType(b[1]), b[2]
+	db.dbyear, db.dbmonth, db.dbday = b[3], b[4], b[5]
+	db.dbcolumn = b[6]
+	if db.dbcolumn == 0 || db.dbmonth == 0 || db.dbmonth > 12 || db.dbday == 0 || db.dbday > 31 {
+		return errors.New("database is corrupt: invalid schema")
+	}
+
+	s := new(dbS)
+	s.f[dbField_extra] = dbI{db.dbcolumn, uint8(db.prcode), uint8(db.dbtype)}
+	b, n := b[8:], int(b[7])
+	for i := 0; i < n; i++ {
+		if len(b) < 1 || len(b) < 1+int(b[0])+3 {
+			return errors.New("database is corrupt: schema is too short")
+		}
+		name, fd := string(b[1:1+b[0]]), dbI{b[1+b[0]], b[2+b[0]], b[3+b[0]]}
+		b = b[4+b[0]:]
+		if fd.col < 2 || fd.col > db.dbcolumn || fd.typ > dbtype_u128 {
+			return errors.New("database is corrupt: invalid field " + strconv.Quote(name))
+		}
+		f, err := ParseDBField(name)
+		if err != nil {
+			continue // unknown field (e.g., not registered); the column is still accessible
+		}
+		if f <= dbFieldMax {
+			s.f[f] = fd
+		} else {
+			for DBField(len(s.x)) < f-dbField_extra {
+				s.x = append(s.x, dbI{})
+			}
+			s.x[f-dbField_extra-1] = fd
+		}
+	}
+	db.s = s
+	return nil
+}
+
+// parseNativeTable parses a table section.
+func parseNativeTable(b []byte, v6 bool, ncol, heap int) (*nativeTable, error) {
+	uvarint := func() uint64 {
+		v, n := binary.Uvarint(b)
+		if n <= 0 {
+			b = nil
+			return 0
+		}
+		b = b[n:]
+		return v
+	}
+	errShort := errors.New("database is corrupt: table is too short")
+
+	n := uvarint()
+	if b == nil || n >= 0xFFFFFFFF || n > uint64(len(b)) {
+		return nil, errShort
+	}
+	t := &nativeTable{
+		from:   make([]uint128, 0, n+1),
+		dict:   make([][]uint32, ncol),
+		width:  make([]int, ncol),
+		offset: make([]int, ncol),
+	}
+	for c := range t.dict {
+		m := uvarint()
+		if b == nil || m > uint64(len(b))/4 {
+			return nil, errShort
+		}
+		t.dict[c] = make([]uint32, m)
+		for i := range t.dict[c] {
+			t.dict[c][i] = as_le_u32(b[i*4:])
+		}
+		b = b[m*4:]
+		switch {
+		case m <= 1<<8:
+			t.width[c] = 1
+		case m <= 1<<16:
+			t.width[c] = 2
+		default:
+			t.width[c] = 4
+		}
+		t.offset[c] = t.stride
+		t.stride += t.width[c]
+	}
+	t.rows = make([]byte, int(n)*t.stride)
+
+	var prev uint128
+	for i := uint64(0); i <= n; i++ {
+		var d uint128
+		if v6 {
+			d.hi = uvarint()
+		}
+		d.lo = uvarint()
+		if b == nil {
+			return nil, errShort
+		}
+		v := prev.add(d)
+		if (i != 0 && !prev.Less(v)) || (!v6 && v.lo > 0xFFFFFFFF) || v.Less(prev) {
+			return nil, errors.New("database is corrupt: invalid address")
+		}
+		t.from = append(t.from, v)
+		prev = v
+		if i == n {
+			break
+		}
+		row := t.rows[int(i)*t.stride:]
+		for c := range t.dict {
+			x := uvarint()
+			if b == nil || x >= uint64(len(t.dict[c])) {
+				return nil, errors.New("database is corrupt: invalid column value")
+			}
+			switch t.width[c] {
+			case 1:
+				row[t.offset[c]] = byte(x)
+			case 2:
+				row[t.offset[c]], row[t.offset[c]+1] = byte(x), byte(x>>8)
+			default:
+				le_put_u32(row[t.offset[c]:], uint32(x))
+			}
+		}
+	}
+	if len(b) != 0 {
+		return nil, errors.New("database is corrupt: trailing data in table")
+	}
+	return t, nil
+}
+
+// search returns the row containing ip, which is a native IPv4 or IPv6.
+func (t *nativeTable) search(ip uint128) (int, bool) {
+	n := len(t.from) - 1
+	i := sort.Search(n, func(i int) bool {
+		return ip.Less(t.from[i+1])
+	})
+	return i, i < n && !ip.Less(t.from[i])
+}
+
+// data returns the column data for row i in the same format as a BIN row.
+func (t *nativeTable) data(i int) []byte {
+	var (
+		d   = make([]byte, len(t.dict)*4)
+		row = t.rows[i*t.stride:]
+	)
+	for c, dict := range t.dict {
+		var x int
+		switch t.width[c] {
+		case 1:
+			x = int(row[t.offset[c]])
+		case 2:
+			x = int(row[t.offset[c]]) | int(row[t.offset[c]+1])<<8
+		default:
+			x = int(as_le_u32(row[t.offset[c]:]))
+		}
+		le_put_u32(d[c*4:], dict[x])
+	}
+	return d
+}
+
+// eachNative is like each, but for a native database.
+func (db *DB) eachNative(v6 bool, fn func(Range, Record) bool) (bool, error) {
+	t := db.n4
+	if v6 {
+		t = db.n6
+	}
+	if t == nil {
+		return true, nil
+	}
+	for i := 0; i < len(t.from)-1; i++ {
+		var rg Range
+		if v6 {
+			rg.From = netip.AddrFrom16(to_be_u128(t.from[i]))
+			rg.To = netip.AddrFrom16(to_be_u128(t.from[i+1]))
+		} else {
+			rg.From = netip.AddrFrom4(to_be_u32(uint32(t.from[i].lo)))
+			rg.To = netip.AddrFrom4(to_be_u32(uint32(t.from[i+1].lo)))
+		}
+		if !fn(rg, Record{r: db.r, s: db.s, d: t.data(i), c: db.c}) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// append_uvarint appends v to b as a uvarint.
+func append_uvarint(b []byte, v uint64) []byte {
+	for v >= 0x80 {
+		b = append(b, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(b, byte(v))
+}
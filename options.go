@@ -0,0 +1,110 @@
+package ip2x
+
+import (
+	"strconv"
+	"time"
+)
+
+// Option configures how a database is opened by [New].
+type Option func(*options)
+
+type options struct {
+	raw       bool
+	intern    int
+	preload   bool
+	index4    int
+	index6    int
+	eytzinger bool
+	check     []func(*DB) error
+}
+
+// Raw makes [New] open databases with unknown products, types, or column
+// counts, and databases from before 2021 (which may not have a product code).
+// If there isn't a matching layout, the database will not have any fields, but
+// the columns can still be accessed using [Record.Column].
+func Raw() Option {
+	return func(o *options) {
+		o.raw = true
+	}
+}
+
+// InternStrings makes string fields stored in the database's string heap (e.g.,
+// [City] and [ISP]) be cached by offset, using up to approximately max bytes
+// of memory. Once a string is cached, getting it doesn't read from the database
+// or allocate memory. Since most databases only have a few thousand distinct
+// strings, strings are not evicted once the limit is reached; new ones are
+// just not cached.
+func InternStrings(max int) Option {
+	return func(o *options) {
+		o.intern = max
+	}
+}
+
+// Preload makes [New] load the index tables into memory, so lookups don't need
+// to read them from the database.
+func Preload() Option {
+	return func(o *options) {
+		o.preload = true
+	}
+}
+
+// IndexBits makes [New] build in-memory indexes on the first ip4 bits of IPv4
+// addresses and the first ip6 bits of IPv6 addresses, which reduces the number
+// of rows read by the binary search in large databases. This is more granular
+// than the 16-bit index tables in the database, and works even if the database
+// doesn't have them. Building the index requires reading all rows, and it uses
+// 4 bytes of memory for each prefix (e.g., 4 MiB for 20 bits). The number of
+// bits must be between 16 and 24, or zero to use the index tables in the
+// database for that address family.
+func IndexBits(ip4, ip6 int) Option {
+	return func(o *options) {
+		o.index4 = ip4
+		o.index6 = ip6
+	}
+}
+
+// EytzingerSearch makes [New] build an in-memory search tree of the start
+// address of every row, stored in a cache-friendly order, so lookups only need
+// to read the matching row from the database. This is the fastest way to look
+// up addresses, but building it requires reading all rows, and it uses 8 bytes
+// of memory for each IPv4 row and 20 bytes for each IPv6 one. It takes
+// precedence over [Preload] and [IndexBits], which are ignored.
+func EytzingerSearch() Option {
+	return func(o *options) {
+		o.eytzinger = true
+	}
+}
+
+// MaxAge makes [New] fail with a [*StaleError] if the database is older than d.
+// If warn is not nil, it is called with the error instead. It may be specified
+// multiple times.
+func MaxAge(d time.Duration, warn func(error)) Option {
+	return func(o *options) {
+		o.check = append(o.check, func(db *DB) error {
+			if age := db.Age(time.Now()); age > d {
+				err := &StaleError{
+					Date:   db.Date(),
+					Age:    age,
+					MaxAge: d,
+				}
+				if warn == nil {
+					return err
+				}
+				warn(err)
+			}
+			return nil
+		})
+	}
+}
+
+// StaleError is returned by [New] if the database is older than allowed by
+// [MaxAge].
+type StaleError struct {
+	Date   time.Time
+	Age    time.Duration
+	MaxAge time.Duration
+}
+
+func (err *StaleError) Error() string {
+	return "database is stale (date: " + err.Date.Format("2006-01-02") + ", age: " + strconv.Itoa(int(err.Age/(24*time.Hour))) + " days, max: " + err.MaxAge.String() + ")"
+}
@@ -0,0 +1,177 @@
+package ip2x
+
+import (
+	"net/netip"
+	"sort"
+	"sync"
+)
+
+// RangeCache is a [Lookuper] which caches records by the range of addresses
+// they were found for, so a lookup for any address in a cached range doesn't
+// read from the database. This uses much less memory than caching individual
+// addresses, since most rows cover many addresses. It is safe for concurrent
+// use.
+type RangeCache struct {
+	mu    sync.Mutex
+	db    *DB
+	gen   uint64
+	size  int
+	rs    []rangeEntry // sorted by family then address
+	hits  uint64
+	miss  uint64
+	evict uint64
+}
+
+type rangeEntry struct {
+	v6       bool
+	used     bool
+	from, to uint128 // unmapped
+	r        Record
+}
+
+// RangeCacheStats contains statistics about a [RangeCache].
+type RangeCacheStats struct {
+	Hits      uint64 // lookups answered from the cache
+	Misses    uint64 // lookups which read from the database
+	Evictions uint64 // ranges removed to make space for new ones
+	Ranges    int    // number of cached ranges
+}
+
+// NewRangeCache caches up to size ranges from db.
+func NewRangeCache(db *DB, size int) *RangeCache {
+	if size < 1 {
+		size = 1
+	}
+	return &RangeCache{
+		db:   db,
+		size: size,
+	}
+}
+
+// Lookup looks up a, returning a cached record if a is in a range which was
+// previously looked up.
+func (c *RangeCache) Lookup(a netip.Addr) (Record, error) {
+	if !a.IsValid() {
+		return Record{}, nil
+	}
+	ip, iplen := unmap(as_ip6_uint128(a))
+	v6 := iplen != 4
+
+	c.mu.Lock()
+	if i, ok := c.search(v6, ip); ok {
+		e := &c.rs[i]
+		e.used = true
+		c.hits++
+		c.mu.Unlock()
+		return e.r, nil
+	}
+	db, gen := c.db, c.gen
+	c.miss++
+	c.mu.Unlock()
+
+	r, from, to, _, err := db.lookup(a)
+	if err != nil || !r.IsValid() {
+		return r, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.gen != gen {
+		return r, nil // database was replaced
+	}
+	i, ok := c.search(v6, ip)
+	if ok {
+		return r, nil // added concurrently
+	}
+	if len(c.rs) >= c.size {
+		c.shrink()
+		i, _ = c.search(v6, ip)
+	}
+	c.rs = append(c.rs, rangeEntry{})
+	copy(c.rs[i+1:], c.rs[i:])
+	c.rs[i] = rangeEntry{
+		v6:   v6,
+		from: from,
+		to:   to,
+		r:    r,
+	}
+	return r, nil
+}
+
+// search returns the index of the range containing ip, or where it should be
+// inserted. The mutex must be held.
+func (c *RangeCache) search(v6 bool, ip uint128) (int, bool) {
+	i := sort.Search(len(c.rs), func(i int) bool {
+		if e := &c.rs[i]; e.v6 != v6 {
+			return e.v6
+		} else {
+			return ip.Less(e.to)
+		}
+	})
+	return i, i < len(c.rs) && c.rs[i].v6 == v6 && !ip.Less(c.rs[i].from)
+}
+
+// shrink evicts ranges which haven't been used since the last time it was
+// called, or every other one (including the only one) if all of them have. The
+// mutex must be held.
+func (c *RangeCache) shrink() {
+	n := 0
+	for _, e := range c.rs {
+		if e.used {
+			e.used = false
+			c.rs[n] = e
+			n++
+		}
+	}
+	if n >= c.size {
+		n = 0
+		for i, e := range c.rs {
+			if i%2 == 1 {
+				c.rs[n] = e
+				n++
+			}
+		}
+	}
+	for i := n; i < len(c.rs); i++ {
+		c.rs[i] = rangeEntry{} // don't keep references to the records
+	}
+	c.evict += uint64(len(c.rs) - n)
+	c.rs = c.rs[:n]
+}
+
+// Reset replaces the database (e.g., after it was reloaded) and removes all
+// cached ranges. If db is nil, the current database is kept. Lookups which were
+// in progress will not be cached.
+func (c *RangeCache) Reset(db *DB) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if db != nil {
+		c.db = db
+	}
+	c.gen++
+	c.rs = nil
+}
+
+// DB returns the current database.
+func (c *RangeCache) DB() *DB {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.db
+}
+
+// Stats returns statistics about the cache.
+func (c *RangeCache) Stats() RangeCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return RangeCacheStats{
+		Hits:      c.hits,
+		Misses:    c.miss,
+		Evictions: c.evict,
+		Ranges:    len(c.rs),
+	}
+}
+
+func (c *RangeCache) Info() (DBProduct, DBType) { return c.DB().Info() }
+func (c *RangeCache) Has(f DBField) bool        { return c.DB().Has(f) }
+func (c *RangeCache) HasIPv4() bool             { return c.DB().HasIPv4() }
+func (c *RangeCache) HasIPv6() bool             { return c.DB().HasIPv6() }
@@ -0,0 +1,437 @@
+package ip2x
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// registry contains database layouts registered at runtime.
+var registry struct {
+	sync.RWMutex
+	product     map[DBProduct]registeredProduct
+	productName map[string]DBProduct // [lowercase name or prefix]
+	field       []registeredField    // [f-dbField_extra-1]
+	fieldName   map[string]DBField   // [column name]
+	schema      map[DBProduct]map[DBType][]*dbS
+}
+
+type registeredProduct struct {
+	name   string
+	prefix string
+}
+
+type registeredField struct {
+	name string
+	typ  uint8
+}
+
+// Register registers a database product layout at runtime, using the same
+// table syntax as codegen.Product in dbdata.go. This allows [New] to open
+// databases with types, columns, or product codes not known to this version of
+// the library.
+//
+// The first line contains the product code, name, type prefix, and the
+// database types being defined. Each following line contains the column type
+// (see codegen.Product) with an optional @N pointer offset, the column name,
+// and the column number (or . if not present) for each database type. Unlike
+// in codegen, the database types do not need to start at 1 or be sequential.
+//
+// If the product code is built-in, the name and prefix must match. Column names
+// which are not built-in are assigned new [DBField] values, which will be
+// returned by [AllFields] and accepted by [ParseDBField]. Registered layouts
+// take precedence over built-in ones with the same product, type, and number of
+// columns, and replace previously registered ones.
+//
+// Register is safe for concurrent use, but databases which have already been
+// opened are not affected.
+func Register(spec string) error {
+	var (
+		code   uint64
+		name   string
+		prefix string
+		types  []DBType
+		cols   []registeredColumn
+	)
+	for n, line := range strings.Split(spec, "\n") {
+		words := strings.Fields(line)
+		if len(words) == 0 {
+			continue
+		}
+		if code == 0 {
+			var err error
+			if len(words) < 3 {
+				return errors.New("register: line " + strconv.Itoa(n) + ": expected product code, name, and prefix")
+			}
+			if code, err = strconv.ParseUint(words[0], 10, 8); err != nil || code == 0 {
+				return errors.New("register: line " + strconv.Itoa(n) + ": invalid product code " + strconv.Quote(words[0]))
+			}
+			if name, prefix = words[1], words[2]; !isUpper(prefix) {
+				return errors.New("register: line " + strconv.Itoa(n) + ": invalid product prefix " + strconv.Quote(prefix))
+			}
+			for _, w := range words[3:] {
+				v, err := strconv.ParseUint(w, 10, 8)
+				if err != nil || v == 0 {
+					return errors.New("register: line " + strconv.Itoa(n) + ": invalid database type " + strconv.Quote(w))
+				}
+				for _, t := range types {
+					if t == DBType(v) {
+						return errors.New("register: line " + strconv.Itoa(n) + ": duplicate database type " + w)
+					}
+				}
+				types = append(types, DBType(v))
+			}
+			if len(types) == 0 {
+				return errors.New("register: line " + strconv.Itoa(n) + ": expected database types")
+			}
+			continue
+		}
+		col, err := parseRegisteredColumn(words, len(types))
+		if err != nil {
+			return errors.New("register: line " + strconv.Itoa(n) + ": " + err.Error())
+		}
+		for _, c := range cols {
+			if c.name == col.name {
+				return errors.New("register: line " + strconv.Itoa(n) + ": duplicate column " + strconv.Quote(col.name))
+			}
+		}
+		cols = append(cols, col)
+	}
+	if code == 0 {
+		return errors.New("register: missing product line")
+	}
+	for i, t := range types {
+		if err := checkRegisteredColumns(cols, i); err != nil {
+			return errors.New("register: " + prefix + t.String() + ": " + err.Error())
+		}
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	p := DBProduct(code)
+	if pn, pp := p.product(), p.prefix(); pn != "" {
+		if pn != name || pp != prefix {
+			return errors.New("register: product code " + strconv.Itoa(int(p)) + " is built-in as " + pn + " " + pp)
+		}
+	} else if rp, ok := registry.product[p]; ok {
+		if rp.name != name || rp.prefix != prefix {
+			return errors.New("register: product code " + strconv.Itoa(int(p)) + " is already registered as " + rp.name + " " + rp.prefix)
+		}
+	} else {
+		for _, s := range []string{name, prefix} {
+			if dbProductByName(strings.ToLower(s)) != 0 || registry.productName[strings.ToLower(s)] != 0 {
+				return errors.New("register: product name or prefix " + strconv.Quote(s) + " is already used")
+			}
+		}
+	}
+
+	fields := make([]DBField, len(cols))
+	var added []registeredField
+	for i, col := range cols {
+		if f := dbFieldByName(col.name); f != 0 {
+			if f.column() != col.name {
+				return errors.New("register: column " + strconv.Quote(col.name) + " conflicts with " + f.column())
+			}
+			if t, _ := f.typ(); t != col.typ {
+				return errors.New("register: column " + strconv.Quote(col.name) + " does not match the built-in column type")
+			}
+			fields[i] = f
+		} else if f, ok := registry.fieldName[col.name]; ok {
+			if registry.field[f-dbField_extra-1].typ != col.typ {
+				return errors.New("register: column " + strconv.Quote(col.name) + " does not match the registered column type")
+			}
+			fields[i] = f
+		} else {
+			added = append(added, registeredField{col.name, col.typ})
+			fields[i] = dbField_extra + DBField(len(registry.field)+len(added))
+		}
+	}
+
+	if registry.product == nil {
+		registry.product = map[DBProduct]registeredProduct{}
+		registry.productName = map[string]DBProduct{}
+		registry.fieldName = map[string]DBField{}
+		registry.schema = map[DBProduct]map[DBType][]*dbS{}
+	}
+	if p.product() == "" {
+		registry.product[p] = registeredProduct{name, prefix}
+		registry.productName[strings.ToLower(name)] = p
+		registry.productName[strings.ToLower(prefix)] = p
+	}
+	for _, a := range added {
+		registry.field = append(registry.field, a)
+		registry.fieldName[a.name] = dbField_extra + DBField(len(registry.field))
+	}
+	if registry.schema[p] == nil {
+		registry.schema[p] = map[DBType][]*dbS{}
+	}
+	for i, t := range types {
+		s := new(dbS)
+		var c uint8 = 1
+		for j, col := range cols {
+			if n := col.column[i]; n != 0 {
+				fd := dbI{n, col.ptr, col.typ}
+				if f := fields[j]; f <= dbFieldMax {
+					s.f[f] = fd
+				} else {
+					for DBField(len(s.x)) < f-dbField_extra {
+						s.x = append(s.x, dbI{})
+					}
+					s.x[f-dbField_extra-1] = fd
+				}
+				if n > c {
+					c = n
+				}
+			}
+		}
+		s.f[dbField_extra] = dbI{c, uint8(p), uint8(t)}
+
+		ss := registry.schema[p][t]
+		for j, x := range ss {
+			if x.f[dbField_extra].col == c {
+				ss = append(ss[:j:j], ss[j+1:]...)
+				break
+			}
+		}
+		registry.schema[p][t] = append(ss, s)
+	}
+	return nil
+}
+
+type registeredColumn struct {
+	name   string
+	typ    uint8
+	ptr    uint8
+	column []uint8 // [type index]
+}
+
+// parseRegisteredColumn parses a column line for n database types.
+func parseRegisteredColumn(words []string, n int) (col registeredColumn, err error) {
+	if len(words) < 2 {
+		return col, errors.New("expected column type and name")
+	}
+	typ, ptr := words[0], ""
+	if i := strings.IndexByte(typ, '@'); i != -1 {
+		typ, ptr = typ[:i], typ[i+1:]
+	}
+	var ok bool
+	if col.typ, ok = columnType(typ); !ok {
+		return col, errors.New("invalid column type " + strconv.Quote(words[0]))
+	}
+	if col.ptr = 0xFF; ptr != "" {
+		if v, err := strconv.ParseUint(ptr, 10, 8); err != nil || v == 0xFF {
+			return col, errors.New("invalid column pointer offset " + strconv.Quote(words[0]))
+		} else {
+			col.ptr = uint8(v)
+		}
+	} else if col.typ == dbtype_f64 || col.typ == dbtype_u128 {
+		return col, errors.New("column type " + strconv.Quote(typ) + " does not fit in a column and must be a pointer")
+	}
+	if col.name = words[1]; !isColumnName(col.name) {
+		return col, errors.New("invalid column name " + strconv.Quote(col.name))
+	}
+	if words = words[2:]; len(words) != n {
+		return col, errors.New("expected " + strconv.Itoa(n) + " column numbers for " + strconv.Quote(col.name) + ", got " + strconv.Itoa(len(words)))
+	}
+	col.column = make([]uint8, n)
+	for i, w := range words {
+		if w != "." {
+			if v, err := strconv.ParseUint(w, 10, 8); err != nil || v < 2 {
+				return col, errors.New("invalid column number " + strconv.Quote(w) + " for " + strconv.Quote(col.name) + " (column 1 is always ip_from)")
+			} else {
+				col.column[i] = uint8(v)
+			}
+		}
+	}
+	return col, nil
+}
+
+// checkRegisteredColumns ensures the columns for the ith database type are
+// sequential, and each is only used for a single value or pointer offset.
+func checkRegisteredColumns(cols []registeredColumn, i int) error {
+	var used [0x100]*registeredColumn
+	var max uint8
+	for j := range cols {
+		col := &cols[j]
+		n := col.column[i]
+		if n == 0 {
+			continue
+		}
+		if o := used[n]; o != nil {
+			if o.ptr == 0xFF || col.ptr == 0xFF || o.ptr == col.ptr {
+				return errors.New("column " + strconv.Itoa(int(n)) + " is used by both " + o.name + " and " + col.name)
+			}
+		}
+		used[n] = col
+		if n > max {
+			max = n
+		}
+	}
+	for n := 2; n < int(max); n++ {
+		if used[n] == nil {
+			return errors.New("column " + strconv.Itoa(n) + " is not mapped")
+		}
+	}
+	return nil
+}
+
+// columnType returns the dbtype for a column type name.
+func columnType(s string) (uint8, bool) {
+	switch s {
+	case "str":
+		return dbtype_str, true
+	case "f32":
+		return dbtype_f32, true
+	case "u32":
+		return dbtype_u32, true
+	case "i32":
+		return dbtype_i32, true
+	case "f64":
+		return dbtype_f64, true
+	case "u128":
+		return dbtype_u128, true
+	}
+	return 0, false
+}
+
+func isUpper(s string) bool {
+	for _, c := range s {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func isColumnName(s string) bool {
+	for i, c := range s {
+		if !(c == '_' || (c >= 'a' && c <= 'z') || (i != 0 && c >= '0' && c <= '9')) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// lookupSchema returns the layout for a database, preferring registered ones
+// with a matching number of columns.
+func lookupSchema(p DBProduct, t DBType, c uint8) *dbS {
+	registry.RLock()
+	ss := registry.schema[p][t]
+	registry.RUnlock()
+	for _, s := range ss {
+		if s.f[dbField_extra].col == c {
+			return s
+		}
+	}
+	if s := dbinfo(p, t); s != nil && s.f[dbField_extra].col != 0 {
+		return s
+	}
+	if len(ss) != 0 {
+		return ss[len(ss)-1]
+	}
+	return dbinfo(p, t)
+}
+
+// registeredSchemas returns all registered layouts for p, or for all products
+// if p is zero.
+func registeredSchemas(p DBProduct) (r []*dbS) {
+	registry.RLock()
+	defer registry.RUnlock()
+	for rp, ts := range registry.schema {
+		if p == 0 || p == rp {
+			for _, ss := range ts {
+				r = append(r, ss...)
+			}
+		}
+	}
+	sort.Slice(r, func(i, j int) bool {
+		_, pi, ti := r[i].Info()
+		_, pj, tj := r[j].Info()
+		return pi < pj || (pi == pj && ti < tj)
+	})
+	return
+}
+
+// registeredFields returns all registered fields.
+func registeredFields() []DBField {
+	registry.RLock()
+	defer registry.RUnlock()
+	fs := make([]DBField, len(registry.field))
+	for i := range fs {
+		fs[i] = dbField_extra + DBField(i+1)
+	}
+	return fs
+}
+
+// productByName is like dbProductByName, but also checks registered products.
+func productByName(s string) DBProduct {
+	if p := dbProductByName(s); p != 0 {
+		return p
+	}
+	registry.RLock()
+	defer registry.RUnlock()
+	return registry.productName[s]
+}
+
+// fieldByName is like dbFieldByName, but also checks registered fields.
+func fieldByName(s string) DBField {
+	if f := dbFieldByName(s); f != 0 {
+		return f
+	}
+	registry.RLock()
+	defer registry.RUnlock()
+	return registry.fieldName[s]
+}
+
+// name is like product, but also checks registered products.
+func (p DBProduct) name() string {
+	if s := p.product(); s != "" {
+		return s
+	}
+	registry.RLock()
+	defer registry.RUnlock()
+	return registry.product[p].name
+}
+
+// typePrefix is like prefix, but also checks registered products.
+func (p DBProduct) typePrefix() string {
+	if s := p.prefix(); s != "" {
+		return s
+	}
+	registry.RLock()
+	defer registry.RUnlock()
+	return registry.product[p].prefix
+}
+
+// registered returns the registered field information for f, if any.
+func (f DBField) registered() (r registeredField, ok bool) {
+	if f > dbField_extra {
+		registry.RLock()
+		defer registry.RUnlock()
+		if i := int(f - dbField_extra - 1); i < len(registry.field) {
+			r, ok = registry.field[i], true
+		}
+	}
+	return
+}
+
+// name is like column, but also checks registered fields.
+func (f DBField) name() string {
+	if s := f.column(); s != "" {
+		return s
+	}
+	r, _ := f.registered()
+	return r.name
+}
+
+// fieldType is like typ, but also checks registered fields.
+func (f DBField) fieldType() (uint8, bool) {
+	if t, ok := f.typ(); ok {
+		return t, ok
+	}
+	r, ok := f.registered()
+	return r.typ, ok
+}
@@ -0,0 +1,190 @@
+package ip2x
+
+import (
+	"math/big"
+)
+
+// Header contains the header of an IP2Location binary database.
+type Header struct {
+	Type        DBType
+	Columns     uint8 // including ip_from
+	Year        uint8 // since 2000
+	Month       uint8
+	Day         uint8
+	IPv4Count   uint32 // including the final row
+	IPv4Base    uint32 // 1-based offset
+	IPv6Count   uint32 // including the final row
+	IPv6Base    uint32 // 1-based offset
+	IPv4Index   uint32 // 1-based offset, or zero if not indexed
+	IPv6Index   uint32 // 1-based offset, or zero if not indexed
+	Product     DBProduct
+	ProductType uint8
+	FileSize    uint32
+}
+
+// Header returns the database header.
+func (db *DB) Header() Header {
+	return Header{
+		Type:        db.dbtype,
+		Columns:     db.dbcolumn,
+		Year:        db.dbyear,
+		Month:       db.dbmonth,
+		Day:         db.dbday,
+		IPv4Count:   db.ip4count,
+		IPv4Base:    db.ip4base,
+		IPv6Count:   db.ip6count,
+		IPv6Base:    db.ip6base,
+		IPv4Index:   db.ip4idx,
+		IPv6Index:   db.ip6idx,
+		Product:     db.prcode,
+		ProductType: db.prtype,
+		FileSize:    db.filesize,
+	}
+}
+
+// Stats contains information about the layout and contents of a database.
+type Stats struct {
+	IPv4Rows    int // excluding the final row
+	IPv6Rows    int // excluding the final row
+	IPv4Index   bool
+	IPv6Index   bool
+	IPv4RowSize int // bytes
+	IPv6RowSize int // bytes
+
+	// The number of addresses in rows containing at least one string field
+	// which is not empty or "-".
+	IPv4Addresses uint64
+	IPv6Addresses *big.Int
+
+	// The number of bytes between the start of the first string and the end
+	// of the last one referenced by the rows.
+	StringHeapSize int64
+
+	// The number of distinct values for each field in the database. For
+	// pointer fields, this is the number of distinct pointers.
+	Distinct map[DBField]int
+}
+
+// Stats reads the entire database to compute statistics about it. This is
+// slow, and will read every row and every distinct string once.
+func (db *DB) Stats() (Stats, error) {
+	st := Stats{
+		IPv4Rows:      int(db.ip4count),
+		IPv6Rows:      int(db.ip6count),
+		IPv4Index:     db.ip4idx != 0,
+		IPv6Index:     db.ip6idx != 0,
+		IPv4RowSize:   4 + int(db.dbcolumn-1)*4,
+		IPv6RowSize:   16 + int(db.dbcolumn-1)*4,
+		IPv6Addresses: new(big.Int),
+		Distinct:      map[DBField]int{},
+	}
+	if st.IPv4Rows != 0 {
+		st.IPv4Rows--
+	}
+	if st.IPv6Rows != 0 {
+		st.IPv6Rows--
+	}
+	if db.s == nil {
+		return st, nil
+	}
+
+	var (
+		fields   []DBField
+		distinct = map[uint8]map[uint32]struct{}{} // [column][value]
+		empty    = map[uint32]bool{}               // [string offset]
+		heapLo   = int64(-1)
+		heapHi   = int64(-1)
+		v4       uint64
+		v6       uint128
+		buf      [getbufSize]byte
+		rerr     error
+	)
+	db.EachField(func(f DBField) bool {
+		fields = append(fields, f)
+		if c := db.s.Field(f).Column(); distinct[c] == nil {
+			distinct[c] = map[uint32]struct{}{}
+		}
+		return true
+	})
+	fn := func(rg Range, r Record) bool {
+		nonempty := false
+		for _, f := range fields {
+			fd := r.s.Field(f)
+			v := as_le_u32(r.d[(int(fd.Column())-2)*4:])
+			distinct[fd.Column()][v] = struct{}{}
+			if ^fd.PtrOffset() != 0 && fd.Type() == dbtype_str {
+				off := v + uint32(fd.PtrOffset())
+				e, ok := empty[off]
+				if !ok {
+					dt, _, err := r.getb(f, buf[:])
+					if dt == nil && err != nil {
+						rerr = err
+						return false
+					}
+					e = len(dt) == 0 || (len(dt) == 1 && dt[0] == '-')
+					empty[off] = e
+					if heapLo == -1 || int64(off) < heapLo {
+						heapLo = int64(off)
+					}
+					if end := int64(off) + 1 + int64(len(dt)); end > heapHi {
+						heapHi = end
+					}
+				}
+				nonempty = nonempty || !e
+			}
+		}
+		if nonempty {
+			a, b := as_ip6_uint128(rg.From), as_ip6_uint128(rg.To)
+			if rg.From.Is4() {
+				v4 += b.lo - a.lo
+			} else {
+				v6 = v6.add(b.sub(a))
+			}
+		}
+		return true
+	}
+	for _, ip6 := range []bool{false, true} {
+		if ok, err := db.each(ip6, fn); !ok {
+			if err == nil {
+				err = rerr
+			}
+			return st, err
+		}
+	}
+	st.IPv4Addresses = v4
+	st.IPv6Addresses = v6.big()
+	if heapLo != -1 {
+		st.StringHeapSize = heapHi - heapLo
+	}
+	for _, f := range fields {
+		st.Distinct[f] = len(distinct[db.s.Field(f).Column()])
+	}
+	return st, nil
+}
+
+// add returns n + v, wrapping on overflow.
+func (n uint128) add(v uint128) uint128 {
+	lo := n.lo + v.lo
+	hi := n.hi + v.hi
+	if lo < n.lo {
+		hi++
+	}
+	return uint128{hi: hi, lo: lo}
+}
+
+// sub returns n - v, wrapping on underflow.
+func (n uint128) sub(v uint128) uint128 {
+	lo := n.lo - v.lo
+	hi := n.hi - v.hi
+	if lo > n.lo {
+		hi--
+	}
+	return uint128{hi: hi, lo: lo}
+}
+
+// big returns n as a new big.Int.
+func (n uint128) big() *big.Int {
+	v := new(big.Int).SetUint64(n.hi)
+	v.Lsh(v, 64)
+	return v.Or(v, new(big.Int).SetUint64(n.lo))
+}
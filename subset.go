@@ -0,0 +1,122 @@
+package ip2x
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"time"
+)
+
+// Subset describes a subset of a database to write using [Subset.Build],
+// [Subset.WriteBIN], or [Subset.WriteNative]. The output uses the smallest type
+// of the same product containing the selected fields (see [MinimalType]), and
+// adjacent rows which have the same values for the selected fields are merged.
+type Subset struct {
+	// Fields to keep, which must all be in the database. If empty, all fields
+	// are kept. Other fields in the output type are empty.
+	Fields []DBField
+
+	// Filter returns whether to keep a row. If nil, all rows are kept. Since
+	// the BIN format cannot represent missing rows, rows which aren't kept are
+	// written as rows with empty values.
+	Filter func(Range, Record) bool
+
+	// IPv4Only and IPv6Only only keep the IPv4 or IPv6 table.
+	IPv4Only bool
+	IPv6Only bool
+}
+
+// Build reads the subset of db into a new writer.
+func (s *Subset) Build(db *DB) (*BINWriter, error) {
+	if db.s == nil {
+		return nil, errors.New("database does not have a layout")
+	}
+	if s.IPv4Only && s.IPv6Only {
+		return nil, errors.New("cannot keep only both ipv4 and ipv6")
+	}
+	fields := s.Fields
+	if len(fields) == 0 {
+		db.EachField(func(f DBField) bool {
+			fields = append(fields, f)
+			return true
+		})
+	}
+	for _, f := range fields {
+		if !db.Has(f) {
+			return nil, errors.New("database does not have field " + f.String())
+		}
+	}
+	t, ok := MinimalType(db.prcode, fields...)
+	if !ok {
+		t = db.dbtype
+	}
+	w, err := NewBINWriter(db.prcode, t)
+	if err != nil {
+		return nil, err
+	}
+	w.SetDate(time.Date(2000+int(db.dbyear), time.Month(db.dbmonth), int(db.dbday), 0, 0, 0, 0, time.UTC))
+
+	var (
+		c    = newCoalescer(db, fields)
+		werr error
+	)
+	for _, v6 := range []bool{false, true} {
+		add := func(rg Range, r Record, ok bool) {
+			if ok && werr == nil {
+				vals := make(map[DBField]any, len(fields))
+				for _, f := range fields {
+					vals[f] = r.Get(f)
+				}
+				werr = w.add(v6, rg, vals) // the ipv6 table may have ipv4-mapped rows
+			}
+		}
+		if (v6 && s.IPv4Only) || (!v6 && s.IPv6Only) {
+			continue
+		}
+		if _, err := db.each(v6, func(rg Range, r Record) bool {
+			if s.Filter != nil && !s.Filter(rg, r) {
+				add(c.flush())
+			} else {
+				add(c.add(rg, r))
+			}
+			return werr == nil && c.err == nil
+		}); err != nil {
+			return nil, err
+		}
+		if add(c.flush()); c.err != nil {
+			return nil, c.err
+		}
+		if werr != nil {
+			return nil, werr
+		}
+	}
+	return w, nil
+}
+
+// WriteBIN writes the subset of db to dst as a BIN database.
+func (s *Subset) WriteBIN(dst io.Writer, db *DB) error {
+	w, err := s.Build(db)
+	if err != nil {
+		return err
+	}
+	_, err = w.WriteTo(dst)
+	return err
+}
+
+// WriteNative writes the subset of db to dst as a native database (see
+// [WriteNative]).
+func (s *Subset) WriteNative(dst io.Writer, db *DB) error {
+	w, err := s.Build(db)
+	if err != nil {
+		return err
+	}
+	b, err := w.Bytes()
+	if err != nil {
+		return err
+	}
+	sdb, err := New(bytes.NewReader(b), Raw())
+	if err != nil {
+		return err
+	}
+	return WriteNative(dst, sdb)
+}
@@ -0,0 +1,677 @@
+package test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pg9182/ip2x"
+)
+
+func TestParse(t *testing.T) {
+	for _, f := range ip2x.AllFields() {
+		for _, s := range []string{f.String(), f.GoString()} {
+			if strings.HasPrefix(s, "DBField(") {
+				continue // registered
+			}
+			if v, err := ip2x.ParseDBField(s); err != nil || v != f {
+				t.Errorf("parse field %q: expected %#v, got %#v (err: %v)", s, f, v, err)
+			}
+		}
+		var v ip2x.DBField
+		if b, err := f.MarshalText(); err != nil {
+			t.Errorf("marshal field %#v: %v", f, err)
+		} else if err := v.UnmarshalText(b); err != nil || v != f {
+			t.Errorf("unmarshal field %q: expected %#v, got %#v (err: %v)", b, f, v, err)
+		}
+	}
+	if _, err := ip2x.ParseDBField("nonexistent"); err == nil {
+		t.Errorf("expected error for nonexistent field")
+	}
+	for s, exp := range map[string]ip2x.DBProduct{
+		"IP2Location": ip2x.IP2Location,
+		"ip2proxy":    ip2x.IP2Proxy,
+		"DB":          ip2x.IP2Location,
+		"px":          ip2x.IP2Proxy,
+	} {
+		if v, err := ip2x.ParseDBProduct(s); err != nil || v != exp {
+			t.Errorf("parse product %q: expected %#v, got %#v (err: %v)", s, exp, v, err)
+		}
+	}
+	for s, exp := range map[string]ip2x.DBType{
+		"11":   11,
+		"DB11": 11,
+		"px2":  2,
+	} {
+		var v ip2x.DBType
+		if err := v.UnmarshalText([]byte(s)); err != nil || v != exp {
+			t.Errorf("unmarshal type %q: expected %d, got %d (err: %v)", s, exp, v, err)
+		}
+	}
+	var fs ip2x.DBFields
+	if err := fs.Set("country_code, City"); err != nil {
+		t.Errorf("set fields: %v", err)
+	} else if err := fs.Set("latitude"); err != nil {
+		t.Errorf("set fields: %v", err)
+	} else if exp := (ip2x.DBFields{ip2x.CountryCode, ip2x.City, ip2x.Latitude}); !reflect.DeepEqual(fs, exp) {
+		t.Errorf("set fields: expected %v, got %v", exp, fs)
+	} else if s := fs.String(); s != "country_code,city,latitude" {
+		t.Errorf("format fields: got %q", s)
+	}
+}
+
+func TestFieldMetadata(t *testing.T) {
+	if k := ip2x.CountryCode.Kind(); k != ip2x.KindString {
+		t.Errorf("country_code: expected kind %s, got %s", ip2x.KindString, k)
+	}
+	if k := ip2x.Latitude.Kind(); k != ip2x.KindFloat32 {
+		t.Errorf("latitude: expected kind %s, got %s", ip2x.KindFloat32, k)
+	}
+	if d := ip2x.City.Description(); d != "City name." {
+		t.Errorf("city: unexpected description %q", d)
+	}
+	if p := ip2x.FraudScore.Products(); !reflect.DeepEqual(p, map[ip2x.DBProduct][]ip2x.DBType{ip2x.IP2Proxy: {12}}) {
+		t.Errorf("fraud_score: unexpected products %v", p)
+	}
+	for _, c := range []struct {
+		p      ip2x.DBProduct
+		fields []ip2x.DBField
+		t      ip2x.DBType
+	}{
+		{ip2x.IP2Location, nil, 1},
+		{ip2x.IP2Location, []ip2x.DBField{ip2x.CountryCode}, 1},
+		{ip2x.IP2Location, []ip2x.DBField{ip2x.ISP}, 2},
+		{ip2x.IP2Location, []ip2x.DBField{ip2x.Latitude}, 5},
+		{ip2x.IP2Location, []ip2x.DBField{ip2x.Timezone}, 11},
+		{ip2x.IP2Location, []ip2x.DBField{ip2x.ASN}, 26},
+		{ip2x.IP2Proxy, []ip2x.DBField{ip2x.ProxyType, ip2x.ASN}, 7},
+		{ip2x.IP2Location, []ip2x.DBField{ip2x.ProxyType}, 0},
+	} {
+		if v, ok := ip2x.MinimalType(c.p, c.fields...); v != c.t || ok != (c.t != 0) {
+			t.Errorf("minimal type for %s %v: expected %d, got %d (ok=%t)", c.p, c.fields, c.t, v, ok)
+		}
+	}
+}
+
+func TestStats(t *testing.T) {
+	h := IP2x_DB.Header()
+	if p, ty := IP2x_DB.Info(); h.Product != p || h.Type != ty {
+		t.Errorf("header: product/type mismatch: %+v", h)
+	}
+	st, err := IP2x_DB.Stats()
+	if err != nil {
+		t.Fatalf("stats: %v", err)
+	}
+	var n4, n6 int
+	IP2x_DB.Each(func(r ip2x.Range, _ ip2x.Record) bool {
+		if r.From.Is4() {
+			n4++
+		} else {
+			n6++
+		}
+		return true
+	})
+	if st.IPv4Rows != n4 || st.IPv6Rows != n6 {
+		t.Errorf("stats: expected %d/%d rows, got %d/%d", n4, n6, st.IPv4Rows, st.IPv6Rows)
+	}
+	if st.IPv4Index != (h.IPv4Index != 0) || st.IPv6Index != (h.IPv6Index != 0) {
+		t.Errorf("stats: index presence mismatch")
+	}
+	if st.IPv4Addresses == 0 || st.IPv6Addresses.Sign() <= 0 || st.StringHeapSize <= 0 {
+		t.Errorf("stats: expected non-zero coverage and string heap, got %+v", st)
+	}
+	IP2x_DB.EachField(func(f ip2x.DBField) bool {
+		if st.Distinct[f] == 0 {
+			t.Errorf("stats: expected distinct values for %s", f)
+		}
+		return true
+	})
+}
+
+func TestMaxAge(t *testing.T) {
+	if d := IP2x_DB.Date(); d.Format("2006-01-02") != IP2x_DB.Version() {
+		t.Errorf("date: expected %s, got %s", IP2x_DB.Version(), d)
+	}
+	if a := IP2x_DB.Age(IP2x_DB.Date().Add(time.Hour)); a != time.Hour {
+		t.Errorf("age: expected 1h, got %s", a)
+	}
+	if _, err := ip2x.New(DB, ip2x.MaxAge(time.Since(IP2x_DB.Date())+time.Hour, nil)); err != nil {
+		t.Errorf("max age: unexpected error: %v", err)
+	}
+	var serr *ip2x.StaleError
+	if _, err := ip2x.New(DB, ip2x.MaxAge(time.Nanosecond, nil)); !errors.As(err, &serr) {
+		t.Errorf("max age: expected stale error, got %v", err)
+	}
+	var warned error
+	if _, err := ip2x.New(DB, ip2x.MaxAge(time.Nanosecond, func(err error) { warned = err })); err != nil || !errors.As(warned, &serr) {
+		t.Errorf("max age: expected warning, got %v (err: %v)", warned, err)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	if err := ip2x.Register(`
+		200   Example  EX  1
+		str@0 country_code 2
+		f32   test_score   3
+		str@0 test_label   4
+	`); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	for _, spec := range []string{
+		"201 IP2Location DB 1\nstr@0 country_code 2", // conflicting name
+		"1 IP2Location DB 27\nf32 country_code 2",    // conflicting type
+		"1 IP2Location DB 27\nstr@0 country_code 3",  // gap
+		"202 Other OT 1\nstr@0 country_code 2 3",     // extra column
+		"202 Other OT 1\nf32 test_score 2\nf32 x 2",  // reused value column
+		"202 Other OT 1\nstr@0 test_score 2",         // registered type mismatch
+		"202 Other OT 1\nint test_other 2",           // unknown type
+	} {
+		if err := ip2x.Register(spec); err == nil {
+			t.Errorf("register %q: expected error", spec)
+		}
+	}
+
+	score, err := ip2x.ParseDBField("test_score")
+	if err != nil {
+		t.Fatalf("parse registered field: %v", err)
+	}
+	label, err := ip2x.ParseDBField("TEST_LABEL")
+	if err != nil {
+		t.Fatalf("parse registered field: %v", err)
+	}
+	if score.String() != "test_score" || score.Kind() != ip2x.KindFloat32 || label.Kind() != ip2x.KindString {
+		t.Errorf("registered field metadata: %s %s %s", score, score.Kind(), label.Kind())
+	}
+	if p, err := ip2x.ParseDBProduct("ex"); err != nil || p != 200 || p.String() != "Example" {
+		t.Errorf("parse registered product: got %d %q (err: %v)", p, p, err)
+	}
+	if p := label.Products(); !reflect.DeepEqual(p, map[ip2x.DBProduct][]ip2x.DBType{200: {1}}) {
+		t.Errorf("registered field products: %v", p)
+	}
+	if v, ok := ip2x.MinimalType(200, label); !ok || v != 1 {
+		t.Errorf("minimal registered type: got %d (ok=%t)", v, ok)
+	}
+
+	db, err := ip2x.New(bytes.NewReader(mkTestBIN(200)))
+	if err != nil {
+		t.Fatalf("open registered database: %v", err)
+	}
+	if s := db.String(); s != "Example EX1 2025-01-01 [country_code,test_score,test_label] (IPv4)" {
+		t.Errorf("unexpected database string %q", s)
+	}
+	for ip, exp := range map[string][3]any{
+		"1.2.3.4":  {"US", float32(1.5), "a"},
+		"10.1.2.3": {"CA", float32(-2), "c"},
+	} {
+		r, err := db.LookupString(ip)
+		if err != nil {
+			t.Fatalf("lookup %s: %v", ip, err)
+		}
+		if v := [3]any{r.Get(ip2x.CountryCode), r.Get(score), r.Get(label)}; v != exp {
+			t.Errorf("lookup %s: expected %v, got %v", ip, exp, v)
+		}
+	}
+}
+
+func TestRegisterWide(t *testing.T) {
+	// more columns than fit in a uint8 offset calculation
+	spec := "205 Wide WD 1\n"
+	for c := 2; c <= 70; c++ {
+		spec += "u32 test_wide_" + strconv.Itoa(c) + " " + strconv.Itoa(c) + "\n"
+	}
+	if err := ip2x.Register(spec); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	cols := map[int]ip2x.DBField{}
+	for c := 2; c <= 70; c++ {
+		f, err := ip2x.ParseDBField("test_wide_" + strconv.Itoa(c))
+		if err != nil {
+			t.Fatalf("parse registered field: %v", err)
+		}
+		cols[c] = f
+	}
+
+	w, err := ip2x.NewBINWriter(205, 1)
+	if err != nil {
+		t.Fatalf("writer: %v", err)
+	}
+	for i, p := range []string{"1.2.3.0/24", "1.2.4.0/24"} {
+		vals := map[ip2x.DBField]any{}
+		for c, f := range cols {
+			vals[f] = uint32(c * 100)
+			if i == 1 && c >= 66 {
+				vals[f] = uint32(c*100 + 1)
+			}
+		}
+		if err := w.AddPrefix(netip.MustParsePrefix(p), vals); err != nil {
+			t.Fatalf("add: %v", err)
+		}
+	}
+	b, err := w.Bytes()
+	if err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	db, err := ip2x.New(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	for i, ip := range []string{"1.2.3.4", "1.2.4.4"} {
+		r, err := db.LookupString(ip)
+		if err != nil {
+			t.Fatalf("lookup %s: %v", ip, err)
+		}
+		for c := 2; c <= 70; c++ {
+			exp := uint32(c * 100)
+			if i == 1 && c >= 66 {
+				exp++
+			}
+			if v, ok := r.GetUint32(cols[c]); !ok || v != exp {
+				t.Errorf("lookup %s: column %d: expected %d, got %d", ip, c, exp, v)
+			}
+		}
+	}
+	st, err := db.Stats()
+	if err != nil {
+		t.Fatalf("stats: %v", err)
+	}
+	if a, b := st.Distinct[cols[2]], st.Distinct[cols[70]]; b != a+1 {
+		t.Errorf("stats: expected %d distinct values in column 70, got %d", a+1, b)
+	}
+}
+
+func TestNumericColumns(t *testing.T) {
+	if err := ip2x.Register(`
+		203    Numbers     NU  1
+		u32    test_count  2
+		i32    test_delta  3
+		f64@0  test_ratio  4
+		u128@0 test_big    5
+		f32    test_last   6
+	`); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	for _, spec := range []string{
+		"204 Other OT 1\nf64 test_other 2",  // inline f64
+		"204 Other OT 1\nu128 test_other 2", // inline u128
+	} {
+		if err := ip2x.Register(spec); err == nil {
+			t.Errorf("register %q: expected error", spec)
+		}
+	}
+	fields := make([]ip2x.DBField, 5)
+	for i, n := range []string{"test_count", "test_delta", "test_ratio", "test_big", "test_last"} {
+		f, err := ip2x.ParseDBField(n)
+		if err != nil {
+			t.Fatalf("parse registered field: %v", err)
+		}
+		fields[i] = f
+	}
+	count, delta, ratio, big, last := fields[0], fields[1], fields[2], fields[3], fields[4]
+	if k := [...]ip2x.Kind{count.Kind(), delta.Kind(), ratio.Kind(), big.Kind(), last.Kind()}; k != [...]ip2x.Kind{ip2x.KindUint32, ip2x.KindInt32, ip2x.KindFloat64, ip2x.KindUint128, ip2x.KindFloat32} {
+		t.Errorf("unexpected kinds %v", k)
+	}
+
+	// 64-byte header, 3 rows (including the final one) of 6 columns, then
+	// values
+	b := make([]byte, 64, 256)
+	b[0], b[1], b[2], b[3], b[4] = 1, 6, 25, 1, 1
+	binary.LittleEndian.PutUint32(b[5:], 3)
+	binary.LittleEndian.PutUint32(b[9:], 65)
+	b[29], b[30] = 203, 1
+	heap := []byte{}
+	addval := func(v []byte) uint32 {
+		off := uint32(64 + 3*24 + len(heap))
+		heap = append(heap, v...)
+		return off
+	}
+	f64 := func(v float64) uint32 {
+		var x [8]byte
+		binary.LittleEndian.PutUint64(x[:], math.Float64bits(v))
+		return addval(x[:])
+	}
+	u128 := func(hi, lo uint64) uint32 {
+		var x [16]byte
+		binary.LittleEndian.PutUint64(x[:8], lo)
+		binary.LittleEndian.PutUint64(x[8:], hi)
+		return addval(x[:])
+	}
+	row := func(v ...uint32) {
+		for _, v := range v {
+			b = append(b, 0, 0, 0, 0)
+			binary.LittleEndian.PutUint32(b[len(b)-4:], v)
+		}
+	}
+	row(0, 7, uint32(0xfffffffd), f64(0.25), u128(1, 5), math.Float32bits(2.5))
+	row(0x0a000000, 4000000000, 12, f64(-1e300), u128(0, 42), math.Float32bits(-0.5))
+	row(0xffffffff, 0, 0, 0, 0, 0)
+	b = append(b, heap...)
+	binary.LittleEndian.PutUint32(b[31:], uint32(len(b)))
+
+	db, err := ip2x.New(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+
+	r, err := db.LookupString("1.2.3.4")
+	if err != nil {
+		t.Fatalf("lookup: %v", err)
+	}
+	if v := r.Get(count); v != uint32(7) {
+		t.Errorf("get u32: got %#v", v)
+	}
+	if v := r.Get(delta); v != int32(-3) {
+		t.Errorf("get i32: got %#v", v)
+	}
+	if v := r.Get(ratio); v != 0.25 {
+		t.Errorf("get f64: got %#v", v)
+	}
+	if v, ok := r.Get(big).(interface{ String() string }); !ok || v.String() != "18446744073709551621" {
+		t.Errorf("get u128: got %#v", r.Get(big))
+	}
+	if v := r.Get(last); v != float32(2.5) {
+		t.Errorf("get last f32: got %#v", v)
+	}
+	if v, ok := r.GetUint32(delta); ok {
+		t.Errorf("get negative i32 as uint32: expected failure, got %d", v)
+	}
+	if v, ok := r.GetInt32(count); !ok || v != 7 {
+		t.Errorf("get u32 as int32: got %d (ok=%t)", v, ok)
+	}
+	if v, ok := r.GetUint32(big); ok {
+		t.Errorf("get large u128 as uint32: expected failure, got %d", v)
+	}
+	if v, ok := r.GetFloat64(delta); !ok || v != -3 {
+		t.Errorf("get i32 as float64: got %v (ok=%t)", v, ok)
+	}
+	if v, ok := r.GetString(ratio); !ok || v != "0.25" {
+		t.Errorf("get f64 as string: got %q (ok=%t)", v, ok)
+	}
+	if v, err := r.MarshalJSON(); err != nil || string(v) != `{"test_count":7,"test_delta":-3,"test_ratio":0.25,"test_big":18446744073709551621,"test_last":2.5}` {
+		t.Errorf("json: got %s (err: %v)", v, err)
+	}
+	if v, err := r.AppendCBOR(nil, delta, big); err != nil || !bytes.Equal(v, []byte("\xa2\x6atest_delta\x22\x68test_big\xc2\x49\x01\x00\x00\x00\x00\x00\x00\x00\x05")) {
+		t.Errorf("cbor: got %x (err: %v)", v, err)
+	}
+	if v, err := r.AppendMsgpack(nil, delta, big); err != nil || !bytes.Equal(v, []byte("\x82\xaatest_delta\xd2\xff\xff\xff\xfd\xa8test_big\xb418446744073709551621")) {
+		t.Errorf("msgpack: got %x (err: %v)", v, err)
+	}
+
+	r, err = db.LookupString("10.1.2.3")
+	if err != nil {
+		t.Fatalf("lookup: %v", err)
+	}
+	if v, ok := r.GetInt32(count); ok {
+		t.Errorf("get large u32 as int32: expected failure, got %d", v)
+	}
+	if v, ok := r.GetUint32(big); !ok || v != 42 {
+		t.Errorf("get u128 as uint32: got %d (ok=%t)", v, ok)
+	}
+	if v, err := r.AppendCSV(nil); err != nil || string(v) != "4000000000,12,-1"+strings.Repeat("0", 300)+",42,-0.5" {
+		t.Errorf("csv: got %s (err: %v)", v, err)
+	}
+	if v, err := r.AppendCBOR(nil, count, ratio, big); err != nil || !bytes.Equal(v, []byte("\xa3\x6atest_count\x1a\xee\x6b\x28\x00\x6atest_ratio\xfb\xfe\x37\xe4\x3c\x88\x00\x75\x9c\x68test_big\x18\x2a")) {
+		t.Errorf("cbor: got %x (err: %v)", v, err)
+	}
+
+	var nb bytes.Buffer
+	if err := ip2x.WriteNative(&nb, db); err != nil {
+		t.Fatalf("convert: %v", err)
+	}
+	ndb, err := ip2x.New(bytes.NewReader(nb.Bytes()))
+	if err != nil {
+		t.Fatalf("open native: %v", err)
+	}
+	for _, ip := range []string{"1.2.3.4", "10.1.2.3"} {
+		r, _ := db.LookupString(ip)
+		nr, err := ndb.LookupString(ip)
+		if err != nil {
+			t.Fatalf("lookup native: %v", err)
+		}
+		if a, b := r.String(), nr.String(); a != b {
+			t.Errorf("native: expected %s, got %s", a, b)
+		}
+	}
+}
+
+func TestRaw(t *testing.T) {
+	if _, err := ip2x.New(bytes.NewReader(mkTestBIN(201))); err == nil {
+		t.Fatalf("expected error for unknown product")
+	}
+	db, err := ip2x.New(bytes.NewReader(mkTestBIN(201)), ip2x.Raw())
+	if err != nil {
+		t.Fatalf("open raw database: %v", err)
+	}
+	if h := db.Header(); h.Product != 201 || h.Columns != 4 {
+		t.Errorf("unexpected header %+v", h)
+	}
+	r, err := db.LookupString("10.1.2.3")
+	if err != nil || !r.IsValid() {
+		t.Fatalf("lookup: %v", err)
+	}
+	if _, ok := r.Column(1); ok {
+		t.Errorf("expected no value for ip_from column")
+	}
+	if _, ok := r.Column(5); ok {
+		t.Errorf("expected no value for out of range column")
+	}
+	if v, ok := r.ColumnFloat32(3); !ok || v != -2 {
+		t.Errorf("column 3: expected -2, got %v", v)
+	}
+	if v, err := r.ColumnString(2, 0); err != nil || v != "CA" {
+		t.Errorf("column 2: expected CA, got %q (err: %v)", v, err)
+	}
+	if v, err := r.ColumnString(4, 0); err != nil || v != "c" {
+		t.Errorf("column 4: expected c, got %q (err: %v)", v, err)
+	}
+	if v, err := r.ColumnString(4, 1); err == nil {
+		t.Errorf("column 4+1: expected error, got %q", v)
+	}
+}
+
+// mkTestBIN makes a small IPv4-only database with the specified product code,
+// type 1, and the following columns:
+//
+//	str@0 country_code 2
+//	f32   test_score   3
+//	str@0 test_label   4
+func mkTestBIN(product uint8) []byte {
+	// 64-byte header, 3 rows (including the final one), then strings
+	b := make([]byte, 64, 256)
+	b[0], b[1], b[2], b[3], b[4] = 1, 4, 25, 1, 1
+	binary.LittleEndian.PutUint32(b[5:], 3)
+	binary.LittleEndian.PutUint32(b[9:], 65)
+	b[29], b[30] = product, 1
+	heap := []byte{}
+	addstr := func(s string) uint32 {
+		off := uint32(64 + 3*16 + len(heap))
+		heap = append(append(heap, byte(len(s))), s...)
+		return off
+	}
+	row := func(ip uint32, cc uint32, score float32, label uint32) {
+		for _, v := range []uint32{ip, cc, math.Float32bits(score), label} {
+			b = append(b, 0, 0, 0, 0)
+			binary.LittleEndian.PutUint32(b[len(b)-4:], v)
+		}
+	}
+	us, ca, a, c := addstr("US"), addstr("CA"), addstr("a"), addstr("c")
+	row(0, us, 1.5, a)
+	row(0x0a000000, ca, -2, c)
+	row(0xffffffff, 0, 0, 0)
+	b = append(b, heap...)
+	binary.LittleEndian.PutUint32(b[31:], uint32(len(b)))
+	return b
+}
+
+func TestTypedRecord(t *testing.T) {
+	for _, ip := range ips {
+		r, err := IP2x_DB.Lookup(ip)
+		if err != nil {
+			t.Fatalf("lookup %s: %v", ip, err)
+		}
+		if !r.IsValid() {
+			continue
+		}
+		if v, ok := r.CountryCode(); !ok || v != r.Get(ip2x.CountryCode) {
+			t.Errorf("lookup %s: country code accessor: got %q", ip, v)
+		}
+		if v, ok := r.Latitude(); !ok || v != r.Get(ip2x.Latitude) {
+			t.Errorf("lookup %s: latitude accessor: got %v", ip, v)
+		}
+		if _, ok := r.MCC(); ok {
+			t.Errorf("lookup %s: expected mcc accessor to fail", ip)
+		}
+		v, err := r.ToDB11()
+		if err != nil {
+			t.Fatalf("lookup %s: to db11: %v", ip, err)
+		}
+		if v.City != r.Get(ip2x.City) || v.Timezone != r.Get(ip2x.Timezone) || v.Longitude != r.Get(ip2x.Longitude) {
+			t.Errorf("lookup %s: to db11: got %+v", ip, v)
+		}
+		if _, err := r.ToDB3(); err != nil {
+			t.Errorf("lookup %s: to db3: %v", ip, err)
+		}
+		if _, err := r.ToDB26(); err == nil {
+			t.Errorf("lookup %s: expected error converting to db26", ip)
+		}
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	buf, err := os.ReadFile(filepath.Join("..", "schema", "DB11.schema.json"))
+	if err != nil {
+		t.Fatalf("read schema: %v", err)
+	}
+	var schema struct {
+		Properties map[string]struct {
+			Type string `json:"type"`
+		} `json:"properties"`
+		Required []string `json:"required"`
+	}
+	if err := json.Unmarshal(buf, &schema); err != nil {
+		t.Fatalf("parse schema: %v", err)
+	}
+	r, err := IP2x_DB.LookupString("8.8.8.8")
+	if err != nil || !r.IsValid() {
+		t.Fatalf("lookup: %v", err)
+	}
+	buf, err = r.MarshalJSON()
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var obj map[string]any
+	if err := json.Unmarshal(buf, &obj); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(obj) != len(schema.Required) || len(obj) != len(schema.Properties) {
+		t.Errorf("expected %d properties, got %d", len(schema.Required), len(obj))
+	}
+	for k, v := range obj {
+		var typ string
+		switch v.(type) {
+		case string:
+			typ = "string"
+		case float64:
+			typ = "number"
+		}
+		if p, ok := schema.Properties[k]; !ok {
+			t.Errorf("unexpected property %q", k)
+		} else if p.Type != typ {
+			t.Errorf("property %q: expected type %s, got %s", k, p.Type, typ)
+		}
+	}
+}
+
+func TestJSONSchemaLayout(t *testing.T) {
+	buf, err := os.ReadFile(filepath.Join("..", "schema", "DB26.schema.json"))
+	if err != nil {
+		t.Fatalf("read schema: %v", err)
+	}
+	var schema struct {
+		Properties map[string]any `json:"properties"`
+		Required   []string       `json:"required"`
+	}
+	if err := json.Unmarshal(buf, &schema); err != nil {
+		t.Fatalf("parse schema: %v", err)
+	}
+
+	w, err := ip2x.NewBINWriter(ip2x.IP2Location, 26)
+	if err != nil {
+		t.Fatalf("create writer: %v", err)
+	}
+	w.SetIndex(false)
+	vals := map[ip2x.DBField]any{}
+	for _, f := range ip2x.AllFields() {
+		for _, typ := range f.Products()[ip2x.IP2Location] {
+			if typ == 26 && f.Kind() == ip2x.KindString {
+				vals[f] = "x"
+			}
+		}
+	}
+	if err := w.AddPrefix(netip.MustParsePrefix("1.2.3.0/24"), vals); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	b, err := w.Bytes()
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+
+	// DB26 from before September 2025 doesn't have the last three columns, so
+	// remove them from each row (pointers are absolute, so the rest of the
+	// file can stay where it is)
+	old := append([]byte(nil), b...)
+	old[1] -= 3
+	for _, x := range []struct{ count, base, iplen int }{
+		{int(binary.LittleEndian.Uint32(b[5:])), int(binary.LittleEndian.Uint32(b[9:])) - 1, 4},
+		{int(binary.LittleEndian.Uint32(b[13:])), int(binary.LittleEndian.Uint32(b[17:])) - 1, 16},
+	} {
+		from, to := x.iplen+int(b[1]-1)*4, x.iplen+int(old[1]-1)*4
+		for i := 0; i < x.count; i++ {
+			copy(old[x.base+i*to:], b[x.base+i*from:x.base+i*from+to])
+		}
+	}
+
+	for i, data := range [][]byte{b, old} {
+		db, err := ip2x.New(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("open: %v", err)
+		}
+		r, err := db.LookupString("1.2.3.4")
+		if err != nil || !r.IsValid() {
+			t.Fatalf("lookup: %v", err)
+		}
+		buf, err := r.MarshalJSON()
+		if err != nil {
+			t.Fatalf("marshal: %v", err)
+		}
+		var obj map[string]any
+		if err := json.Unmarshal(buf, &obj); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+		if v, _ := obj["as"].(string); v != "x" {
+			t.Errorf("%s: incorrect record %s", db, buf)
+		}
+		for _, k := range schema.Required {
+			if _, ok := obj[k]; !ok {
+				t.Errorf("%s: missing required property %q", db, k)
+			}
+		}
+		for k := range obj {
+			if _, ok := schema.Properties[k]; !ok {
+				t.Errorf("%s: unexpected property %q", db, k)
+			}
+		}
+		if _, ok := obj["as_domain"]; ok != (i == 0) {
+			t.Errorf("%s: expected as_domain only for the current layout", db)
+		}
+	}
+}
@@ -1,11 +1,14 @@
 package test
 
 import (
+	"bytes"
 	"fmt"
+	"net"
 	"os"
 	"testing"
 
 	"github.com/ip2location/ip2location-go/v9"
+	"github.com/oschwald/maxminddb-golang"
 	"github.com/pg9182/ip2x"
 )
 
@@ -133,3 +136,94 @@ func BenchmarkGetNonexistent(b *testing.B) {
 		}
 	})
 }
+
+func BenchmarkLookupIndex(b *testing.B) {
+	for _, c := range []struct {
+		Name string
+		Opt  []ip2x.Option
+	}{
+		{"index=file", nil},
+		{"index=preload", []ip2x.Option{ip2x.Preload()}},
+		{"index=20", []ip2x.Option{ip2x.IndexBits(20, 20)}},
+		{"index=24", []ip2x.Option{ip2x.IndexBits(24, 24)}},
+		{"index=eytzinger", []ip2x.Option{ip2x.EytzingerSearch()}},
+	} {
+		b.Run(c.Name, func(b *testing.B) {
+			db, err := ip2x.New(DB, c.Opt...)
+			if err != nil {
+				b.Fatalf("open: %v", err)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				db.Lookup(ips[i%len(ips)])
+			}
+		})
+	}
+}
+
+func BenchmarkMMDBLookup(b *testing.B) {
+	var export bytes.Buffer
+	if err := (&ip2x.MMDBWriter{}).Write(&export, IP2x_DB); err != nil {
+		b.Fatalf("export: %v", err)
+	}
+	names := func(s string) map[string]any {
+		m := map[string]any{}
+		for _, l := range []string{"de", "en", "es", "fr", "ja", "pt-BR", "ru", "zh-CN"} {
+			m[l] = s + " (" + l + ")"
+		}
+		return m
+	}
+	city := mmdbFile(map[string]any{
+		"city":      map[string]any{"geoname_id": uint32(1), "names": names("City")},
+		"continent": map[string]any{"code": "NA", "geoname_id": uint32(2), "names": names("Continent")},
+		"country":   map[string]any{"iso_code": "US", "geoname_id": uint32(3), "names": names("Country")},
+		"location": map[string]any{
+			"accuracy_radius": uint32(10),
+			"latitude":        float64(1.5),
+			"longitude":       float64(-2.5),
+			"time_zone":       "America/New_York",
+		},
+		"postal":             map[string]any{"code": "12345"},
+		"registered_country": map[string]any{"iso_code": "US", "geoname_id": uint32(3), "names": names("Country")},
+		"subdivisions": []any{
+			map[string]any{"iso_code": "NY", "geoname_id": uint32(4), "names": names("Region")},
+			map[string]any{"iso_code": "XX", "geoname_id": uint32(5), "names": names("County")},
+		},
+	})
+	for _, c := range []struct {
+		Name string
+		Data []byte
+	}{
+		{"data=export", export.Bytes()},
+		{"data=city", city},
+	} {
+		b.Run(c.Name, func(b *testing.B) {
+			b.Run("lib=ip2x", func(b *testing.B) {
+				m, err := ip2x.NewMMDB(bytes.NewReader(c.Data), int64(len(c.Data)), nil)
+				if err != nil {
+					b.Fatalf("open: %v", err)
+				}
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					r, _ := m.Lookup(ips[i%len(ips)])
+					r.GetString(ip2x.City)
+				}
+			})
+			b.Run("lib=maxminddb", func(b *testing.B) {
+				r, err := maxminddb.FromBytes(c.Data)
+				if err != nil {
+					b.Fatalf("open: %v", err)
+				}
+				var rec struct {
+					City struct {
+						Names map[string]string `maxminddb:"names"`
+					} `maxminddb:"city"`
+				}
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					r.Lookup(net.IP(ips[i%len(ips)].AsSlice()), &rec)
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// codegenTypes is a product table using every column type, appended to a copy
+// of dbdata.go by TestCodegen.
+const codegenTypes = `
+const TestCount codegen.Field = "test_count"
+const TestDelta codegen.Field = "test_delta"
+const TestRatio codegen.Field = "test_ratio"
+const TestBig codegen.Field = "test_big"
+
+// Test product.
+const TestTypes codegen.Product = ` + "`" + `
+250    TestTypes     TT  1  2
+str@0  country_code      2  2
+u32    test_count        3  3
+i32    test_delta        4  .
+f64@0  test_ratio        5  4
+u128@0 test_big          6  5
+f32    latitude          7  6
+` + "`\n"
+
+// codegenCheck uses the code generated for codegenTypes.
+const codegenCheck = `package main
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"net/netip"
+	"os"
+
+	"github.com/pg9182/ip2x"
+)
+
+func main() {
+	n, _ := new(big.Int).SetString("340282366920938463463374607431768211455", 10)
+	w, err := ip2x.NewBINWriter(ip2x.TestTypes, 1)
+	if err != nil {
+		panic(err)
+	}
+	if err := w.AddPrefix(netip.MustParsePrefix("1.2.3.0/24"), map[ip2x.DBField]any{
+		ip2x.CountryCode: "US",
+		ip2x.TestCount:   uint32(4000000000),
+		ip2x.TestDelta:   int32(-3),
+		ip2x.TestRatio:   0.25,
+		ip2x.TestBig:     n,
+		ip2x.Latitude:    float32(1.5),
+	}); err != nil {
+		panic(err)
+	}
+	b, err := w.Bytes()
+	if err != nil {
+		panic(err)
+	}
+	db, err := ip2x.New(bytes.NewReader(b))
+	if err != nil {
+		panic(err)
+	}
+	r, err := db.LookupString("1.2.3.4")
+	if err != nil {
+		panic(err)
+	}
+	var (
+		count, _ = r.TestCount()
+		delta, _ = r.TestDelta()
+		ratio, _ = r.TestRatio()
+		big, _   = r.TestBig()
+	)
+	v, err := r.ToTT1()
+	if err != nil {
+		panic(err)
+	}
+	j, err := r.MarshalJSON()
+	if err != nil {
+		panic(err)
+	}
+	fmt.Fprintf(os.Stdout, "%s %d %d %g %s\n%+v\n%s\n", db, count, delta, ratio, big, v, j)
+}
+`
+
+func TestCodegen(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping codegen test in short mode")
+	}
+
+	root, err := filepath.Abs("..")
+	if err != nil {
+		t.Fatalf("get root: %v", err)
+	}
+	dir := t.TempDir()
+	for _, pat := range []string{"go.mod", "*.go", filepath.Join("internal", "codegen", "*.go")} {
+		fs, err := filepath.Glob(filepath.Join(root, pat))
+		if err != nil || len(fs) == 0 {
+			t.Fatalf("copy %s: no files (err %v)", pat, err)
+		}
+		for _, f := range fs {
+			b, err := os.ReadFile(f)
+			if err != nil {
+				t.Fatalf("copy %s: %v", f, err)
+			}
+			rel, _ := filepath.Rel(root, f)
+			if rel == "dbdata.go" {
+				b = append(b, codegenTypes...)
+			}
+			if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(rel)), 0777); err != nil {
+				t.Fatalf("copy %s: %v", f, err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, rel), b, 0666); err != nil {
+				t.Fatalf("copy %s: %v", f, err)
+			}
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "check"), 0777); err != nil {
+		t.Fatalf("write check: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "check", "main.go"), []byte(codegenCheck), 0666); err != nil {
+		t.Fatalf("write check: %v", err)
+	}
+
+	gobin := filepath.Join(runtime.GOROOT(), "bin", "go")
+	run := func(args ...string) string {
+		cmd := exec.Command(gobin, args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOWORK=off")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("go %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+		return string(out)
+	}
+	run("run", "dbdata.go", "-jsonschema", "schema", "-proto", "schema/ip2x.proto", "-structs")
+	run("vet", ".")
+
+	out := run("run", "./check")
+	for _, exp := range []string{
+		"TestTypes TT1 ",
+		" 4000000000 -3 0.25 340282366920938463463374607431768211455\n",
+		"{CountryCode:US TestCount:4000000000 TestDelta:-3 TestRatio:0.25 TestBig:+340282366920938463463374607431768211455 Latitude:1.5}",
+		`"test_count":4000000000,"test_delta":-3,"test_ratio":0.25,"test_big":340282366920938463463374607431768211455`,
+	} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("expected output to contain %q, got:\n%s", exp, out)
+		}
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, "schema", "TT1.schema.json"))
+	if err != nil {
+		t.Fatalf("read schema: %v", err)
+	}
+	for _, exp := range []string{`"test_count": {`, `"type": "integer"`, `"type": "number"`} {
+		if !strings.Contains(string(b), exp) {
+			t.Errorf("expected schema to contain %q, got:\n%s", exp, b)
+		}
+	}
+	b, err = os.ReadFile(filepath.Join(dir, "schema", "ip2x.proto"))
+	if err != nil {
+		t.Fatalf("read proto: %v", err)
+	}
+	for _, exp := range []string{"uint32 test_count", "sint32 test_delta", "double test_ratio", "bytes test_big"} {
+		if !strings.Contains(string(b), exp) {
+			t.Errorf("expected proto to contain %q, got:\n%s", exp, b)
+		}
+	}
+
+	for _, c := range [][2]string{
+		{"u128@0 test_big", "u128   test_big"},     // must be a pointer
+		{"f64@0  test_ratio", "f64    test_ratio"}, // must be a pointer
+		{"i32    test_delta", "i64    test_delta"}, // unknown type
+	} {
+		b, err := os.ReadFile(filepath.Join(dir, "dbdata.go"))
+		if err != nil {
+			t.Fatalf("read dbdata: %v", err)
+		}
+		bad := strings.Replace(string(b), c[0], c[1], 1)
+		if bad == string(b) {
+			t.Fatalf("failed to replace %q", c[0])
+		}
+		if err := os.WriteFile(filepath.Join(dir, "bad.go"), []byte(bad), 0666); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		cmd := exec.Command(gobin, "run", "bad.go")
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOWORK=off")
+		if out, err := cmd.CombinedOutput(); err == nil || !strings.Contains(string(out), "column type") {
+			t.Errorf("%q: expected column type error, got %v:\n%s", c[1], err, out)
+		}
+	}
+}
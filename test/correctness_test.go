@@ -1,6 +1,7 @@
 package test
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"net/netip"
@@ -11,13 +12,14 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ip2location/ip2location-go/v9"
 	"github.com/pg9182/ip2x"
 )
 
 func TestCorrectness(t *testing.T) {
 	for _, a := range ips {
 		t.Run(a.String(), func(t *testing.T) {
-			if err := testCorrectness(a); err != nil {
+			if err := testCorrectness(IP2LocationV9_DB, IP2x_DB, a); err != nil {
 				t.Fatal(err.Error())
 			}
 		})
@@ -83,7 +85,7 @@ func testCorrectnessIPv4Bits(t *testing.T, bits int) {
 				binary.BigEndian.PutUint32(b[:], n)
 
 				a := netip.AddrFrom4(b)
-				if err := testCorrectness(a); err != nil {
+				if err := testCorrectness(IP2LocationV9_DB, IP2x_DB, a); err != nil {
 					t.Errorf("%s: %v", a, err)
 					atomic.AddUint64(&numErrors, 1)
 				}
@@ -97,7 +99,55 @@ func testCorrectnessIPv4Bits(t *testing.T, bits int) {
 	}
 }
 
-func testCorrectness(a netip.Addr) error {
+// TestCorrectnessSynthetic checks lookups in every synthetic database against
+// the values it was generated from, and against the official library for
+// IP2Location databases.
+func TestCorrectnessSynthetic(t *testing.T) {
+	eachSynthDB(func(s *synthDB) bool {
+		t.Run(s.Name(), func(t *testing.T) {
+			db, err := ip2x.New(bytes.NewReader(s.Data))
+			if err != nil {
+				t.Fatalf("open: %v", err)
+			}
+			if db.HasIPv4() != (s.Family&synthIPv4 != 0) || db.HasIPv6() != (s.Family&synthIPv6 != 0) {
+				t.Errorf("expected %s, got %s", s.Family, db)
+			}
+			var v9 *ip2location.DB
+			if s.Product == ip2x.IP2Location {
+				if v9, err = ip2location.OpenDBWithReader(nopCloserAt{bytes.NewReader(s.Data)}); err != nil {
+					t.Fatalf("open: ip2location/v9: %v", err)
+				}
+			}
+			for _, a := range s.Addrs() {
+				row, ok := s.Lookup(a)
+				r, rg, err := db.LookupRange(a)
+				if err != nil {
+					t.Fatalf("lookup %s: %v", a, err)
+				}
+				if r.IsValid() != ok {
+					t.Errorf("lookup %s: expected found=%t, got %t", a, ok, r.IsValid())
+					continue
+				}
+				if rg != row.Range {
+					t.Errorf("lookup %s: expected range %v, got %v", a, row.Range, rg)
+				}
+				for _, f := range s.Fields {
+					if v := r.Get(f); v != row.Values[f] {
+						t.Errorf("lookup %s: %s: expected %#v, got %#v", a, f, row.Values[f], v)
+					}
+				}
+				if v9 != nil && ok {
+					if err := testCorrectness(v9, db, a); err != nil {
+						t.Errorf("lookup %s: %v", a, err)
+					}
+				}
+			}
+		})
+		return !t.Failed()
+	})
+}
+
+func testCorrectness(v9 *ip2location.DB, db *ip2x.DB, a netip.Addr) error {
 	fmap := map[ip2x.DBField]string{ // map[ip2x.DBField]ip2location.Record.*
 		ip2x.CountryCode:        "Country_short",
 		ip2x.CountryName:        "Country_long",
@@ -123,7 +173,7 @@ func testCorrectness(a netip.Addr) error {
 		ip2x.Category:           "Category",
 	}
 
-	r1, err := IP2LocationV9_DB.Get_all(a.String())
+	r1, err := v9.Get_all(a.String())
 	if err != nil {
 		return fmt.Errorf("ip2location/v9 lookup error: %w", err)
 	}
@@ -131,7 +181,7 @@ func testCorrectness(a netip.Addr) error {
 		return fmt.Errorf("ip2location/v9 thinks a valid address is invalid...")
 	}
 
-	r2, err := IP2x_DB.Lookup(a)
+	r2, err := db.Lookup(a)
 	if err != nil {
 		return fmt.Errorf("ip2x lookup error: %w", err)
 	}
@@ -139,7 +189,7 @@ func testCorrectness(a netip.Addr) error {
 	for x, y := range fmap {
 		switch v := reflect.ValueOf(r1).FieldByName(y).Interface().(type) {
 		case string:
-			if exp, act := v != ip2locationv9_not_supported, IP2x_DB.Has(x); exp != act {
+			if exp, act := v != ip2locationv9_not_supported, db.Has(x); exp != act {
 				return fmt.Errorf("ip2location/v9 thinks field %s (ip2x.%s) should exist=%t (value %q), but ip2x thinks %t", y, x, exp, v, act)
 			}
 			if act, _ := r2.GetString(x); v != act && !(v == ip2locationv9_not_supported && act == "") {
@@ -157,3 +207,65 @@ func testCorrectness(a netip.Addr) error {
 	}
 	return nil
 }
+
+func TestCorrectnessIndex(t *testing.T) {
+	opts := map[string][]ip2x.Option{
+		"preload":  {ip2x.Preload()},
+		"bits=16":  {ip2x.IndexBits(16, 16)},
+		"bits=20":  {ip2x.IndexBits(20, 0), ip2x.Preload()},
+		"bits=24":  {ip2x.IndexBits(24, 24)},
+		"bits=v6":  {ip2x.IndexBits(0, 20)},
+		"bits=mix": {ip2x.IndexBits(22, 17)},
+		"eytz":     {ip2x.EytzingerSearch()},
+		"eytz+idx": {ip2x.EytzingerSearch(), ip2x.IndexBits(20, 20)},
+	}
+	eachSynthDB(func(s *synthDB) bool {
+		if s.Product != ip2x.IP2Location || (s.Type != 1 && s.Type != 11) {
+			return true
+		}
+		t.Run(s.Name(), func(t *testing.T) {
+			base, err := ip2x.New(bytes.NewReader(s.Data))
+			if err != nil {
+				t.Fatalf("open: %v", err)
+			}
+			for name, opt := range opts {
+				db, err := ip2x.New(bytes.NewReader(s.Data), opt...)
+				if err != nil {
+					t.Fatalf("open: %s: %v", name, err)
+				}
+				for _, a := range s.Addrs() {
+					r1, rg1, err1 := base.LookupRange(a)
+					r2, rg2, err2 := db.LookupRange(a)
+					if err1 != nil || err2 != nil {
+						t.Fatalf("%s: lookup %s: %v, %v", name, a, err1, err2)
+					}
+					if r1.IsValid() != r2.IsValid() || rg1 != rg2 || r1.String() != r2.String() {
+						t.Errorf("%s: lookup %s: expected %v %s, got %v %s", name, a, rg1, r1, rg2, r2)
+					}
+				}
+			}
+		})
+		return !t.Failed()
+	})
+	for _, bits := range [][2]int{{8, 0}, {0, 25}, {-1, 16}} {
+		if _, err := ip2x.New(bytes.NewReader(mkSynthDB(ip2x.IP2Location, 1, synthDual, true).Data), ip2x.IndexBits(bits[0], bits[1])); err == nil {
+			t.Errorf("expected error for index bits %v", bits)
+		}
+	}
+
+	// indexes aren't built if they wouldn't be used
+	var reads [2]int64
+	for i, opt := range [][]ip2x.Option{
+		{ip2x.EytzingerSearch()},
+		{ip2x.EytzingerSearch(), ip2x.IndexBits(24, 24), ip2x.Preload()},
+	} {
+		cr := &countingReaderAt{r: bytes.NewReader(mkSynthDB(ip2x.IP2Location, 1, synthDual, true).Data)}
+		if _, err := ip2x.New(cr, opt...); err != nil {
+			t.Fatalf("open: %v", err)
+		}
+		reads[i] = cr.n
+	}
+	if reads[0] != reads[1] {
+		t.Errorf("expected the same number of reads with and without index options, got %v", reads)
+	}
+}
@@ -0,0 +1,394 @@
+package test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/pg9182/ip2x"
+	"github.com/pg9182/ip2x/ip2xtest"
+)
+
+func TestAppendJSON(t *testing.T) {
+	city := "a\"b\\c\nd\re\tf\x01g\x1fh\x7fi j k<>&lé"
+	db := ip2xtest.New(ip2x.IP2Location, 5).
+		Add("1.2.3.4", map[ip2x.DBField]any{
+			ip2x.CountryCode: "US",
+			ip2x.City:        city,
+			ip2x.Latitude:    1.5,
+		}).
+		Add("1.2.3.5", map[ip2x.DBField]any{
+			ip2x.City: "a\xffb\xc3",
+		}).
+		Build()
+
+	r, err := db.LookupString("1.2.3.4")
+	if err != nil || !r.IsValid() {
+		t.Fatalf("lookup: %v", err)
+	}
+
+	var exp bytes.Buffer
+	enc := json.NewEncoder(&exp)
+	enc.SetEscapeHTML(false)
+	enc.Encode(city)
+	for _, c := range []struct {
+		fields []ip2x.DBField
+		want   string
+	}{
+		{nil, `{"city":` + strings.TrimSpace(exp.String()) + `,"country_code":"US","country_name":"","latitude":1.5,"longitude":0,"region":""}`},
+		{[]ip2x.DBField{ip2x.Latitude, ip2x.CountryCode}, `{"latitude":1.5,"country_code":"US"}`},
+		{[]ip2x.DBField{ip2x.CountryCode, ip2x.ISP, ip2x.Latitude}, `{"country_code":"US","latitude":1.5}`},
+		{[]ip2x.DBField{ip2x.ISP}, `{}`},
+	} {
+		b, err := r.AppendJSON([]byte("x"), c.fields...)
+		if err != nil {
+			t.Errorf("append json %v: %v", c.fields, err)
+		} else if string(b) != "x"+c.want {
+			t.Errorf("append json %v: expected %s, got %s", c.fields, c.want, b[1:])
+		} else if !json.Valid(b[1:]) {
+			t.Errorf("append json %v: invalid json %s", c.fields, b[1:])
+		}
+	}
+
+	r, err = db.LookupString("1.2.3.5")
+	if err != nil || !r.IsValid() {
+		t.Fatalf("lookup: %v", err)
+	}
+	if b, err := r.AppendJSON(nil, ip2x.City); err != nil || string(b) != `{"city":"a\ufffdb\ufffd"}` || !json.Valid(b) {
+		t.Errorf("append json: expected invalid utf-8 to be replaced, got %s (err %v)", b, err)
+	}
+	if b, err := (ip2x.Record{}).AppendJSON([]byte("x"), ip2x.City); err != nil || string(b) != "xnull" {
+		t.Errorf("append json: expected null for an invalid record, got %s (err %v)", b, err)
+	}
+}
+
+func TestAppendFormat(t *testing.T) {
+	db := ip2xtest.New(ip2x.IP2Location, 5).
+		Add("1.2.3.4", map[ip2x.DBField]any{
+			ip2x.CountryCode: "US",
+			ip2x.City:        "A b",
+			ip2x.Latitude:    1.5,
+		}).
+		Build()
+
+	r, err := db.LookupString("1.2.3.4")
+	if err != nil || !r.IsValid() {
+		t.Fatalf("lookup: %v", err)
+	}
+	for _, c := range []struct {
+		multiline bool
+		fields    []ip2x.DBField
+		want      string
+	}{
+		{false, nil, `IP2Location<DB5>{city="A b" country_code="US" country_name="" latitude=1.5 longitude=0 region=""}`},
+		{false, []ip2x.DBField{ip2x.City, ip2x.CountryCode}, `IP2Location<DB5>{city="A b" country_code="US"}`},
+		{false, []ip2x.DBField{ip2x.CountryCode, ip2x.City}, `IP2Location<DB5>{country_code="US" city="A b"}`},
+		{false, []ip2x.DBField{ip2x.ISP}, `IP2Location<DB5>{}`},
+		{true, []ip2x.DBField{ip2x.Latitude, ip2x.ISP, ip2x.City}, "IP2Location<DB5>{\n  latitude 1.5\n  city \"A b\"\n}"},
+	} {
+		if s := string(r.AppendFormat([]byte("x"), false, c.multiline, c.fields...)); s != "x"+c.want {
+			t.Errorf("append format %v (multiline=%t): expected %q, got %q", c.fields, c.multiline, c.want, s[1:])
+		}
+	}
+	if s := string(r.AppendFormat(nil, false, false)); s != r.Format(false, false) {
+		t.Errorf("append format: expected %q to match format %q", s, r.Format(false, false))
+	}
+}
+
+func TestFormatter(t *testing.T) {
+	eachSynthDB(func(s *synthDB) bool {
+		if s.Index || s.Family != synthDual {
+			return true
+		}
+		db, err := ip2x.New(bytes.NewReader(s.Data))
+		if err != nil {
+			t.Fatalf("%s: open: %v", s.Name(), err)
+		}
+		for i, row := range s.Rows {
+			if i > 20 {
+				break
+			}
+			r, err := db.Lookup(row.Range.From)
+			if err != nil {
+				t.Fatalf("%s: lookup %s: %v", s.Name(), row.Range.From, err)
+			}
+			if a, b := legacyFormat(db, r, false, false), r.String(); a != b {
+				t.Errorf("%s: string: expected %q, got %q", s.Name(), a, b)
+			}
+			for _, color := range []bool{false, true} {
+				for _, multiline := range []bool{false, true} {
+					f := ip2x.Formatter{Multiline: multiline}
+					if color {
+						f.Color = ip2x.DefaultColorScheme()
+					}
+					a, b, c := legacyFormat(db, r, color, multiline), r.Format(color, multiline), f.Format(r)
+					if a != b || a != c {
+						t.Errorf("%s: format (color=%t multiline=%t): expected %q, got %q (record) and %q (formatter)", s.Name(), color, multiline, a, b, c)
+					}
+				}
+			}
+		}
+		return !t.Failed()
+	})
+
+	b, err := ip2xtest.New(ip2x.IP2Location, 5).
+		Add("1.2.3.4", map[ip2x.DBField]any{
+			ip2x.CountryCode: "US",
+			ip2x.City:        "A b",
+			ip2x.Latitude:    1.25,
+		}).
+		Bytes()
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	fr := &failingReaderAt{r: bytes.NewReader(b)}
+	db, err := ip2x.New(fr)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	r, err := db.LookupString("1.2.3.4")
+	if err != nil || !r.IsValid() {
+		t.Fatalf("lookup: %v", err)
+	}
+	scheme := &ip2x.ColorScheme{
+		Product: "<p>",
+		Type:    "<t>",
+		Field:   "<f>",
+		String:  "<s>",
+		Number:  "<n>",
+		Error:   "<e>",
+		Reset:   "</>",
+	}
+	for _, c := range []struct {
+		f    ip2x.Formatter
+		want string
+	}{
+		{ip2x.Formatter{Color: scheme, Fields: []ip2x.DBField{ip2x.City, ip2x.Latitude}}, `<p>IP2Location</><<t>DB5</>></>{<f>city</>=<s>"A b"</> <f>latitude</>=<n>1.25</>}</>`},
+		{ip2x.Formatter{Precision: 3, Fields: []ip2x.DBField{ip2x.Latitude}}, `IP2Location<DB5>{latitude=1.250}`},
+		{ip2x.Formatter{Precision: -1, Fields: []ip2x.DBField{ip2x.Latitude}}, `IP2Location<DB5>{latitude=1.25}`},
+		{ip2x.Formatter{Round: true, Precision: 3, Fields: []ip2x.DBField{ip2x.Latitude}}, `IP2Location<DB5>{latitude=1}`},
+		{ip2x.Formatter{NoHeader: true, Filter: func(f ip2x.DBField) bool { return f == ip2x.CountryCode }}, `{country_code="US"}`},
+		{ip2x.Formatter{NoHeader: true, Multiline: true, Indent: "\t", Fields: []ip2x.DBField{ip2x.CountryCode, ip2x.City}}, "{\n\tcountry_code \"US\"\n\tcity \"A b\"\n}"},
+	} {
+		if s := c.f.Format(r); s != c.want {
+			t.Errorf("format %+v: expected %q, got %q", c.f, c.want, s)
+		}
+	}
+
+	// unknown products use the number like DB.String
+	raw := append([]byte(nil), b...)
+	raw[29] = 99
+	rdb, err := ip2x.New(bytes.NewReader(raw), ip2x.Raw())
+	if err != nil {
+		t.Fatalf("open raw: %v", err)
+	}
+	if rr, err := rdb.LookupString("1.2.3.4"); err != nil || !rr.IsValid() {
+		t.Errorf("lookup raw: %v", err)
+	} else if s, p := (&ip2x.Formatter{}).Format(rr), "DBProduct(99)<"; !strings.HasPrefix(s, p) || !strings.HasPrefix(rdb.String(), "DBProduct(99) ") {
+		t.Errorf("expected raw record %q to start with %q like %q", s, p, rdb)
+	}
+
+	dcs := ip2x.DefaultColorScheme()
+	dcs.Field = "x"
+	if s := r.Format(true, false); strings.Contains(s, "xcity") {
+		t.Errorf("expected default color scheme to be a copy")
+	}
+
+	fr.fail = true
+	if s, want := (&ip2x.Formatter{Color: scheme, NoHeader: true, Fields: []ip2x.DBField{ip2x.City}}).Format(r), `{<f>city</>=<e><error: test></>}</>`; s != want {
+		t.Errorf("format: expected %q, got %q", want, s)
+	}
+}
+
+// legacyFormat is Record.Format from before Formatter was added.
+func legacyFormat(db *ip2x.DB, r ip2x.Record, color, multiline bool) string {
+	if !r.IsValid() {
+		return ""
+	}
+	p, typ := db.Info()
+	s := make([]byte, 0, 512)
+	if color {
+		s = append(s, "\x1b[34m"...)
+	}
+	s = append(s, p.String()...)
+	if color {
+		s = append(s, "\x1b[0m"...)
+	}
+	s = append(s, '<')
+	if p == ip2x.IP2Proxy {
+		s = append(s, "PX"...)
+	} else {
+		s = append(s, "DB"...)
+	}
+	s = strconv.AppendInt(s, int64(typ), 10)
+	s = append(s, '>')
+	if color {
+		s = append(s, "\x1b[0m"...)
+	}
+	if multiline {
+		s = append(s, "{\n  "...)
+	} else {
+		s = append(s, '{')
+	}
+	n := 0
+	db.EachField(func(f ip2x.DBField) bool {
+		if n++; n > 1 {
+			if multiline {
+				s = append(s, "\n  "...)
+			} else {
+				s = append(s, ' ')
+			}
+		}
+		if color {
+			s = append(s, "\x1b[35m"...)
+		}
+		s = append(s, f.String()...)
+		if color {
+			s = append(s, "\x1b[0m"...)
+		}
+		if multiline {
+			s = append(s, " "...)
+		} else {
+			s = append(s, '=')
+		}
+		switch v := r.Get(f).(type) {
+		case string:
+			if color {
+				s = append(s, "\x1b[33m"...)
+			}
+			s = strconv.AppendQuote(s, v)
+		case float32:
+			if color {
+				s = append(s, "\x1b[32m"...)
+			}
+			s = strconv.AppendFloat(s, float64(v), 'f', -1, 32)
+		}
+		if color {
+			s = append(s, "\x1b[0m"...)
+		}
+		return true
+	})
+	if multiline {
+		s = append(s, "\n}"...)
+	} else {
+		s = append(s, '}')
+	}
+	if color {
+		s = append(s, "\x1b[0m"...)
+	}
+	return string(s)
+}
+
+// failingReaderAt returns an error for reads once fail is set.
+type failingReaderAt struct {
+	r    io.ReaderAt
+	fail bool
+}
+
+func (f *failingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if f.fail {
+		return 0, errors.New("test")
+	}
+	return f.r.ReadAt(p, off)
+}
+
+func TestAppendDelimited(t *testing.T) {
+	db := ip2xtest.New(ip2x.IP2Location, 5).
+		Add("1.2.3.4", map[ip2x.DBField]any{
+			ip2x.City:        " lead",
+			ip2x.CountryCode: "US",
+			ip2x.CountryName: `a,b "hi"`,
+			ip2x.Latitude:    -1.5,
+			ip2x.Region:      "x\ny\r\nz",
+		}).
+		Add("1.2.3.5", map[ip2x.DBField]any{
+			ip2x.CountryCode: "a\t",
+			ip2x.CountryName: `c\d`,
+			ip2x.Region:      "k=v",
+		}).
+		Build()
+
+	for _, c := range []struct {
+		ip     string
+		fields []ip2x.DBField
+		csv    string
+		tsv    string
+		logfmt string
+	}{
+		{
+			ip:     "1.2.3.4",
+			csv:    `" lead",US,"a,b ""hi""",-1.5,0,"x` + "\ny\r\nz" + `"`,
+			tsv:    " lead\tUS\ta,b \"hi\"\t-1.5\t0\tx\\ny\\r\\nz",
+			logfmt: `city=" lead" country_code=US country_name="a,b \"hi\"" latitude=-1.5 longitude=0 region="x\ny\r\nz"`,
+		},
+		{
+			ip:     "1.2.3.5",
+			csv:    ",a\t,c\\d,0,0,k=v",
+			tsv:    "\ta\\t\tc\\\\d\t0\t0\tk=v",
+			logfmt: `city="" country_code="a\t" country_name=c\d latitude=0 longitude=0 region="k=v"`,
+		},
+		{
+			ip:     "1.2.3.5",
+			fields: []ip2x.DBField{ip2x.Region, ip2x.ISP, ip2x.CountryCode},
+			csv:    "k=v,,a\t",
+			tsv:    "k=v\t\ta\\t",
+			logfmt: `region="k=v" country_code="a\t"`,
+		},
+	} {
+		r, err := db.LookupString(c.ip)
+		if err != nil || !r.IsValid() {
+			t.Fatalf("lookup %s: %v", c.ip, err)
+		}
+		if b, err := r.AppendCSV(nil, c.fields...); err != nil || string(b) != c.csv {
+			t.Errorf("csv %s %v: expected %q, got %q (err %v)", c.ip, c.fields, c.csv, b, err)
+		} else if rec, err := csv.NewReader(bytes.NewReader(b)).Read(); err != nil {
+			t.Errorf("csv %s %v: invalid csv: %v", c.ip, c.fields, err)
+		} else {
+			fs := c.fields
+			if len(fs) == 0 {
+				db.EachField(func(f ip2x.DBField) bool {
+					fs = append(fs, f)
+					return true
+				})
+			}
+			if len(rec) != len(fs) {
+				t.Fatalf("csv %s %v: expected %d columns, got %d", c.ip, c.fields, len(fs), len(rec))
+			}
+			for i, f := range fs {
+				if f.Kind() != ip2x.KindString {
+					continue
+				}
+				want, _ := r.GetString(f)
+				if want = strings.ReplaceAll(want, "\r\n", "\n"); rec[i] != want {
+					t.Errorf("csv %s %v: column %d: expected %q, got %q", c.ip, c.fields, i, want, rec[i])
+				}
+			}
+		}
+		if b, err := r.AppendTSV(nil, c.fields...); err != nil || string(b) != c.tsv {
+			t.Errorf("tsv %s %v: expected %q, got %q (err %v)", c.ip, c.fields, c.tsv, b, err)
+		}
+		if b, err := r.AppendLogfmt(nil, c.fields...); err != nil || string(b) != c.logfmt {
+			t.Errorf("logfmt %s %v: expected %q, got %q (err %v)", c.ip, c.fields, c.logfmt, b, err)
+		}
+	}
+
+	for _, c := range []struct {
+		fields []ip2x.DBField
+		csv    string
+	}{
+		{nil, "city,country_code,country_name,latitude,longitude,region"},
+		{[]ip2x.DBField{ip2x.Region, ip2x.ISP}, "region,isp"},
+	} {
+		if b := db.AppendCSVHeader(nil, c.fields...); string(b) != c.csv {
+			t.Errorf("csv header %v: expected %q, got %q", c.fields, c.csv, b)
+		}
+		if b, want := db.AppendTSVHeader(nil, c.fields...), strings.ReplaceAll(c.csv, ",", "\t"); string(b) != want {
+			t.Errorf("tsv header %v: expected %q, got %q", c.fields, want, b)
+		}
+	}
+}
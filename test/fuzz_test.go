@@ -1,17 +1,27 @@
 package test
 
 import (
+	"bytes"
 	"net/netip"
 	"testing"
+
+	"github.com/pg9182/ip2x"
 )
 
 func FuzzLookup(f *testing.F) {
+	dbs := []*ip2x.DB{IP2x_DB}
+	for _, family := range []synthFamily{synthIPv4, synthIPv6} {
+		db, err := ip2x.New(bytes.NewReader(mkSynthDB(ip2x.IP2Location, 11, family, false).Data))
+		if err != nil {
+			f.Fatalf("failed to open synthetic db: %v", err)
+		}
+		dbs = append(dbs, db)
+	}
 	for _, ip := range ips {
 		hi, lo := addrUint128(ip)
 		f.Add(hi, lo)
 	}
 	f.Fuzz(func(t *testing.T, hi, lo uint64) {
-		var last string
 		as := []netip.Addr{
 			uint128Addr(hi, lo), // v6 (or v4 if hi is 0)
 			uint128Addr(0, lo&0xffffffff|0xffff00000000).Unmap(), // v4
@@ -19,16 +29,19 @@ func FuzzLookup(f *testing.F) {
 			uint128Addr(0x2002<<48|(lo&0xffffffff)<<16, 0),       //  ^ 6to4
 			uint128Addr(0x20010000<<32, ^(lo & 0xffffffff)),      //  ^ teredo
 		}
-		for i, a := range as {
-			r, err := IP2x_DB.Lookup(a)
-			if err != nil {
-				t.Errorf("lookup %s: %v", a, err)
-				// not fatal since r should still work on error
-			}
-			if res := r.Format(false, false); i >= 2 && last != res {
-				t.Errorf("lookup %s: expected all v4 mappings to match native v4 (%s): expected %q, got %q", a, as[1], last, res)
-			} else {
-				last = res
+		for _, db := range dbs {
+			var last string
+			for i, a := range as {
+				r, err := db.Lookup(a)
+				if err != nil {
+					t.Errorf("%s: lookup %s: %v", db, a, err)
+					// not fatal since r should still work on error
+				}
+				if res := r.Format(false, false); i >= 2 && last != res {
+					t.Errorf("%s: lookup %s: expected all v4 mappings to match native v4 (%s): expected %q, got %q", db, a, as[1], last, res)
+				} else {
+					last = res
+				}
 			}
 		}
 	})
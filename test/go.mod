@@ -5,9 +5,13 @@ go 1.18
 require (
 	github.com/ip2location/ip2location-go/v9 v9.8.0
 	github.com/ip2location/ip2proxy-go/v4 v4.1.0
+	github.com/oschwald/maxminddb-golang v1.12.0
 	github.com/pg9182/ip2x v0.0.0
 )
 
-require lukechampine.com/uint128 v1.2.0 // indirect
+require (
+	golang.org/x/sys v0.10.0 // indirect
+	lukechampine.com/uint128 v1.2.0 // indirect
+)
 
 replace github.com/pg9182/ip2x => ../
@@ -0,0 +1,122 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pg9182/ip2x"
+)
+
+// goldenSynth returns the synthetic databases with golden test vectors in
+// testdata, named SYNTH-NAME.BIN. Since they are built from the same layouts
+// as the reader, they only catch reader bugs, not layout mistakes, which are
+// caught by the vectors CI generates from real databases. To update them, run
+// TestGolden with IP2X_GOLDEN_SYNTH set to an empty directory, then run codegen
+// with -samples set to that directory.
+func goldenSynth() map[string]*synthDB {
+	m := map[string]*synthDB{}
+	for _, s := range []*synthDB{
+		mkSynthDB(ip2x.IP2Location, 26, synthDual, true),
+		mkSynthDB(ip2x.IP2Location, 1, synthIPv4, false),
+		mkSynthDB(ip2x.IP2Proxy, 12, synthDual, true),
+	} {
+		m["SYNTH-"+s.Name()+".BIN"] = s
+	}
+	return m
+}
+
+// TestGolden checks lookups against the test vectors generated by codegen for
+// sample databases (see codegen.Main). The sample databases must be in this
+// directory, and ones which are missing or from a different release are
+// skipped. Synthetic ones are generated by goldenSynth.
+func TestGolden(t *testing.T) {
+	synth := goldenSynth()
+	if dir := os.Getenv("IP2X_GOLDEN_SYNTH"); dir != "" {
+		for name, s := range synth {
+			if err := os.WriteFile(filepath.Join(dir, name), s.Data, 0666); err != nil {
+				t.Fatalf("write synthetic database: %v", err)
+			}
+		}
+	} else {
+		for name := range synth {
+			if _, err := os.Stat(filepath.Join("testdata", strings.TrimSuffix(name, ".BIN")+".golden.json")); err != nil {
+				t.Errorf("missing golden vectors for %s: %v", name, err)
+			}
+		}
+	}
+
+	names, err := filepath.Glob(filepath.Join("testdata", "*.golden.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range names {
+		name := name
+		t.Run(filepath.Base(name), func(t *testing.T) {
+			var golden struct {
+				File    string
+				Product uint8
+				Type    uint8
+				Date    string
+				Vectors []struct {
+					IP     string
+					Fields map[string]string
+				}
+			}
+			if buf, err := os.ReadFile(name); err != nil {
+				t.Fatalf("read golden vectors: %v", err)
+			} else if err := json.Unmarshal(buf, &golden); err != nil {
+				t.Fatalf("parse golden vectors: %v", err)
+			}
+
+			var r io.ReaderAt
+			if s, ok := synth[golden.File]; ok {
+				r = bytes.NewReader(s.Data)
+			} else {
+				f, err := os.Open(golden.File)
+				if err != nil {
+					t.Skipf("open sample database: %v", err)
+				}
+				defer f.Close()
+				r = f
+			}
+
+			db, err := ip2x.New(r)
+			if err != nil {
+				t.Fatalf("open sample database: %v", err)
+			}
+			if v := db.Version(); v != golden.Date {
+				t.Skipf("sample database is from %s, but golden vectors are for %s", v, golden.Date)
+			}
+			if p, typ := db.Info(); p != ip2x.DBProduct(golden.Product) || typ != ip2x.DBType(golden.Type) {
+				t.Fatalf("expected %s type %d, got %s type %d", ip2x.DBProduct(golden.Product), golden.Type, p, typ)
+			}
+			if len(golden.Vectors) == 0 {
+				t.Errorf("no golden vectors")
+			}
+			for _, vec := range golden.Vectors {
+				r, err := db.LookupString(vec.IP)
+				if err != nil {
+					t.Fatalf("lookup %s: %v", vec.IP, err)
+				}
+				if !r.IsValid() {
+					t.Errorf("lookup %s: not found", vec.IP)
+					continue
+				}
+				for column, exp := range vec.Fields {
+					f, err := ip2x.ParseDBField(column)
+					if err != nil {
+						t.Fatalf("parse field: %v", err)
+					}
+					if v, ok := r.GetString(f); !ok || v != exp {
+						t.Errorf("lookup %s: %s: expected %q, got %q (ok=%t)", vec.IP, f, exp, v, ok)
+					}
+				}
+			}
+		})
+	}
+}
@@ -42,7 +42,12 @@ func init() {
 	// search, and one for each pointer field being read), so this won't skew the
 	// results
 
-	if buf, err := os.ReadFile("IP2LOCATION-LITE-DB11.IPV6.BIN"); err != nil {
+	// note: by default, we use a synthetic DB11 database, but a real one (which
+	// must have the same fields) can be specified with IP2X_TEST_BIN
+
+	if name := os.Getenv("IP2X_TEST_BIN"); name == "" {
+		DB = nopCloserAt{bytes.NewReader(mkSynthDB(ip2x.IP2Location, 11, synthDual, true).Data)}
+	} else if buf, err := os.ReadFile(name); err != nil {
 		panic(err)
 	} else {
 		DB = nopCloserAt{bytes.NewReader(buf)}
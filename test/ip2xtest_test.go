@@ -0,0 +1,67 @@
+package test
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/pg9182/ip2x"
+	"github.com/pg9182/ip2x/ip2xtest"
+)
+
+func TestIP2xTest(t *testing.T) {
+	db := ip2xtest.New(ip2x.IP2Location, 11).
+		Date(time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)).
+		Add("1.2.3.0/24", map[ip2x.DBField]any{
+			ip2x.CountryCode: "US",
+			ip2x.City:        "Test",
+			ip2x.Latitude:    12.5,
+		}).
+		Add("10.0.0.1-10.0.0.3", map[ip2x.DBField]any{
+			ip2x.CountryCode: []byte("CA"),
+			ip2x.Longitude:   -3,
+		}).
+		Add("2001:db8::/32", map[ip2x.DBField]any{
+			ip2x.CountryCode: "DE",
+		}).
+		Build()
+
+	if v := db.Header(); v.Year != 25 || v.Month != 1 || v.Day != 2 {
+		t.Errorf("incorrect date %d-%d-%d", v.Year, v.Month, v.Day)
+	}
+	ip2xtest.AssertRecord(t, db, "1.2.3.0", map[ip2x.DBField]any{ip2x.CountryCode: "US", ip2x.City: "Test", ip2x.Latitude: 12.5, ip2x.Region: nil})
+	ip2xtest.AssertRecord(t, db, "1.2.3.255", map[ip2x.DBField]any{ip2x.CountryCode: "US"})
+	ip2xtest.AssertRecord(t, db, "::ffff:1.2.3.4", map[ip2x.DBField]any{ip2x.CountryCode: "US"})
+	ip2xtest.AssertRecord(t, db, "1.2.4.0", map[ip2x.DBField]any{ip2x.CountryCode: nil, ip2x.Latitude: 0})
+	ip2xtest.AssertRecord(t, db, "10.0.0.3", map[ip2x.DBField]any{ip2x.CountryCode: "CA", ip2x.Longitude: float32(-3)})
+	ip2xtest.AssertRecord(t, db, "10.0.0.4", map[ip2x.DBField]any{ip2x.CountryCode: ""})
+	ip2xtest.AssertRecord(t, db, "2001:db8:ffff::1", map[ip2x.DBField]any{ip2x.CountryCode: "DE"})
+	ip2xtest.AssertRecord(t, db, "2001:db9::", map[ip2x.DBField]any{ip2x.CountryCode: ""})
+
+	v4 := ip2xtest.New(ip2x.IP2Location, 1).Add("1.0.0.0/8", map[ip2x.DBField]any{ip2x.CountryCode: "AU"}).Build()
+	ip2xtest.AssertRecord(t, v4, "1.1.1.1", map[ip2x.DBField]any{ip2x.CountryCode: "AU"})
+	ip2xtest.AssertNotFound(t, v4, "2001:db8::")
+
+	r, _ := v4.Lookup(netip.MustParseAddr("1.1.1.1"))
+	if err := ip2xtest.Match(r, ip2x.CountryCode, "US"); err == nil {
+		t.Errorf("expected mismatch error")
+	}
+	if err := ip2xtest.Match(r, ip2x.Latitude, 0); err == nil {
+		t.Errorf("expected error for missing field")
+	}
+
+	for _, b := range []*ip2xtest.Builder{
+		ip2xtest.New(ip2x.IP2Location, 100),
+		ip2xtest.New(ip2x.IP2Location, 1).Add("1.2.3.4/24", nil),
+		ip2xtest.New(ip2x.IP2Location, 1).Add("1.2.3.9-1.2.3.4", nil),
+		ip2xtest.New(ip2x.IP2Location, 1).Add("1.2.3.4-::1", nil),
+		ip2xtest.New(ip2x.IP2Location, 1).Add("255.0.0.0/8", nil),
+		ip2xtest.New(ip2x.IP2Location, 1).Add("1.2.3.0/24", nil).Add("1.2.3.4", nil),
+		ip2xtest.New(ip2x.IP2Location, 1).Add("1.2.3.0/24", map[ip2x.DBField]any{ip2x.City: "Test"}),
+		ip2xtest.New(ip2x.IP2Location, 1).Add("1.2.3.0/24", map[ip2x.DBField]any{ip2x.CountryCode: 1}),
+	} {
+		if _, err := b.Bytes(); err == nil {
+			t.Errorf("expected error")
+		}
+	}
+}
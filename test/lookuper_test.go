@@ -0,0 +1,277 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/netip"
+	"sync/atomic"
+	"testing"
+
+	"github.com/pg9182/ip2x"
+	"github.com/pg9182/ip2x/ip2xtest"
+)
+
+type countingLookuper struct {
+	ip2x.Lookuper
+	n   int
+	err error
+}
+
+func (l *countingLookuper) Lookup(a netip.Addr) (ip2x.Record, error) {
+	l.n++
+	if l.err != nil {
+		return ip2x.Record{}, l.err
+	}
+	return l.Lookuper.Lookup(a)
+}
+
+func TestLookuper(t *testing.T) {
+	db11 := ip2xtest.New(ip2x.IP2Location, 11).
+		Add("1.2.3.0/24", map[ip2x.DBField]any{ip2x.CountryCode: "US", ip2x.City: "Test"}).
+		Build()
+	db1 := ip2xtest.New(ip2x.IP2Location, 1).
+		Add("1.2.3.0/24", map[ip2x.DBField]any{ip2x.CountryCode: "CA"}).
+		Add("2001:db8::/32", map[ip2x.DBField]any{ip2x.CountryCode: "DE"}).
+		Build()
+
+	t.Run("Cache", func(t *testing.T) {
+		l := &countingLookuper{Lookuper: db11}
+		c := ip2x.NewCache(l, 2)
+		for _, ip := range []string{"1.2.3.4", "1.2.3.4", "1.2.3.5", "1.2.3.4", "1.2.3.6", "1.2.3.4", "1.2.3.5"} {
+			ip2xtest.AssertRecord(t, c, ip, map[ip2x.DBField]any{ip2x.CountryCode: "US"})
+		}
+		if l.n != 4 {
+			t.Errorf("expected 4 lookups, got %d", l.n)
+		}
+		if n := c.Len(); n != 2 {
+			t.Errorf("expected 2 cached records, got %d", n)
+		}
+		c.Purge()
+		if n := c.Len(); n != 0 {
+			t.Errorf("expected no cached records, got %d", n)
+		}
+		l.err = errors.New("test")
+		if _, err := c.Lookup(netip.MustParseAddr("1.2.3.4")); err != l.err {
+			t.Errorf("expected error, got %v", err)
+		}
+		if n := c.Len(); n != 0 {
+			t.Errorf("expected error to not be cached")
+		}
+		if p, typ := c.Info(); p != ip2x.IP2Location || typ != 11 || !c.Has(ip2x.City) {
+			t.Errorf("incorrect info")
+		}
+	})
+
+	t.Run("Fallback", func(t *testing.T) {
+		broken := &countingLookuper{Lookuper: db11, err: errors.New("test")}
+		fb := ip2x.Fallback{broken, db11, db1}
+		ip2xtest.AssertRecord(t, fb, "1.2.3.4", map[ip2x.DBField]any{ip2x.CountryCode: "US", ip2x.City: "Test"})
+		ip2xtest.AssertRecord(t, fb, "2001:db8::1", map[ip2x.DBField]any{ip2x.CountryCode: "DE"})
+		if p, typ := fb.Info(); p != ip2x.IP2Location || typ != 11 || !fb.HasIPv6() {
+			t.Errorf("incorrect info")
+		}
+		if _, err := (ip2x.Fallback{broken, db11}).Lookup(netip.MustParseAddr("2001:db8::")); err != broken.err {
+			t.Errorf("expected error, got %v", err)
+		}
+		ip2xtest.AssertNotFound(t, ip2x.Fallback{db11}, "2001:db8::1")
+		ip2xtest.AssertNotFound(t, ip2x.Fallback{}, "1.2.3.4")
+	})
+
+	t.Run("Override", func(t *testing.T) {
+		o, err := ip2x.NewOverride(db1, map[netip.Prefix]map[ip2x.DBField]any{
+			netip.MustParsePrefix("10.0.0.0/8"):  {ip2x.CountryCode: "ZZ"},
+			netip.MustParsePrefix("1.2.3.64/26"): {ip2x.CountryCode: "XX"},
+			netip.MustParsePrefix("fd00::/8"):    {ip2x.CountryCode: "YY"},
+		})
+		if err != nil {
+			t.Fatalf("create override: %v", err)
+		}
+		for ip, cc := range map[string]string{
+			"10.1.2.3":         "ZZ",
+			"::ffff:10.1.2.3":  "ZZ",
+			"2002:a01:203::":   "ZZ",
+			"1.2.3.63":         "CA",
+			"1.2.3.64":         "XX",
+			"1.2.3.127":        "XX",
+			"1.2.3.128":        "CA",
+			"fd12::1":          "YY",
+			"2001:db8::1":      "DE",
+			"fe00::":           "",
+			"9.255.255.255":    "",
+			"11.0.0.0":         "",
+			"::ffff:1.2.3.100": "XX",
+			"2001::f5f5:fc9b":  "ZZ", // teredo 10.10.3.100
+		} {
+			ip2xtest.AssertRecord(t, o, ip, map[ip2x.DBField]any{ip2x.CountryCode: cc})
+		}
+		if _, err := ip2x.NewOverride(db1, map[netip.Prefix]map[ip2x.DBField]any{
+			netip.MustParsePrefix("10.0.0.0/8"):  nil,
+			netip.MustParsePrefix("10.1.0.0/16"): nil,
+		}); err == nil {
+			t.Errorf("expected error for overlapping prefixes")
+		}
+
+		// ipv4-mapped prefixes are unmapped like addresses
+		o, err = ip2x.NewOverride(db1, map[netip.Prefix]map[ip2x.DBField]any{
+			netip.MustParsePrefix("::ffff:1.2.3.0/120"): {ip2x.CountryCode: "WW"},
+		})
+		if err != nil {
+			t.Fatalf("create override: %v", err)
+		}
+		for ip, cc := range map[string]string{
+			"1.2.3.4":          "WW",
+			"::ffff:1.2.3.4":   "WW",
+			"2002:102:304::":   "WW",
+			"1.2.4.0":          "",
+			"::ffff:1.2.2.255": "",
+		} {
+			ip2xtest.AssertRecord(t, o, ip, map[ip2x.DBField]any{ip2x.CountryCode: cc})
+		}
+		for _, p := range []string{
+			"::ffff:10.0.0.0/104", // overlaps 10.0.0.0/8
+			"::ffff:0.0.0.0/95",   // not entirely ipv4-mapped
+			"2002:a01::/32",       // 6to4
+			"2001:0:f5f5::/48",    // teredo
+		} {
+			if _, err := ip2x.NewOverride(db1, map[netip.Prefix]map[ip2x.DBField]any{
+				netip.MustParsePrefix("10.0.0.0/8"): nil,
+				netip.MustParsePrefix(p):            nil,
+			}); err == nil {
+				t.Errorf("expected error for prefix %s", p)
+			}
+		}
+		if _, err := ip2x.NewOverride(db1, map[netip.Prefix]map[ip2x.DBField]any{
+			netip.MustParsePrefix("10.0.0.0/8"): {ip2x.City: "Test"},
+		}); err == nil {
+			t.Errorf("expected error for missing field")
+		}
+	})
+}
+
+func TestRangeCache(t *testing.T) {
+	db := ip2xtest.New(ip2x.IP2Location, 1).
+		Add("1.2.3.0/24", map[ip2x.DBField]any{ip2x.CountryCode: "US"}).
+		Add("1.2.4.0/24", map[ip2x.DBField]any{ip2x.CountryCode: "CA"}).
+		Add("2001:db8::/32", map[ip2x.DBField]any{ip2x.CountryCode: "DE"}).
+		Build()
+
+	c := ip2x.NewRangeCache(db, 3)
+	for ip, cc := range map[string]string{
+		"1.2.3.4":          "US",
+		"1.2.3.5":          "US",
+		"::ffff:1.2.3.255": "US",
+		"2002:102:300::":   "US",
+		"1.2.4.0":          "CA",
+		"2001:db8::1":      "DE",
+		"2001:db8:ffff::":  "DE",
+	} {
+		ip2xtest.AssertRecord(t, c, ip, map[ip2x.DBField]any{ip2x.CountryCode: cc})
+	}
+	if s := c.Stats(); s.Hits != 4 || s.Misses != 3 || s.Ranges != 3 || s.Evictions != 0 {
+		t.Errorf("incorrect stats %+v", s)
+	}
+
+	// 1.2.3.0/24 and 2001:db8::/32 are used again, so 1.2.4.0/24 will be evicted
+	ip2xtest.AssertRecord(t, c, "1.2.3.1", map[ip2x.DBField]any{ip2x.CountryCode: "US"})
+	ip2xtest.AssertRecord(t, c, "2001:db8::2", map[ip2x.DBField]any{ip2x.CountryCode: "DE"})
+	ip2xtest.AssertRecord(t, c, "1.2.5.0", map[ip2x.DBField]any{ip2x.CountryCode: ""})
+	ip2xtest.AssertRecord(t, c, "1.2.3.2", map[ip2x.DBField]any{ip2x.CountryCode: "US"})
+	if s := c.Stats(); s.Hits != 7 || s.Misses != 4 || s.Ranges != 3 || s.Evictions != 1 {
+		t.Errorf("incorrect stats %+v", s)
+	}
+	ip2xtest.AssertRecord(t, c, "1.2.4.1", map[ip2x.DBField]any{ip2x.CountryCode: "CA"})
+	if s := c.Stats(); s.Misses != 5 {
+		t.Errorf("expected evicted range to be looked up again, got %+v", s)
+	}
+
+	db2 := ip2xtest.New(ip2x.IP2Location, 1).
+		Add("1.2.3.0/24", map[ip2x.DBField]any{ip2x.CountryCode: "JP"}).
+		Build()
+	c.Reset(db2)
+	if c.DB() != db2 {
+		t.Errorf("expected database to be replaced")
+	}
+	if s := c.Stats(); s.Ranges != 0 {
+		t.Errorf("expected cache to be empty, got %+v", s)
+	}
+	ip2xtest.AssertRecord(t, c, "1.2.3.4", map[ip2x.DBField]any{ip2x.CountryCode: "JP"})
+	ip2xtest.AssertNotFound(t, c, "2001:db8::1")
+
+	// with one entry, it is evicted even if it was used
+	c = ip2x.NewRangeCache(db, 1)
+	for _, x := range [][2]string{
+		{"1.2.3.4", "US"},
+		{"1.2.3.5", "US"},
+		{"1.2.4.0", "CA"},
+		{"2001:db8::1", "DE"},
+		{"1.2.3.6", "US"},
+	} {
+		ip2xtest.AssertRecord(t, c, x[0], map[ip2x.DBField]any{ip2x.CountryCode: x[1]})
+		if s := c.Stats(); s.Ranges != 1 {
+			t.Errorf("expected one cached range, got %+v", s)
+		}
+	}
+	if s := c.Stats(); s.Hits != 1 || s.Misses != 4 || s.Evictions != 3 {
+		t.Errorf("incorrect stats %+v", s)
+	}
+}
+
+type countingReaderAt struct {
+	r *bytes.Reader
+	n int64
+}
+
+func (c *countingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	atomic.AddInt64(&c.n, 1)
+	return c.r.ReadAt(p, off)
+}
+
+func TestInternStrings(t *testing.T) {
+	s := mkSynthDB(ip2x.IP2Location, 11, synthDual, true)
+	for _, max := range []int{0, 1, 1 << 20} {
+		cr := &countingReaderAt{r: bytes.NewReader(s.Data)}
+		db, err := ip2x.New(cr, ip2x.InternStrings(max))
+		if err != nil {
+			t.Fatalf("open: %v", err)
+		}
+		for pass := 0; pass < 2; pass++ {
+			for _, row := range s.Rows {
+				r, err := db.Lookup(row.Range.From)
+				if err != nil {
+					t.Fatalf("lookup %s: %v", row.Range.From, err)
+				}
+				for _, f := range s.Fields {
+					if v := r.Get(f); v != row.Values[f] {
+						t.Errorf("max=%d: lookup %s: %s: expected %#v, got %#v", max, row.Range.From, f, row.Values[f], v)
+					}
+				}
+				if b, err := r.MarshalJSON(); err != nil || !json.Valid(b) {
+					t.Errorf("max=%d: lookup %s: marshal: %v", max, row.Range.From, err)
+				}
+			}
+		}
+		n, size := db.InternStats()
+		switch {
+		case max == 0 && n != 0:
+			t.Errorf("max=%d: expected no cache, got %d strings", max, n)
+		case max == 1 && n != 0:
+			t.Errorf("max=%d: expected no strings to fit, got %d strings", max, n)
+		case max > 1 && (n == 0 || size > max):
+			t.Errorf("max=%d: expected cached strings within limit, got %d strings (%d bytes)", max, n, size)
+		}
+		if max > 1 {
+			r, _ := db.Lookup(s.Rows[1].Range.From)
+			before := atomic.LoadInt64(&cr.n)
+			if a := testing.AllocsPerRun(100, func() {
+				r.GetString(ip2x.City)
+				r.GetString(ip2x.CountryName)
+			}); a != 0 {
+				t.Errorf("max=%d: expected no allocations for interned strings, got %v", max, a)
+			}
+			if n := atomic.LoadInt64(&cr.n) - before; n != 0 {
+				t.Errorf("max=%d: expected no reads for interned strings, got %d", max, n)
+			}
+		}
+	}
+}
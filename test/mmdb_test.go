@@ -0,0 +1,457 @@
+package test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"net"
+	"net/netip"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/oschwald/maxminddb-golang"
+	"github.com/pg9182/ip2x"
+	"github.com/pg9182/ip2x/ip2xtest"
+)
+
+func TestMMDBWriter(t *testing.T) {
+	eachSynthDB(func(s *synthDB) bool {
+		if s.Index || s.Product != ip2x.IP2Location {
+			return true
+		}
+		t.Run(s.Name(), func(t *testing.T) {
+			db, err := ip2x.New(bytes.NewReader(s.Data))
+			if err != nil {
+				t.Fatalf("open: %v", err)
+			}
+			var buf bytes.Buffer
+			if err := (&ip2x.MMDBWriter{}).Write(&buf, db); err != nil {
+				t.Fatalf("write: %v", err)
+			}
+			r, err := maxminddb.FromBytes(buf.Bytes())
+			if err != nil {
+				t.Fatalf("open mmdb: %v", err)
+			}
+			if err := r.Verify(); err != nil {
+				t.Errorf("verify mmdb: %v", err)
+			}
+			if m := r.Metadata; m.DatabaseType != "IP2Location-DB"+strconv.Itoa(int(s.Type)) || m.IPVersion != 6 || m.BinaryFormatMajorVersion != 2 {
+				t.Errorf("incorrect metadata %+v", m)
+			}
+			mapping := ip2x.DefaultMMDBMapping(s.Fields...)
+			for _, a := range s.Addrs() {
+				if a.Is6() && !a.Is4In6() && a.Less(netip.MustParseAddr("::1:0:0")) {
+					continue // ::/96 is IPv4 in MMDB files
+				}
+				row, ok := s.Lookup(a)
+				if a.Is6() && a.As16()[0] == 0x20 && a.As16()[1] == 0x01 && a.As16()[2] == 0 && a.As16()[3] == 0 {
+					ok = false // teredo is not found
+				}
+				if ok {
+					ok = false // rows without any strings are not found
+					for _, v := range row.Values {
+						if v, _ := v.(string); v != "" && v != "-" {
+							ok = true
+						}
+					}
+				}
+				var rec map[string]any
+				if err := r.Lookup(net.IP(a.AsSlice()), &rec); err != nil {
+					t.Fatalf("lookup %s: %v", a, err)
+				}
+				for _, f := range s.Fields {
+					want := row.Values[f]
+					switch v := want.(type) {
+					case string:
+						if v == "" || v == "-" {
+							want = nil
+						}
+					case float32:
+						want, _ = strconv.ParseFloat(strconv.FormatFloat(float64(v), 'g', -1, 32), 64)
+					}
+					if !ok {
+						want = nil
+					}
+					if v := mmdbPath(rec, mapping[f]); v != want {
+						t.Errorf("lookup %s: %s (%s): expected %#v, got %#v", a, f, mapping[f], want, v)
+					}
+				}
+				if a.Is4() {
+					m, v := mmdbRecord(t, r, buf.Bytes(), netip.AddrFrom16(a.As16())), mmdbRecord(t, r, buf.Bytes(), netip.AddrFrom16([16]byte{12: a.As4()[0], 13: a.As4()[1], 14: a.As4()[2], 15: a.As4()[3]}))
+					if m != v {
+						t.Errorf("lookup %s: expected ipv4-mapped record %d to be the same as %d", a, m, v)
+					}
+				}
+			}
+		})
+		return !t.Failed()
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		db := ip2xtest.New(ip2x.IP2Location, 5).
+			Add("1.0.0.0/8", map[ip2x.DBField]any{ip2x.CountryCode: "US", ip2x.Latitude: float32(1.5)}).
+			Add("2.0.0.0/8", map[ip2x.DBField]any{ip2x.CountryCode: "-", ip2x.Latitude: float32(0)}).
+			Build()
+		for _, m := range []map[ip2x.DBField]string{
+			nil,
+			{ip2x.Latitude: "lat", ip2x.Longitude: "lon"},
+		} {
+			var buf bytes.Buffer
+			if err := (&ip2x.MMDBWriter{Fields: m}).Write(&buf, db); err != nil {
+				t.Fatalf("write: %v", err)
+			}
+			r, err := maxminddb.FromBytes(buf.Bytes())
+			if err != nil {
+				t.Fatalf("open mmdb: %v", err)
+			}
+			for ip, found := range map[string]bool{
+				"1.2.3.4":  true,
+				"2.2.3.4":  false, // reserved
+				"10.0.0.1": false, // gap
+				"::1":      false,
+			} {
+				var rec map[string]any
+				if err := r.Lookup(net.ParseIP(ip), &rec); err != nil {
+					t.Fatalf("lookup %s: %v", ip, err)
+				}
+				if (rec != nil) != found {
+					t.Errorf("mapping %v: lookup %s: expected found=%t, got %v", m, ip, found, rec)
+				}
+			}
+		}
+	})
+
+	t.Run("Mapping", func(t *testing.T) {
+		db, err := ip2x.New(bytes.NewReader(mkSynthDB(ip2x.IP2Location, 3, synthDual, false).Data))
+		if err != nil {
+			t.Fatalf("open: %v", err)
+		}
+		var buf bytes.Buffer
+		if err := (&ip2x.MMDBWriter{
+			Fields: map[ip2x.DBField]string{
+				ip2x.CountryCode: "cc",
+				ip2x.Region:      "loc.0",
+				ip2x.City:        "loc.1",
+			},
+			DatabaseType: "Test",
+		}).Write(&buf, db); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		r, err := maxminddb.FromBytes(buf.Bytes())
+		if err != nil {
+			t.Fatalf("open mmdb: %v", err)
+		}
+		if r.Metadata.DatabaseType != "Test" {
+			t.Errorf("incorrect database type %q", r.Metadata.DatabaseType)
+		}
+		var n, padded int
+		db.Each(func(rg ip2x.Range, x ip2x.Record) bool {
+			if !rg.From.Is4() {
+				return true // checked above; we only care about the mapping here
+			}
+			var rec struct {
+				CC  string   `maxminddb:"cc"`
+				Loc []string `maxminddb:"loc"`
+			}
+			if err := r.Lookup(net.IP(rg.From.AsSlice()), &rec); err != nil {
+				t.Fatalf("lookup %s: %v", rg.From, err)
+			}
+			var loc []string // missing elements are padded
+			for i, f := range []ip2x.DBField{ip2x.Region, ip2x.City} {
+				if v, _ := x.GetString(f); v != "" && v != "-" {
+					for len(loc) < i {
+						loc = append(loc, "")
+						padded++
+					}
+					loc = append(loc, v)
+				}
+			}
+			if cc, _ := x.GetString(ip2x.CountryCode); cc == "-" && rec.CC != "" || cc != "-" && rec.CC != cc {
+				t.Errorf("lookup %s: expected cc %q, got %q", rg.From, cc, rec.CC)
+			}
+			if fmt.Sprintf("%q", rec.Loc) != fmt.Sprintf("%q", loc) {
+				t.Errorf("lookup %s: expected loc %q, got %q", rg.From, loc, rec.Loc)
+			}
+			n++
+			return true
+		})
+		if n == 0 || padded == 0 {
+			t.Errorf("expected rows with missing array elements (rows=%d, padded=%d)", n, padded)
+		}
+		for _, m := range []map[ip2x.DBField]string{
+			{ip2x.CountryCode: "a", ip2x.City: "a.b"},
+			{ip2x.CountryCode: "a.0", ip2x.City: "a.b"},
+			{ip2x.CountryCode: "0"},
+			{ip2x.CountryCode: "a.1"},
+			{ip2x.CountryCode: "a.0", ip2x.City: "a.2"},
+			{ip2x.CountryCode: "a..b"},
+			{ip2x.ASN: "asn"},
+		} {
+			if err := (&ip2x.MMDBWriter{Fields: m}).Write(&buf, db); err == nil {
+				t.Errorf("expected error for mapping %v", m)
+			}
+		}
+	})
+}
+
+func TestMMDB(t *testing.T) {
+	eachSynthDB(func(s *synthDB) bool {
+		if s.Index || s.Product != ip2x.IP2Location {
+			return true
+		}
+		t.Run(s.Name(), func(t *testing.T) {
+			db, err := ip2x.New(bytes.NewReader(s.Data))
+			if err != nil {
+				t.Fatalf("open: %v", err)
+			}
+			var buf bytes.Buffer
+			if err := (&ip2x.MMDBWriter{}).Write(&buf, db); err != nil {
+				t.Fatalf("write: %v", err)
+			}
+			m, err := ip2x.NewMMDB(bytes.NewReader(buf.Bytes()), int64(buf.Len()), nil)
+			if err != nil {
+				t.Fatalf("open mmdb: %v", err)
+			}
+			if m.DatabaseType() != "IP2Location-DB"+strconv.Itoa(int(s.Type)) || !m.HasIPv4() || !m.HasIPv6() {
+				t.Errorf("incorrect metadata %s", m)
+			}
+			if p, typ := m.Info(); p != ip2x.IP2Location || typ != 26 {
+				t.Errorf("expected DB26 records, got %s %d", p, typ)
+			}
+			for _, a := range s.Addrs() {
+				if a.Is6() && !a.Is4In6() && a.Less(netip.MustParseAddr("::1:0:0")) {
+					continue // ::/96 is IPv4 in MMDB files
+				}
+				row, ok := s.Lookup(a) // teredo is unmapped before the lookup
+				r, err := m.Lookup(a)
+				if err != nil {
+					t.Fatalf("lookup %s: %v", a, err)
+				}
+				if !ok {
+					if r.IsValid() {
+						t.Errorf("lookup %s: expected not found, got %s", a, r)
+					}
+					continue
+				}
+				for _, f := range []ip2x.DBField{ip2x.CountryCode, ip2x.CountryName, ip2x.Region, ip2x.City, ip2x.Latitude, ip2x.Longitude, ip2x.Zipcode, ip2x.Timezone, ip2x.ASN, ip2x.AS} {
+					want := row.Values[f]
+					switch v := want.(type) {
+					case string:
+						if v == "-" {
+							want = ""
+						}
+					case nil:
+						if want = ""; f == ip2x.Latitude || f == ip2x.Longitude {
+							want = float32(0)
+						}
+					}
+					if !r.IsValid() {
+						if want != "" && want != float32(0) {
+							t.Errorf("lookup %s: %s: expected %#v, got not found", a, f, want)
+						}
+					} else if v := r.Get(f); v != want {
+						t.Errorf("lookup %s: %s: expected %#v, got %#v", a, f, want, v)
+					}
+				}
+			}
+		})
+		return !t.Failed()
+	})
+
+	t.Run("GeoLite2", func(t *testing.T) {
+		b := mmdbFile(map[string]any{
+			"autonomous_system_number":       uint32(15169),
+			"autonomous_system_organization": "GOOGLE",
+			"country":                        map[string]any{"iso_code": "US"},
+			"location": map[string]any{
+				"latitude":  37.751,
+				"time_zone": "America/Chicago",
+			},
+		})
+		r, err := maxminddb.FromBytes(b)
+		if err != nil {
+			t.Fatalf("open mmdb: %v", err)
+		}
+		var rec struct {
+			ASN uint32 `maxminddb:"autonomous_system_number"`
+		}
+		if err := r.Lookup(net.ParseIP("1.2.3.4"), &rec); err != nil || rec.ASN != 15169 {
+			t.Fatalf("invalid test mmdb (asn %d, err %v)", rec.ASN, err)
+		}
+		m, err := ip2x.NewMMDB(bytes.NewReader(b), int64(len(b)), nil)
+		if err != nil {
+			t.Fatalf("open: %v", err)
+		}
+		if m.HasIPv6() || m.DatabaseType() != "Test" {
+			t.Errorf("incorrect metadata %s", m)
+		}
+		ip2xtest.AssertRecord(t, m, "1.2.3.4", map[ip2x.DBField]any{
+			ip2x.ASN:         "15169",
+			ip2x.AS:          "GOOGLE",
+			ip2x.CountryCode: "US",
+			ip2x.CountryName: "",
+			ip2x.Latitude:    float32(37.751),
+			ip2x.Longitude:   float32(0),
+			ip2x.Timezone:    "America/Chicago",
+		})
+		ip2xtest.AssertRecord(t, m, "::ffff:1.2.3.4", map[ip2x.DBField]any{ip2x.ASN: "15169"})
+		ip2xtest.AssertNotFound(t, m, "128.0.0.1")
+		ip2xtest.AssertNotFound(t, m, "2001:db8::1")
+
+		m, err = ip2x.NewMMDB(bytes.NewReader(b), int64(len(b)), map[ip2x.DBField]string{
+			ip2x.City:      "location.time_zone",
+			ip2x.Longitude: "location.latitude",
+		})
+		if err != nil {
+			t.Fatalf("open: %v", err)
+		}
+		if m.Has(ip2x.CountryCode) {
+			t.Errorf("expected unmapped field to be missing")
+		}
+		ip2xtest.AssertRecord(t, m, "1.2.3.4", map[ip2x.DBField]any{
+			ip2x.City:      "America/Chicago",
+			ip2x.Longitude: float32(37.751),
+		})
+
+		b = mmdbFile(map[string]any{
+			"city":         map[string]any{"names": map[string]any{"en": "Toronto"}},
+			"country_code": "CA",
+			"location":     map[string]any{"time_zone": "America/Toronto"},
+			"subdivisions": []any{
+				map[string]any{"iso_code": "ON"},
+				map[string]any{"iso_code": "XX", "names": map[string]any{"en": "Second"}},
+			},
+		})
+		m, err = ip2x.NewMMDB(bytes.NewReader(b), int64(len(b)), map[ip2x.DBField]string{
+			ip2x.City:        "city.names.en",
+			ip2x.CountryCode: "country.iso_code",
+			ip2x.CountryName: "location",
+			ip2x.Region:      "subdivisions.1.names.en",
+			ip2x.Timezone:    "location.time_zone",
+			ip2x.Zipcode:     "subdivisions.1.names.en",
+			ip2x.ISP:         "subdivisions.2.iso_code",
+		})
+		if err != nil {
+			t.Fatalf("open: %v", err)
+		}
+		ip2xtest.AssertRecord(t, m, "1.2.3.4", map[ip2x.DBField]any{
+			ip2x.City:        "Toronto", // column name is a map
+			ip2x.CountryCode: "CA",      // falls back to the column name
+			ip2x.CountryName: "",        // map containing another path
+			ip2x.Region:      "Second",
+			ip2x.Timezone:    "America/Toronto",
+			ip2x.Zipcode:     "Second", // same path as another field
+			ip2x.ISP:         "",       // past the end of the array
+		})
+
+		if _, err := ip2x.NewMMDB(bytes.NewReader(b), int64(len(b)), map[ip2x.DBField]string{ip2x.City: "a..b"}); err == nil {
+			t.Errorf("expected error for invalid key")
+		}
+		if _, err := ip2x.NewMMDB(bytes.NewReader(b[:len(b)-20]), int64(len(b)-20), nil); err == nil {
+			t.Errorf("expected error for truncated file")
+		}
+	})
+}
+
+// mmdbFile returns an IPv4 MMDB file with a single node mapping 0.0.0.0/1 to
+// data and leaving the rest empty.
+func mmdbFile(data map[string]any) []byte {
+	var b []byte
+	b = append(b, 0, 0, 17, 0, 0, 1) // node 0: 0.0.0.0/1 = data offset 0, 128.0.0.0/1 = empty
+	b = append(b, make([]byte, 16)...)
+	b = mmdbAppend(b, data)
+	b = append(b, "\xAB\xCD\xEFMaxMind.com"...)
+	b = mmdbAppend(b, map[string]any{
+		"binary_format_major_version": uint32(2),
+		"binary_format_minor_version": uint32(0),
+		"build_epoch":                 uint32(1700000000),
+		"database_type":               "Test",
+		"ip_version":                  uint32(4),
+		"languages":                   []any{},
+		"node_count":                  uint32(1),
+		"record_size":                 uint32(24),
+		"description":                 map[string]any{},
+	})
+	return b
+}
+
+// mmdbAppend appends the MMDB encoding of v, which must be small.
+func mmdbAppend(b []byte, v any) []byte {
+	switch v := v.(type) {
+	case string:
+		if len(v) < 29 {
+			b = append(b, 2<<5|byte(len(v)))
+		} else {
+			b = append(b, 2<<5|29, byte(len(v)-29))
+		}
+		return append(b, v...)
+	case float64:
+		b = append(b, 3<<5|8, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(b[len(b)-8:], math.Float64bits(v))
+		return b
+	case uint32:
+		b = append(b, 6<<5|4, 0, 0, 0, 0)
+		binary.BigEndian.PutUint32(b[len(b)-4:], v)
+		return b
+	case map[string]any:
+		ks := make([]string, 0, len(v))
+		for k := range v {
+			ks = append(ks, k)
+		}
+		sort.Strings(ks)
+		b = append(b, 7<<5|byte(len(v)))
+		for _, k := range ks {
+			b = mmdbAppend(mmdbAppend(b, k), v[k])
+		}
+		return b
+	case []any:
+		b = append(b, byte(len(v)), 11-7) // extended array
+		for _, x := range v {
+			b = mmdbAppend(b, x)
+		}
+		return b
+	default:
+		panic("unsupported type")
+	}
+}
+
+// mmdbPath gets the value at the dot-separated path in v.
+func mmdbPath(v any, path string) any {
+	for _, s := range strings.Split(path, ".") {
+		switch x := v.(type) {
+		case map[string]any:
+			v = x[s]
+		case []any:
+			i, _ := strconv.Atoi(s)
+			if i >= len(x) {
+				return nil
+			}
+			v = x[i]
+		default:
+			return nil
+		}
+	}
+	return v
+}
+
+// mmdbRecord walks the search tree in b for a, returning the record it ends
+// at.
+func mmdbRecord(t *testing.T, r *maxminddb.Reader, b []byte, a netip.Addr) uint {
+	var (
+		n   = r.Metadata.NodeCount
+		rs  = r.Metadata.RecordSize
+		ip  = a.As16()
+		rec uint
+	)
+	if rs != 24 {
+		t.Fatalf("unsupported record size %d", rs)
+	}
+	for i := 0; i < 128 && rec < n; i++ {
+		off := rec*6 + uint(ip[i/8]>>(7-i%8)&1)*3
+		rec = uint(b[off])<<16 | uint(b[off+1])<<8 | uint(b[off+2])
+	}
+	return rec
+}
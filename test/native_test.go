@@ -0,0 +1,102 @@
+package test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/pg9182/ip2x"
+)
+
+func TestNative(t *testing.T) {
+	eachSynthDB(func(s *synthDB) bool {
+		if s.Index {
+			return true // the native format doesn't have index tables
+		}
+		t.Run(s.Name(), func(t *testing.T) {
+			bin, err := ip2x.New(bytes.NewReader(s.Data))
+			if err != nil {
+				t.Fatalf("open: %v", err)
+			}
+			var buf bytes.Buffer
+			if err := ip2x.WriteNative(&buf, bin); err != nil {
+				t.Fatalf("convert: %v", err)
+			}
+			db, err := ip2x.New(bytes.NewReader(buf.Bytes()), ip2x.InternStrings(1<<20))
+			if err != nil {
+				t.Fatalf("open native: %v", err)
+			}
+			if db.String() != bin.String() {
+				t.Errorf("expected %s, got %s", bin, db)
+			}
+			if db.HasIPv4() != bin.HasIPv4() || db.HasIPv6() != bin.HasIPv6() {
+				t.Errorf("incorrect families")
+			}
+			for _, a := range s.Addrs() {
+				row, ok := s.Lookup(a)
+				r, rg, err := db.LookupRange(a)
+				if err != nil {
+					t.Fatalf("lookup %s: %v", a, err)
+				}
+				if r.IsValid() != ok {
+					t.Errorf("lookup %s: expected found=%t, got %t", a, ok, r.IsValid())
+					continue
+				}
+				if rg != row.Range {
+					t.Errorf("lookup %s: expected range %v, got %v", a, row.Range, rg)
+				}
+				for _, f := range s.Fields {
+					if v := r.Get(f); v != row.Values[f] {
+						t.Errorf("lookup %s: %s: expected %#v, got %#v", a, f, row.Values[f], v)
+					}
+				}
+			}
+			var n int
+			db.Each(func(rg ip2x.Range, r ip2x.Record) bool {
+				if n >= len(s.Rows) {
+					t.Errorf("too many rows")
+					return false
+				}
+				if rg != s.Rows[n].Range {
+					t.Errorf("row %d: expected range %v, got %v", n, s.Rows[n].Range, rg)
+				}
+				for _, f := range s.Fields {
+					if v := r.Get(f); v != s.Rows[n].Values[f] {
+						t.Errorf("row %d: %s: expected %#v, got %#v", n, f, s.Rows[n].Values[f], v)
+					}
+				}
+				n++
+				return true
+			})
+			if n != len(s.Rows) {
+				t.Errorf("expected %d rows, got %d", len(s.Rows), n)
+			}
+		})
+		return !t.Failed()
+	})
+
+	t.Run("Corrupt", func(t *testing.T) {
+		s := mkSynthDB(ip2x.IP2Location, 11, synthDual, false)
+		bin, err := ip2x.New(bytes.NewReader(s.Data))
+		if err != nil {
+			t.Fatalf("open: %v", err)
+		}
+		var buf bytes.Buffer
+		if err := ip2x.WriteNative(&buf, bin); err != nil {
+			t.Fatalf("convert: %v", err)
+		}
+		for i := 5; i < buf.Len(); i += 7 {
+			b := append([]byte(nil), buf.Bytes()...)
+			b[i] ^= 0x40
+			if _, err := ip2x.New(bytes.NewReader(b)); err == nil {
+				t.Errorf("expected error after corrupting byte %d", i)
+			}
+		}
+		if _, err := ip2x.New(bytes.NewReader(buf.Bytes()[:buf.Len()-1])); err == nil {
+			t.Errorf("expected error for truncated database")
+		}
+		if _, err := ip2x.New(strings.NewReader("IP2X\x01S\xff\xff\xff\xff")); err == nil {
+			t.Errorf("expected error for section longer than database")
+		}
+	})
+}
@@ -0,0 +1,264 @@
+package test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net/netip"
+	"testing"
+
+	"github.com/pg9182/ip2x"
+	"github.com/pg9182/ip2x/ip2xtest"
+)
+
+func TestSubset(t *testing.T) {
+	src := ip2xtest.New(ip2x.IP2Location, 11).
+		Add("1.2.3.0/24", map[ip2x.DBField]any{ip2x.CountryCode: "US", ip2x.City: "A"}).
+		Add("1.2.4.0/24", map[ip2x.DBField]any{ip2x.CountryCode: "US", ip2x.City: "B"}).
+		Add("1.2.5.0/24", map[ip2x.DBField]any{ip2x.CountryCode: "CA", ip2x.City: "C"}).
+		Add("1.2.6.0/24", map[ip2x.DBField]any{ip2x.CountryCode: "US", ip2x.City: "D"}).
+		Add("2001:db8::/32", map[ip2x.DBField]any{ip2x.CountryCode: "DE", ip2x.City: "E"}).
+		Build()
+
+	open := func(t *testing.T, s ip2x.Subset, native bool) *ip2x.DB {
+		var buf bytes.Buffer
+		var err error
+		if native {
+			err = s.WriteNative(&buf, src)
+		} else {
+			err = s.WriteBIN(&buf, src)
+		}
+		if err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		db, err := ip2x.New(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatalf("open: %v", err)
+		}
+		return db
+	}
+	ranges := func(db *ip2x.DB) map[ip2x.Range]string {
+		m := map[ip2x.Range]string{}
+		db.Each(func(rg ip2x.Range, r ip2x.Record) bool {
+			if cc, _ := r.GetString(ip2x.CountryCode); cc != "" {
+				m[rg] = cc
+			}
+			return true
+		})
+		return m
+	}
+	rng := func(from, to string) ip2x.Range {
+		return ip2x.Range{From: netip.MustParseAddr(from), To: netip.MustParseAddr(to)}
+	}
+
+	for _, native := range []bool{false, true} {
+		db := open(t, ip2x.Subset{Fields: []ip2x.DBField{ip2x.CountryCode}}, native)
+		if p, typ := db.Info(); p != ip2x.IP2Location || typ != 1 {
+			t.Errorf("native=%t: expected DB1, got %s", native, db)
+		}
+		if h, s := db.Header(), src.Header(); h.Year != s.Year || h.Month != s.Month || h.Day != s.Day {
+			t.Errorf("native=%t: expected date to be preserved", native)
+		}
+		want := map[ip2x.Range]string{
+			rng("1.2.3.0", "1.2.5.0"):       "US",
+			rng("1.2.5.0", "1.2.6.0"):       "CA",
+			rng("1.2.6.0", "1.2.7.0"):       "US",
+			rng("2001:db8::", "2001:db9::"): "DE",
+		}
+		if got := ranges(db); len(got) != len(want) {
+			t.Errorf("native=%t: expected ranges %v, got %v", native, want, got)
+		} else {
+			for rg, cc := range want {
+				if got[rg] != cc {
+					t.Errorf("native=%t: expected ranges %v, got %v", native, want, got)
+					break
+				}
+			}
+		}
+		ip2xtest.AssertRecord(t, db, "1.2.4.5", map[ip2x.DBField]any{ip2x.CountryCode: "US"})
+	}
+
+	db := open(t, ip2x.Subset{
+		Fields:   []ip2x.DBField{ip2x.CountryCode, ip2x.City},
+		IPv4Only: true,
+		Filter: func(_ ip2x.Range, r ip2x.Record) bool {
+			cc, _ := r.GetString(ip2x.CountryCode)
+			return cc == "US"
+		},
+	}, false)
+	if p, typ := db.Info(); p != ip2x.IP2Location || typ != 3 || db.HasIPv6() {
+		t.Errorf("expected IPv4-only DB3, got %s", db)
+	}
+	ip2xtest.AssertRecord(t, db, "1.2.3.4", map[ip2x.DBField]any{ip2x.CountryCode: "US", ip2x.City: "A"})
+	ip2xtest.AssertRecord(t, db, "1.2.4.4", map[ip2x.DBField]any{ip2x.CountryCode: "US", ip2x.City: "B"})
+	ip2xtest.AssertRecord(t, db, "1.2.5.4", map[ip2x.DBField]any{ip2x.CountryCode: "", ip2x.City: ""})
+	ip2xtest.AssertRecord(t, db, "1.2.6.4", map[ip2x.DBField]any{ip2x.CountryCode: "US", ip2x.City: "D"})
+	ip2xtest.AssertNotFound(t, db, "2001:db8::1")
+
+	db = open(t, ip2x.Subset{IPv6Only: true}, true)
+	if p, typ := db.Info(); p != ip2x.IP2Location || typ != 11 || db.HasIPv4() {
+		t.Errorf("expected IPv6-only DB11, got %s", db)
+	}
+	ip2xtest.AssertRecord(t, db, "2001:db8::1", map[ip2x.DBField]any{ip2x.CountryCode: "DE", ip2x.City: "E"})
+
+	if _, err := (&ip2x.Subset{Fields: []ip2x.DBField{ip2x.ASN}}).Build(src); err == nil {
+		t.Errorf("expected error for missing field")
+	}
+	if _, err := (&ip2x.Subset{IPv4Only: true, IPv6Only: true}).Build(src); err == nil {
+		t.Errorf("expected error for no families")
+	}
+
+	t.Run("IPv4Mapped", func(t *testing.T) {
+		// the writer unmaps ranges, so patch the ipv6 table to start a row
+		// inside ::ffff:0:0/96 and end the previous one there
+		w, err := ip2x.NewBINWriter(ip2x.IP2Location, 1)
+		if err != nil {
+			t.Fatalf("create writer: %v", err)
+		}
+		w.SetIndex(false)
+		if err := w.AddPrefix(netip.MustParsePrefix("1::/16"), map[ip2x.DBField]any{ip2x.CountryCode: "US"}); err != nil {
+			t.Fatalf("add: %v", err)
+		}
+		b, err := w.Bytes()
+		if err != nil {
+			t.Fatalf("build: %v", err)
+		}
+		row := int(binary.LittleEndian.Uint32(b[17:])) - 1 + 16 + int(b[1]-1)*4
+		a := netip.MustParseAddr("::ffff:1.2.3.0").As16()
+		for i := range a {
+			b[row+i] = a[15-i]
+		}
+		src, err := ip2x.New(bytes.NewReader(b))
+		if err != nil {
+			t.Fatalf("open: %v", err)
+		}
+		if ranges(src)[rng("::ffff:1.2.3.0", "2::")] != "US" {
+			t.Fatalf("failed to patch database: %v", ranges(src))
+		}
+
+		w, err = (&ip2x.Subset{}).Build(src)
+		if err != nil {
+			t.Fatalf("build subset: %v", err)
+		}
+		b, err = w.Bytes()
+		if err != nil {
+			t.Fatalf("build subset: %v", err)
+		}
+		db, err := ip2x.New(bytes.NewReader(b))
+		if err != nil {
+			t.Fatalf("open subset: %v", err)
+		}
+		if got := ranges(db); len(got) != 1 || got[rng("::ffff:1.2.3.0", "2::")] != "US" {
+			t.Errorf("expected ipv6 rows to be kept, got %v", got)
+		}
+		if db.HasIPv4() {
+			t.Errorf("expected no ipv4 table")
+		}
+		ip2xtest.AssertRecord(t, db, "1:2::3", map[ip2x.DBField]any{ip2x.CountryCode: "US"})
+		ip2xtest.AssertNotFound(t, db, "1.2.3.4")
+	})
+}
+
+func TestCoalesce(t *testing.T) {
+	db := ip2xtest.New(ip2x.IP2Location, 3).
+		Add("1.2.3.0/24", map[ip2x.DBField]any{ip2x.CountryCode: "US", ip2x.City: "A"}).
+		Add("1.2.4.0/24", map[ip2x.DBField]any{ip2x.CountryCode: "US", ip2x.City: "B"}).
+		Add("1.2.5.0/24", map[ip2x.DBField]any{ip2x.CountryCode: "US", ip2x.City: "B"}).
+		Add("2001:db8::/32", map[ip2x.DBField]any{ip2x.CountryCode: "US", ip2x.City: "B"}).
+		Build()
+
+	collect := func(each func(func(ip2x.Range, ip2x.Record) bool), limit int) []string {
+		var s []string
+		each(func(rg ip2x.Range, r ip2x.Record) bool {
+			city, _ := r.GetString(ip2x.City)
+			s = append(s, rg.From.String()+"-"+rg.To.String()+"="+city)
+			return len(s) < limit
+		})
+		return s
+	}
+	for _, c := range []struct {
+		fields []ip2x.DBField
+		limit  int
+		want   []string
+	}{
+		{[]ip2x.DBField{ip2x.CountryCode}, 100, []string{
+			"0.0.0.0-1.2.3.0=",
+			"1.2.3.0-1.2.6.0=A",
+			"1.2.6.0-255.255.255.255=",
+			"::-2001:db8::=",
+			"2001:db8::-2001:db9::=B",
+			"2001:db9::-ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff=",
+		}},
+		{nil, 100, []string{
+			"0.0.0.0-1.2.3.0=",
+			"1.2.3.0-1.2.4.0=A",
+			"1.2.4.0-1.2.6.0=B",
+			"1.2.6.0-255.255.255.255=",
+			"::-2001:db8::=",
+			"2001:db8::-2001:db9::=B",
+			"2001:db9::-ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff=",
+		}},
+		{[]ip2x.DBField{ip2x.CountryCode}, 2, []string{
+			"0.0.0.0-1.2.3.0=",
+			"1.2.3.0-1.2.6.0=A",
+		}},
+		{[]ip2x.DBField{ip2x.CountryCode}, 3, []string{
+			"0.0.0.0-1.2.3.0=",
+			"1.2.3.0-1.2.6.0=A",
+			"1.2.6.0-255.255.255.255=",
+		}},
+	} {
+		if got := collect(db.Coalesce(c.fields...), c.limit); fmt.Sprint(got) != fmt.Sprint(c.want) {
+			t.Errorf("coalesce %v (limit %d): expected %q, got %q", c.fields, c.limit, c.want, got)
+		}
+	}
+	if got := collect(db.Coalesce(ip2x.CountryCode), 1); len(got) != 1 {
+		t.Errorf("expected iteration to stop")
+	}
+
+	t.Run("Error", func(t *testing.T) {
+		w, err := ip2x.NewBINWriter(ip2x.IP2Location, 3)
+		if err != nil {
+			t.Fatalf("create writer: %v", err)
+		}
+		for _, p := range []string{"1.2.3.0/24", "1.2.4.0/24", "2001:db8::/32"} {
+			if err := w.AddPrefix(netip.MustParsePrefix(p), map[ip2x.DBField]any{ip2x.CountryCode: "US", ip2x.City: p}); err != nil {
+				t.Fatalf("add: %v", err)
+			}
+		}
+		b, err := w.Bytes()
+		if err != nil {
+			t.Fatalf("build: %v", err)
+		}
+		fr := &failingReaderAt{r: bytes.NewReader(b)}
+		db, err := ip2x.New(fr)
+		if err != nil {
+			t.Fatalf("open: %v", err)
+		}
+		for name, each := range map[string]func(func(ip2x.Range, ip2x.Record) bool) error{
+			"EachErr": db.EachErr,
+			"CoalesceErr": func(fn func(ip2x.Range, ip2x.Record) bool) error {
+				return db.CoalesceErr(fn, ip2x.CountryCode)
+			},
+		} {
+			fr.fail = false
+			n := 0
+			if err := each(func(ip2x.Range, ip2x.Record) bool {
+				n++
+				return true
+			}); err != nil || n == 0 {
+				t.Errorf("%s: unexpected error %v after %d rows", name, err, n)
+			}
+			n = 0
+			if err := each(func(ip2x.Range, ip2x.Record) bool {
+				n++
+				fr.fail = true
+				return true
+			}); err == nil {
+				t.Errorf("%s: expected error", name)
+			} else if n != 1 {
+				t.Errorf("%s: expected iteration to stop after the error, got %d rows", name, n)
+			}
+		}
+	})
+}
@@ -0,0 +1,232 @@
+package test
+
+import (
+	"fmt"
+	"math/rand"
+	"net/netip"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pg9182/ip2x"
+)
+
+// synthFamily is the address families in a synthetic database.
+type synthFamily int
+
+const (
+	synthIPv4 synthFamily = 1 << iota
+	synthIPv6
+	synthDual = synthIPv4 | synthIPv6
+)
+
+func (f synthFamily) String() string {
+	switch f {
+	case synthIPv4:
+		return "IPV4"
+	case synthIPv6:
+		return "IPV6ONLY"
+	case synthDual:
+		return "IPV6"
+	}
+	return "INVALID"
+}
+
+// synthDB is a small deterministic database generated for testing.
+type synthDB struct {
+	Product ip2x.DBProduct
+	Type    ip2x.DBType
+	Family  synthFamily
+	Index   bool
+	Fields  []ip2x.DBField
+	Rows    []synthRow // sorted by family then address
+	Data    []byte
+}
+
+type synthRow struct {
+	Range  ip2x.Range
+	Values map[ip2x.DBField]any
+}
+
+// synthTypes contains the fields in every built-in database product and type.
+// It is initialized before any tests run, so it doesn't include registered
+// ones.
+var synthTypes = func() map[ip2x.DBProduct]map[ip2x.DBType][]ip2x.DBField {
+	m := map[ip2x.DBProduct]map[ip2x.DBType][]ip2x.DBField{}
+	for _, f := range ip2x.AllFields() {
+		for p, ts := range f.Products() {
+			if m[p] == nil {
+				m[p] = map[ip2x.DBType][]ip2x.DBField{}
+			}
+			for _, t := range ts {
+				m[p][t] = append(m[p][t], f)
+			}
+		}
+	}
+	return m
+}()
+
+// eachSynthDB generates a synthetic database for every built-in product and
+// type, for IPv4-only, IPv6-only, and dual-stack databases with and without
+// index tables.
+func eachSynthDB(fn func(*synthDB) bool) {
+	var ps []ip2x.DBProduct
+	for p := range synthTypes {
+		ps = append(ps, p)
+	}
+	sort.Slice(ps, func(i, j int) bool { return ps[i] < ps[j] })
+	for _, p := range ps {
+		var ts []ip2x.DBType
+		for t := range synthTypes[p] {
+			ts = append(ts, t)
+		}
+		sort.Slice(ts, func(i, j int) bool { return ts[i] < ts[j] })
+		for _, t := range ts {
+			for _, family := range []synthFamily{synthIPv4, synthIPv6, synthDual} {
+				for _, index := range []bool{true, false} {
+					if !fn(mkSynthDB(p, t, family, index)) {
+						return
+					}
+				}
+			}
+		}
+	}
+}
+
+// mkSynthDB generates a synthetic database. The same arguments will always
+// result in the same database.
+func mkSynthDB(p ip2x.DBProduct, t ip2x.DBType, family synthFamily, index bool) *synthDB {
+	s := &synthDB{
+		Product: p,
+		Type:    t,
+		Family:  family,
+		Index:   index,
+		Fields:  synthTypes[p][t],
+	}
+	if s.Fields == nil {
+		panic(fmt.Errorf("unknown database %s %d", p, t))
+	}
+
+	seed := int64(p)<<16 | int64(t)<<8 | int64(family)<<1
+	if index {
+		seed |= 1
+	}
+	rng := rand.New(rand.NewSource(seed))
+
+	w, err := ip2x.NewBINWriter(p, t)
+	if err != nil {
+		panic(err)
+	}
+	w.SetDate(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	w.SetIndex(index)
+
+	if family&synthIPv4 != 0 {
+		// mostly large ranges, plus some small ones inside a single index
+		// entry so the binary search is exercised
+		starts := []netip.Addr{netip.IPv4Unspecified()}
+		for i := 0; i < 48; i++ {
+			starts = append(starts, uint128Addr(0, 0xffff00000000|uint64(rng.Uint32())).Unmap())
+		}
+		for i := 0; i < 16; i++ {
+			starts = append(starts, netip.AddrFrom4([4]byte{8, 8, byte(rng.Intn(256)), byte(rng.Intn(256))}))
+		}
+		s.addRows(rng, starts, netip.AddrFrom4([4]byte{255, 255, 255, 255}))
+	}
+	if family&synthIPv6 != 0 {
+		starts := []netip.Addr{netip.IPv6Unspecified()}
+		for i := 0; i < 48; i++ {
+			starts = append(starts, uint128Addr(rng.Uint64(), rng.Uint64()))
+		}
+		for i := 0; i < 16; i++ {
+			starts = append(starts, uint128Addr(0x20010db8<<32|uint64(rng.Intn(4))<<16, rng.Uint64()))
+		}
+		s.addRows(rng, starts, uint128Addr(^uint64(0), ^uint64(0)))
+	}
+	for _, row := range s.Rows {
+		if err := w.Add(row.Range, row.Values); err != nil {
+			panic(err)
+		}
+	}
+	if s.Data, err = w.Bytes(); err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// addRows adds rows with random values covering the addresses from the first
+// start to end.
+func (s *synthDB) addRows(rng *rand.Rand, starts []netip.Addr, end netip.Addr) {
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Less(starts[j]) })
+	for i, from := range starts {
+		if i != 0 && from == starts[i-1] {
+			continue
+		}
+		to := end
+		for _, next := range starts[i+1:] {
+			if next != from {
+				to = next
+				break
+			}
+		}
+		values := map[ip2x.DBField]any{}
+		for _, f := range s.Fields {
+			values[f] = synthValue(rng, f)
+		}
+		s.Rows = append(s.Rows, synthRow{ip2x.Range{From: from, To: to}, values})
+	}
+}
+
+// synthValue generates a random value for f. There are only a few distinct
+// values for each field so strings are deduplicated.
+func synthValue(rng *rand.Rand, f ip2x.DBField) any {
+	switch f.Kind() {
+	case ip2x.KindString:
+		if f == ip2x.CountryCode {
+			return []string{"US", "CA", "DE", "JP", "AU", "BR", "-"}[rng.Intn(7)]
+		}
+		if rng.Intn(8) == 0 {
+			return "-"
+		}
+		return f.String() + " " + strconv.Itoa(rng.Intn(16))
+	case ip2x.KindFloat32:
+		return float32(rng.Intn(36001)-18000) / 100
+	}
+	panic("unhandled kind " + f.Kind().String())
+}
+
+// Name returns a unique name for the database.
+func (s *synthDB) Name() string {
+	n := fmt.Sprintf("%s-%d-%s", s.Product, s.Type, s.Family)
+	if !s.Index {
+		n += "-NOINDEX"
+	}
+	return n
+}
+
+// Addrs returns the addresses to test, including the first and last address of
+// each row, and the addresses in ips.
+func (s *synthDB) Addrs() []netip.Addr {
+	as := append([]netip.Addr(nil), ips...)
+	for _, row := range s.Rows {
+		as = append(as, row.Range.From, row.Range.To.Prev())
+	}
+	return as
+}
+
+// Lookup returns the row which a lookup for a should return.
+func (s *synthDB) Lookup(a netip.Addr) (synthRow, bool) {
+	hi, lo := addrUint128(a)
+	switch {
+	case hi>>48 == 0x2002:
+		a = uint128Addr(0, (hi>>16)&0xffffffff|0xffff00000000)
+	case hi>>32 == 0x20010000:
+		a = uint128Addr(0, (^lo)&0xffffffff|0xffff00000000)
+	}
+	a = a.Unmap()
+	for _, row := range s.Rows {
+		if row.Range.From.Is4() == a.Is4() && !a.Less(row.Range.From) && a.Less(row.Range.To) {
+			return row, true
+		}
+	}
+	return synthRow{}, false
+}
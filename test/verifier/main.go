@@ -159,9 +159,7 @@ func readDBHeader(r io.ReaderAt) (h dbHeader, err error) {
 }
 
 func dbRecordEmpty(r dbRecordAdapter) bool {
-	// HACK: ip2x doesn't include the max field as part of the public API (this
-	// won't work if fields are skipped)
-	for f := ip2x.DBField(0); f == 0 || f.String() != ""; f++ {
+	for _, f := range ip2x.AllFields() {
 		if v := r.Get(f); v != nil && v != "" && v != "-" && v != float32(0) {
 			return false
 		}
@@ -170,9 +168,7 @@ func dbRecordEmpty(r dbRecordAdapter) bool {
 }
 
 func dbRecordEquals(act, exp dbRecordAdapter) error {
-	// HACK: ip2x doesn't include the max field as part of the public API (this
-	// won't work if fields are skipped)
-	for f := ip2x.DBField(0); f == 0 || f.String() != ""; f++ {
+	for _, f := range ip2x.AllFields() {
 		if a, e := act.Get(f), exp.Get(f); a != e {
 			if a == nil && e == float32(0) {
 				return nil
@@ -0,0 +1,501 @@
+package ip2x
+
+import (
+	"errors"
+	"io"
+	"math"
+	"math/big"
+	"net/netip"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// BINWriter builds an IP2Location binary database.
+//
+// Rows are added as IPv4 or IPv6 ranges, which must not overlap. Since the
+// format cannot represent missing rows, addresses not covered by any range are
+// written as rows with empty values (i.e., empty strings and zero numbers), and
+// the last address of each family cannot be covered. The IPv4 and IPv6 tables
+// are only written if at least one range of that family is added.
+type BINWriter struct {
+	s     *dbS
+	date  time.Time
+	index bool
+	cols  [][]DBField // [column-2] sorted by pointer offset
+	empty []uint32
+	v4    []binRow
+	v6    []binRow
+	heap  []byte
+	blobs map[string]uint32 // [data]heap offset
+}
+
+type binRow struct {
+	from, to uint128
+	cols     []uint32 // pointers are relative to the start of the heap; nil for the final row
+}
+
+// NewBINWriter creates a new writer for the specified database product and
+// type, which may have been added by [Register]. The date defaults to the
+// current one, and index tables are written by default.
+func NewBINWriter(p DBProduct, t DBType) (*BINWriter, error) {
+	c, _, _ := dbinfo(p, t).Info()
+	s := lookupSchema(p, t, c)
+	if c, _, _ = s.Info(); c == 0 {
+		return nil, errors.New("unsupported database " + p.String() + " type " + strconv.Itoa(int(t)))
+	}
+	w := &BINWriter{
+		s:     s,
+		date:  time.Now(),
+		index: true,
+		cols:  make([][]DBField, c-1),
+		blobs: map[string]uint32{},
+	}
+	for f, m := DBField(1), s.FieldMax(); f <= m; f++ {
+		if fd := s.Field(f); fd.IsValid() {
+			w.cols[fd.Column()-2] = append(w.cols[fd.Column()-2], f)
+		}
+	}
+	for _, fs := range w.cols {
+		sort.Slice(fs, func(i, j int) bool {
+			return s.Field(fs[i]).PtrOffset() < s.Field(fs[j]).PtrOffset()
+		})
+	}
+	w.empty, _ = w.encode(nil)
+	return w, nil
+}
+
+// SetDate sets the database date. Only the year (2000-2255), month, and day
+// are used.
+func (w *BINWriter) SetDate(d time.Time) {
+	w.date = d
+}
+
+// SetIndex sets whether to write index tables, which speed up lookups.
+func (w *BINWriter) SetIndex(index bool) {
+	w.index = index
+}
+
+// Add adds a row for r with the specified field values. Strings must be a
+// string or []byte, and numbers must be a Go integer or float type (or a
+// *big.Int for 128-bit integers) which fits. Missing fields are empty.
+func (w *BINWriter) Add(r Range, values map[DBField]any) error {
+	from, to := r.From.Unmap(), r.To.Unmap()
+	if !from.IsValid() || !to.IsValid() || from.Is4() != to.Is4() {
+		return errors.New("invalid range")
+	}
+	return w.add(!from.Is4(), Range{From: from, To: to}, values)
+}
+
+// add adds a row for r to the IPv4 or IPv6 table. Unlike [BINWriter.Add],
+// IPv4-mapped addresses are kept in the IPv6 table (e.g., when copying rows
+// from a database which has them, even though they can't be looked up).
+func (w *BINWriter) add(v6 bool, r Range, values map[DBField]any) error {
+	row := binRow{
+		from: as_ip6_uint128(r.From),
+		to:   as_ip6_uint128(r.To),
+	}
+	if !v6 {
+		row.from.lo &= 0xffffffff
+		row.to.lo &= 0xffffffff
+	}
+	if !row.from.Less(row.to) {
+		return errors.New("empty range " + r.From.String() + "-" + r.To.String())
+	}
+	cols, err := w.encode(values)
+	if err != nil {
+		return err
+	}
+	row.cols = cols
+	if v6 {
+		w.v6 = append(w.v6, row)
+	} else {
+		w.v4 = append(w.v4, row)
+	}
+	return nil
+}
+
+// AddPrefix is like [BINWriter.Add], but adds a row for the addresses in p.
+func (w *BINWriter) AddPrefix(p netip.Prefix, values map[DBField]any) error {
+	r, ok := prefixRange(p)
+	if !ok {
+		return errors.New("invalid prefix " + p.String())
+	}
+	return w.Add(r, values)
+}
+
+// prefixRange returns the range of addresses in p. If p includes the last
+// address, it is excluded.
+func prefixRange(p netip.Prefix) (Range, bool) {
+	if p = p.Masked(); !p.IsValid() {
+		return Range{}, false
+	}
+	from := p.Addr()
+	b := from.As16()
+	host := 128 - p.Bits()
+	if from.Is4() {
+		host = 32 - p.Bits()
+	}
+	for i := 15; i >= 0 && host > 0; i-- {
+		if host >= 8 {
+			b[i] = 0xff
+		} else {
+			b[i] |= byte(1)<<host - 1
+		}
+		host -= 8
+	}
+	to := netip.AddrFrom16(b)
+	if from.Is4() {
+		to = to.Unmap()
+	}
+	if n := to.Next(); n.IsValid() {
+		to = n
+	}
+	return Range{From: from, To: to}, true
+}
+
+// encode encodes the column values for a row.
+func (w *BINWriter) encode(values map[DBField]any) ([]uint32, error) {
+	for f := range values {
+		if !w.s.Field(f).IsValid() {
+			return nil, errors.New("database does not contain " + f.String())
+		}
+	}
+	cols := make([]uint32, len(w.cols))
+	for i, fs := range w.cols {
+		if len(fs) == 0 {
+			continue
+		}
+		if fd := w.s.Field(fs[0]); ^fd.PtrOffset() == 0 {
+			b, err := binValue(fd.Type(), values[fs[0]])
+			if err != nil {
+				return nil, errors.New(fs[0].String() + ": " + err.Error())
+			}
+			cols[i] = as_le_u32(b)
+			continue
+		}
+		var blob []byte
+		for j, f := range fs {
+			fd := w.s.Field(f)
+			b, err := binValue(fd.Type(), values[f])
+			if err != nil {
+				return nil, errors.New(f.String() + ": " + err.Error())
+			}
+			if len(blob) > int(fd.PtrOffset()) {
+				return nil, errors.New(fs[j-1].String() + ": value is too long (it must fit before " + f.String() + ")")
+			}
+			for len(blob) < int(fd.PtrOffset()) {
+				blob = append(blob, 0)
+			}
+			blob = append(blob, b...)
+		}
+		off, ok := w.blobs[string(blob)]
+		if !ok {
+			off = uint32(len(w.heap))
+			w.heap = append(w.heap, blob...)
+			w.blobs[string(blob)] = off
+		}
+		cols[i] = off
+	}
+	return cols, nil
+}
+
+// binValue encodes v as a value of type t, or an empty value if v is nil.
+func binValue(t uint8, v any) ([]byte, error) {
+	var b [16]byte
+	switch t {
+	case dbtype_str:
+		var s string
+		switch v := v.(type) {
+		case nil:
+		case string:
+			s = v
+		case []byte:
+			s = string(v)
+		default:
+			return nil, errors.New("expected string")
+		}
+		if len(s) > 0xFF {
+			return nil, errors.New("string is longer than 255 bytes")
+		}
+		return append([]byte{byte(len(s))}, s...), nil
+	case dbtype_f32, dbtype_f64:
+		f, ok := binFloat(v)
+		if !ok {
+			return nil, errors.New("expected number")
+		}
+		if t == dbtype_f32 {
+			le_put_u32(b[:], math.Float32bits(float32(f)))
+			return b[:4], nil
+		}
+		le_put_u64(b[:], math.Float64bits(f))
+		return b[:8], nil
+	case dbtype_u32, dbtype_i32, dbtype_u128:
+		n, neg, ok := binInt(v)
+		if !ok {
+			return nil, errors.New("expected integer")
+		}
+		switch t {
+		case dbtype_u32:
+			if neg || n.hi != 0 || n.lo > math.MaxUint32 {
+				return nil, errors.New("value out of range for uint32")
+			}
+			le_put_u32(b[:], uint32(n.lo))
+			return b[:4], nil
+		case dbtype_i32:
+			if n.hi != 0 || (!neg && n.lo > math.MaxInt32) || (neg && n.lo > -math.MinInt32) {
+				return nil, errors.New("value out of range for int32")
+			}
+			v := int32(n.lo)
+			if neg {
+				v = int32(-int64(n.lo))
+			}
+			le_put_u32(b[:], uint32(v))
+			return b[:4], nil
+		default:
+			if neg {
+				return nil, errors.New("value out of range for uint128")
+			}
+			le_put_u64(b[:8], n.lo)
+			le_put_u64(b[8:], n.hi)
+			return b[:16], nil
+		}
+	default:
+		panic("unhandled dbft")
+	}
+}
+
+// binInt returns the magnitude and sign of the integer v.
+func binInt(v any) (n uint128, neg bool, ok bool) {
+	var i int64
+	switch v := v.(type) {
+	case nil:
+		return n, false, true
+	case int:
+		i = int64(v)
+	case int8:
+		i = int64(v)
+	case int16:
+		i = int64(v)
+	case int32:
+		i = int64(v)
+	case int64:
+		i = v
+	case uint:
+		return uint128{lo: uint64(v)}, false, true
+	case uint8:
+		return uint128{lo: uint64(v)}, false, true
+	case uint16:
+		return uint128{lo: uint64(v)}, false, true
+	case uint32:
+		return uint128{lo: uint64(v)}, false, true
+	case uint64:
+		return uint128{lo: v}, false, true
+	case *big.Int:
+		if v.BitLen() > 128 {
+			return n, false, false
+		}
+		x := new(big.Int).Abs(v)
+		n.lo = x.Uint64()
+		n.hi = x.Rsh(x, 64).Uint64()
+		return n, v.Sign() < 0, true
+	default:
+		return n, false, false
+	}
+	if i < 0 {
+		return uint128{lo: uint64(-(i + 1)) + 1}, true, true
+	}
+	return uint128{lo: uint64(i)}, false, true
+}
+
+// binFloat returns the number v as a float64.
+func binFloat(v any) (float64, bool) {
+	switch v := v.(type) {
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	n, neg, ok := binInt(v)
+	if !ok {
+		return 0, false
+	}
+	f := float64(n.hi)*(1<<64) + float64(n.lo)
+	if neg {
+		f = -f
+	}
+	return f, true
+}
+
+// WriteTo writes the database to dst.
+func (w *BINWriter) WriteTo(dst io.Writer) (int64, error) {
+	b, err := w.Bytes()
+	if err != nil {
+		return 0, err
+	}
+	n, err := dst.Write(b)
+	return int64(n), err
+}
+
+// Bytes returns the database.
+func (w *BINWriter) Bytes() ([]byte, error) {
+	c, p, t := w.s.Info()
+	if y := w.date.Year(); y < 2000 || y > 2000+0xFF {
+		return nil, errors.New("date out of range")
+	}
+	v4, err := w.table(w.v4, false)
+	if err != nil {
+		return nil, err
+	}
+	v6, err := w.table(w.v6, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		ip4size = 4 + int(c-1)*4
+		ip6size = 16 + int(c-1)*4
+		size    = 64
+		ip4idx  int
+		ip6idx  int
+	)
+	if w.index && len(v4) != 0 {
+		ip4idx, size = size+1, size+binIndexSize
+	}
+	if w.index && len(v6) != 0 {
+		ip6idx, size = size+1, size+binIndexSize
+	}
+	ip4base, size := size+1, size+len(v4)*ip4size
+	ip6base, size := size+1, size+len(v6)*ip6size
+	heap, size := size, size+len(w.heap)
+	if int64(size) > math.MaxUint32 {
+		return nil, errors.New("database is too large")
+	}
+
+	b := make([]byte, size)
+	b[0] = byte(t)
+	b[1] = c
+	b[2] = byte(w.date.Year() - 2000)
+	b[3] = byte(w.date.Month())
+	b[4] = byte(w.date.Day())
+	le_put_u32(b[5:], uint32(len(v4)))
+	le_put_u32(b[9:], uint32(ip4base))
+	le_put_u32(b[13:], uint32(len(v6)))
+	le_put_u32(b[17:], uint32(ip6base))
+	le_put_u32(b[21:], uint32(ip4idx))
+	le_put_u32(b[25:], uint32(ip6idx))
+	b[29] = byte(p)
+	le_put_u32(b[31:], uint32(size))
+
+	if ip4idx != 0 {
+		writeBINIndex(b[ip4idx-1:], v4, false)
+	}
+	if ip6idx != 0 {
+		writeBINIndex(b[ip6idx-1:], v6, true)
+	}
+	for i, row := range v4 {
+		w.writeRow(b[ip4base-1+i*ip4size:], row, false, uint32(heap))
+	}
+	for i, row := range v6 {
+		w.writeRow(b[ip6base-1+i*ip6size:], row, true, uint32(heap))
+	}
+	copy(b[heap:], w.heap)
+	return b, nil
+}
+
+// table sorts rows, fills the gaps between them with empty rows, and adds the
+// final row. If there are no rows, nil is returned.
+func (w *BINWriter) table(rows []binRow, v6 bool) ([]binRow, error) {
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	max := uint128{lo: math.MaxUint32}
+	if v6 {
+		max = uint128{hi: math.MaxUint64, lo: math.MaxUint64}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].from.Less(rows[j].from)
+	})
+	t := make([]binRow, 0, len(rows)*2+1)
+	var cur uint128
+	for _, row := range rows {
+		if row.from.Less(cur) {
+			return nil, errors.New("overlapping ranges")
+		}
+		if cur.Less(row.from) {
+			t = append(t, binRow{from: cur, to: row.from, cols: w.empty})
+		}
+		t = append(t, row)
+		cur = row.to
+	}
+	if cur.Less(max) {
+		t = append(t, binRow{from: cur, to: max, cols: w.empty})
+	}
+	return append(t, binRow{from: max, to: max}), nil
+}
+
+// writeRow writes row to b.
+func (w *BINWriter) writeRow(b []byte, row binRow, v6 bool, heap uint32) {
+	if v6 {
+		le_put_u64(b, row.from.lo)
+		le_put_u64(b[8:], row.from.hi)
+		b = b[16:]
+	} else {
+		le_put_u32(b, uint32(row.from.lo))
+		b = b[4:]
+	}
+	for i, v := range row.cols {
+		if len(w.cols[i]) != 0 && ^w.s.Field(w.cols[i][0]).PtrOffset() != 0 {
+			v += heap
+		}
+		le_put_u32(b[i*4:], v)
+	}
+}
+
+// binIndexSize is the size of a first-level index table.
+const binIndexSize = 1 << 16 * 8
+
+// writeBINIndex writes the first-level index for a table to b. Each entry
+// contains the first and last row which could contain addresses starting with
+// the entry's upper 16 bits.
+func writeBINIndex(b []byte, rows []binRow, v6 bool) {
+	last := len(rows) - 2 // excluding the final row
+	var lower, upper int
+	for k := 0; k < 1<<16; k++ {
+		var lo, hi uint128
+		if v6 {
+			lo = uint128{hi: uint64(k) << 48}
+			hi = uint128{hi: lo.hi | (1<<48 - 1), lo: math.MaxUint64}
+		} else {
+			lo = uint128{lo: uint64(k) << 16}
+			hi = uint128{lo: lo.lo | (1<<16 - 1)}
+		}
+		for lower < last && !lo.Less(rows[lower+1].from) {
+			lower++
+		}
+		if upper < lower {
+			upper = lower
+		}
+		for upper < last && !hi.Less(rows[upper+1].from) {
+			upper++
+		}
+		le_put_u32(b[k*8:], uint32(lower))
+		le_put_u32(b[k*8+4:], uint32(upper))
+	}
+}
+
+// le_put_u32 puts v into b in little-endian.
+func le_put_u32(b []byte, v uint32) {
+	_ = b[3] // bounds check hint to compiler; see golang.org/issue/14808
+	b[0] = byte(v)
+	b[1] = byte(v >> 8)
+	b[2] = byte(v >> 16)
+	b[3] = byte(v >> 24)
+}
+
+// le_put_u64 puts v into b in little-endian.
+func le_put_u64(b []byte, v uint64) {
+	_ = b[7] // bounds check hint to compiler; see golang.org/issue/14808
+	le_put_u32(b, uint32(v))
+	le_put_u32(b[4:], uint32(v>>32))
+}